@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configCmd groups the commands that inspect .tfskel.yaml
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and check the tfskel configuration",
+	Long: `The config command works with the tfskel configuration file (.tfskel.yaml or --config).
+
+  • validate - Run deep semantic checks and report problems with their line numbers
+  • show     - Print the effective configuration and template data with the source of every value
+  • schema   - Print the JSON Schema of the configuration file for editors
+  • migrate  - Upgrade the configuration file to the current layout version`,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
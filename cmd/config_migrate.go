@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ErrNoConfigFile indicates no config file was found to work on
+var ErrNoConfigFile = errors.New("no config file found, create one with tfskel init or pass --config")
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade .tfskel.yaml to the current layout version",
+	Long: `Rewrite the configuration file to the layout of the current version.
+
+The version key records the layout of the file, files without it have version 1.
+Each migration moves renamed keys to their new place; values, comments and the
+blank lines between top-level sections are kept. Blank lines inside a section are
+removed.
+
+Version 1 is the current layout, there are no migrations yet.
+
+Use --dry-run to print the migrated file instead of writing it.`,
+	Example: `  # Upgrade .tfskel.yaml in place
+  tfskel config migrate
+
+  # Preview the migrated file
+  tfskel config migrate --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+var configMigrateDryRun bool
+
+func init() {
+	configCmd.AddCommand(configMigrateCmd)
+
+	configMigrateCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false, "print the migrated file without writing it")
+}
+
+func runConfigMigrate(cmd *cobra.Command, _ []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting config migrate command")
+
+	configFile := viper.ConfigFileUsed()
+	if configFile == "" {
+		return ErrNoConfigFile
+	}
+	if configMigrateDryRun {
+		log.SetOutput(os.Stderr)
+	}
+
+	cmd.SilenceUsage = true
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", configFile, err)
+	}
+
+	migrated, applied, err := config.Migrate(content)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w", configFile, err)
+	}
+	if len(applied) == 0 {
+		log.Infof("%s is already at version %d", configFile, config.CurrentVersion)
+		return nil
+	}
+	for _, description := range applied {
+		log.Infof("Migration: %s", description)
+	}
+
+	if configMigrateDryRun {
+		_, err := cmd.OutOrStdout().Write(migrated)
+		return err
+	}
+	if err := os.WriteFile(configFile, migrated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configFile, err)
+	}
+	log.Successf("Migrated %s to version %d", configFile, config.CurrentVersion)
+	return nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/drift"
+	"github.com/spf13/cobra"
+)
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of .tfskel.yaml",
+	Long: `Print the JSON Schema of the configuration file for editor validation and completion.
+
+The schema is generated from the configuration tfskel reads, so it always matches
+the installed version. A copy is published in the repository at schema/tfskel.schema.json.
+
+With the YAML language server (VS Code, Neovim, ...) add this line at the top of .tfskel.yaml:
+
+  # yaml-language-server: $schema=./.tfskel.schema.json`,
+	Example: `  # Write the schema next to the config file
+  tfskel config schema > .tfskel.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return writeSchema(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+// writeSchema writes the JSON Schema of the config file, including the drift settings
+func writeSchema(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(config.Schema(drift.DriftConfig{}))
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishedSchemaIsCurrent(t *testing.T) {
+	published, err := os.ReadFile("../schema/tfskel.schema.json")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSchema(&buf))
+	assert.Equal(t, buf.String(), string(published), "run make schema to regenerate schema/tfskel.schema.json")
+}
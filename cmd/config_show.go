@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/drift"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v4"
+)
+
+// formatYAML selects YAML output of config show
+const formatYAML = "yaml"
+
+// ErrRegionWithoutEnv indicates config show got --region without --env
+var ErrRegionWithoutEnv = errors.New("--region requires --env")
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration and where every value comes from",
+	Long: `Print the configuration resolved for an environment and app, and the template data of a
+target, with the source of every value:
+
+  flag --name       a command line flag such as --set
+  <file>:<line>     a key in the config file, including environments.<env> and apps[] overrides
+  env NAME          an environment variable read through viper (only for keys present in the file)
+  default           a built-in default
+
+Template data values read from config carry the source of their config key, values
+computed from other values are marked as derived. The template data is shown when both
+--env and --region are given.`,
+	Example: `  # Show the global configuration
+  tfskel config show
+
+  # Show what generate would use for payments in dev/eu-central-1
+  tfskel config show --env dev --region eu-central-1 --app payments
+
+  # Check where the bucket name comes from
+  tfskel config show -e dev -r eu-central-1 --format json | jq '.data[] | select(.key == "S3BucketName")'`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+var (
+	configShowEnv     string
+	configShowRegion  string
+	configShowApp     string
+	configShowFormat  string
+	configShowSetVars []string
+)
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+
+	configShowCmd.Flags().StringVarP(&configShowEnv, "env", "e", "", "resolve the environments.<env> overrides of this environment")
+	configShowCmd.Flags().StringVarP(&configShowRegion, "region", "r", "", "region to show the template data for (requires --env)")
+	configShowCmd.Flags().StringVarP(&configShowApp, "app", "a", "", "resolve the apps[] overrides of this app")
+	configShowCmd.Flags().StringVarP(&configShowFormat, "format", "f", formatTable, "Output format: table, json, yaml")
+	configShowCmd.Flags().StringArrayVar(&configShowSetVars, "set", nil, "template variable as key=value, like generate --set (repeatable)")
+}
+
+// configShowOutput is the JSON and YAML document printed by config show
+type configShowOutput struct {
+	ConfigFiles []string         `json:"configFiles,omitempty" yaml:"configFiles,omitempty"` // Merged config files, the most general first
+	Config      []config.Setting `json:"config" yaml:"config"`
+	Data        []config.Setting `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+func runConfigShow(cmd *cobra.Command, _ []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting config show command")
+
+	if configShowRegion != "" && configShowEnv == "" {
+		return ErrRegionWithoutEnv
+	}
+	// Keep stdout machine-readable for JSON/YAML
+	if configShowFormat == formatJSON || configShowFormat == formatYAML {
+		log.SetOutput(os.Stderr)
+	}
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Merge the config files of the environment and app directories like generate does
+	dir := "."
+	switch {
+	case configShowRegion != "":
+		dir = cfg.AppPath(configShowEnv, configShowRegion, configShowApp)
+	case configShowEnv != "":
+		dir = cfg.AppPath(configShowEnv, "", "")
+	}
+	if cfg, err = cfg.ForDir(dir); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var output configShowOutput
+	files := slices.Clone(cfg.Files())
+	for i := range files {
+		files[i].Path = displayPath(files[i].Path)
+		output.ConfigFiles = append(output.ConfigFiles, files[i].Path)
+	}
+	sources, err := config.NewSources(cmd, viper.GetViper(), files)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+
+	output.Config = cfg.Settings(configShowEnv, configShowApp, sources)
+	if configShowRegion != "" {
+		generator := app.NewGenerator(cfg, fs.NewOSFileSystem(), log)
+		if output.Data, err = generator.DataSettings(configShowEnv, configShowRegion, configShowApp, sources); err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("failed to resolve template data: %w", err)
+		}
+		for i, setting := range output.Data {
+			switch setting.Key {
+			case "Env":
+				output.Data[i].Source = "flag --env"
+			case "Region":
+				output.Data[i].Source = "flag --region"
+			case "AppDir":
+				output.Data[i].Source = "flag --app"
+			}
+		}
+	}
+
+	if err := printConfigShow(cmd.OutOrStdout(), output, configShowFormat); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	return nil
+}
+
+// displayPath returns path relative to the working directory when it is below it
+func displayPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	return path
+}
+
+// printConfigShow prints the settings in the requested format
+func printConfigShow(w io.Writer, output configShowOutput, format string) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		printSettings(tw, "CONFIG", output.Config)
+		if len(output.Data) > 0 {
+			fmt.Fprintln(tw)
+			printSettings(tw, "DATA", output.Data)
+		}
+		return tw.Flush()
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(output)
+	case formatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(output); err != nil {
+			return fmt.Errorf("failed to write YAML: %w", err)
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("%w: %s", drift.ErrUnsupportedFormat, format)
+	}
+}
+
+// printSettings prints a table section with one row per setting
+func printSettings(w io.Writer, title string, settings []config.Setting) {
+	fmt.Fprintln(w, joinColumns([]string{title, "VALUE", "SOURCE"}))
+	for _, setting := range settings {
+		fmt.Fprintln(w, joinColumns([]string{setting.Key, settingValue(setting.Value), setting.Source}))
+	}
+}
+
+// settingValue formats a value for the table, strings as-is and everything else as JSON
+func settingValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
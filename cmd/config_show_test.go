@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/drift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintConfigShow(t *testing.T) {
+	output := configShowOutput{
+		ConfigFiles: []string{".tfskel.yaml"},
+		Config: []config.Setting{
+			{Key: "terraform_version", Value: "~> 1.13", Source: ".tfskel.yaml:1"},
+			{Key: "provider.aws.regions", Value: []string{"eu-central-1"}, Source: ".tfskel.yaml:4"},
+		},
+		Data: []config.Setting{{Key: "Env", Value: "dev", Source: "flag --env"}},
+	}
+
+	tests := []struct {
+		format   string
+		contains []string
+	}{
+		{formatTable, []string{"CONFIG", `provider.aws.regions  ["eu-central-1"]  .tfskel.yaml:4`, "DATA", "flag --env"}},
+		{formatJSON, []string{`"configFiles": [`, `".tfskel.yaml"`, `"key": "terraform_version"`, `"source": "flag --env"`}},
+		{formatYAML, []string{"configFiles:", "- .tfskel.yaml", "- key: terraform_version", "value: ~> 1.13", "source: flag --env"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, printConfigShow(&buf, output, tt.format))
+			for _, want := range tt.contains {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+
+	assert.ErrorIs(t, printConfigShow(&bytes.Buffer{}, output, "xml"), drift.ErrUnsupportedFormat)
+}
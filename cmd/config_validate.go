@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration for mistakes before generating",
+	Long: `Run the checks of every command and a set of deeper semantic checks on the configuration:
+
+  • account IDs are 12 digits and not placeholders such as REPLACE_WITH_YOUR_DEV_ACCOUNT_ID
+    (subscription IDs must be UUIDs and project IDs valid Google Cloud project IDs)
+  • configured regions and the regions of declared apps exist
+  • terraform_version and provider version constraints parse, including overrides
+  • the S3 bucket name meets the S3 naming rules after rendering for every env/region
+  • generate.github_workflows.name_template renders to a safe file name
+  • templates_dir exists
+
+Every problem is printed with the config file and line of the offending key, the most
+specific file when the key comes from a file listed in extends.
+The command exits with code 1 when a problem was found. Warnings, such as a config
+file with an outdated version:, are printed but do not change the exit code.`,
+	Example: `  # Check .tfskel.yaml of the project root
+  tfskel config validate
+
+  # Check another config file
+  tfskel config validate --config ci/.tfskel.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runConfigValidate,
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+func runConfigValidate(cmd *cobra.Command, _ []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting config validate command")
+
+	// The config file is read leniently on startup, surface syntax errors here
+	configFile := viper.ConfigFileUsed()
+	if configFile != "" {
+		if err := viper.ReadInConfig(); err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("failed to read %s: %w", configFile, err)
+		}
+		if err := config.MergeExtends(viper.GetViper()); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	issues := app.NewGenerator(cfg, fs.NewOSFileSystem(), log).CheckConfig()
+
+	if configFile == "" {
+		log.Warnf("No config file found, checking the default configuration")
+	} else if err := config.SetIssueLines(issues, cfg.Files()); err != nil {
+		log.Warnf("Could not determine line numbers: %v", err)
+	}
+
+	printIssues(cmd.OutOrStdout(), configFile, issues)
+	failed := countFailedIssues(issues)
+	if failed == 0 {
+		log.Successf("Configuration is valid")
+		return nil
+	}
+
+	cmd.SilenceUsage = true
+	return NewExitError(1, fmt.Sprintf("%d configuration issues found", failed))
+}
+
+// countFailedIssues returns the number of issues that are not warnings
+func countFailedIssues(issues []config.Issue) int {
+	failed := 0
+	for _, issue := range issues {
+		if !issue.Warning {
+			failed++
+		}
+	}
+	return failed
+}
+
+// printIssues prints one line per issue, prefixed with the file that defines its key,
+// or the config file for issues that are not tied to a file
+func printIssues(w io.Writer, configFile string, issues []config.Issue) {
+	for _, issue := range issues {
+		if file := cmp.Or(issue.File, configFile); file != "" {
+			_, _ = fmt.Fprintf(w, "%s: %s\n", file, issue) //nolint:errcheck // best-effort terminal output
+			continue
+		}
+		_, _ = fmt.Fprintln(w, issue.String()) //nolint:errcheck // best-effort terminal output
+	}
+}
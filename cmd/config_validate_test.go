@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintIssues(t *testing.T) {
+	issues := []config.Issue{
+		{Path: "version", Message: "config version 1 is outdated, run tfskel config migrate", Warning: true},
+		{Path: "provider.aws.account_mapping.dev", File: "base.yaml", Line: 7, Message: "account ID 'x' must be 12 digits"},
+		{Message: "templates_dir does not exist"},
+	}
+
+	var buf bytes.Buffer
+	printIssues(&buf, ".tfskel.yaml", issues)
+	assert.Equal(t, `.tfskel.yaml: warning: version: config version 1 is outdated, run tfskel config migrate
+base.yaml: line 7: provider.aws.account_mapping.dev: account ID 'x' must be 12 digits
+.tfskel.yaml: templates_dir does not exist
+`, buf.String())
+	assert.Equal(t, 2, countFailedIssues(issues))
+	assert.Zero(t, countFailedIssues(issues[:1]))
+}
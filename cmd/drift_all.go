@@ -121,6 +121,8 @@ func init() {
 		"Skip plan analysis (versions only)")
 	driftAllCmd.Flags().BoolVar(&allSkipVersions, "skip-versions", false,
 		"Skip version analysis (plan only)")
+	_ = driftAllCmd.MarkFlagDirname("path")               //nolint:errcheck // flag is defined above
+	_ = driftAllCmd.MarkFlagFilename("plan-file", "json") //nolint:errcheck // flag is defined above
 }
 
 func runDriftAll(cmd *cobra.Command, _ []string) error {
@@ -67,6 +67,7 @@ func init() {
 
 	driftPlanCmd.Flags().StringVar(&planFile, "plan-file", "",
 		"Path to terraform plan JSON file (required)")
+	_ = driftPlanCmd.MarkFlagFilename("plan-file", "json") //nolint:errcheck // flag is defined above
 	if err := driftPlanCmd.MarkFlagRequired("plan-file"); err != nil {
 		// This should never happen with a valid flag name, but handle it for completeness
 		panic(fmt.Sprintf("failed to mark plan-file as required: %v", err))
@@ -69,6 +69,7 @@ func init() {
 		"Disable colored output")
 	driftVersionCmd.Flags().StringVarP(&versionsPath, "path", "p", ".",
 		"Path to scan for Terraform files (default: current directory)")
+	_ = driftVersionCmd.MarkFlagDirname("path") //nolint:errcheck // flag is defined above
 }
 
 func runDriftVersions(cmd *cobra.Command, _ []string) error {
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/viper"
+)
+
+// planSymbols maps each action to the marker shown in the dry-run plan
+var planSymbols = map[fs.Action]string{
+	fs.ActionCreate: "+",
+	fs.ActionUpdate: "~",
+	fs.ActionSkip:   "=",
+	fs.ActionDelete: "-",
+}
+
+// newRunFileSystem returns the filesystem and logger for a command run
+// In dry-run mode writes are recorded instead of applied and the regular
+// progress output is discarded so that only the plan is printed
+func newRunFileSystem(dryRun bool, log *logger.Logger) (fs.FileSystem, *logger.Logger) {
+	if !dryRun {
+		return fs.NewOSFileSystem(), log
+	}
+	return fs.NewDryRunFileSystem(fs.NewOSFileSystem()), logger.NewWithWriters(viper.GetBool("verbose"), io.Discard, os.Stderr)
+}
+
+// recordSkip adds a skipped path to the plan when running against a recording filesystem
+func recordSkip(filesystem fs.FileSystem, change fs.Change) {
+	if recorder, ok := filesystem.(fs.Recorder); ok {
+		change.Action = fs.ActionSkip
+		recorder.Record(change)
+	}
+}
+
+// printDryRunPlan prints the changes recorded during a dry run with paths relative to baseDir
+func printDryRunPlan(w io.Writer, changes []fs.Change, baseDir string) {
+	counts := make(map[fs.Action]int)
+
+	_, _ = fmt.Fprintln(w, "Dry run - no files were written. Planned changes:") //nolint:errcheck // best-effort terminal output
+	for _, change := range changes {
+		counts[change.Action]++
+
+		path := change.Path
+		if rel, err := filepath.Rel(baseDir, path); err == nil && filepath.IsAbs(path) {
+			path = rel
+		}
+		kind := "file"
+		if change.IsDir {
+			kind = "dir"
+			path += string(filepath.Separator)
+		}
+
+		line := fmt.Sprintf("  %s %-6s %-4s %s", planSymbols[change.Action], change.Action, kind, path)
+		if change.Reason != "" {
+			line += fmt.Sprintf(" (%s)", change.Reason)
+		}
+		_, _ = fmt.Fprintln(w, line) //nolint:errcheck // best-effort terminal output
+	}
+
+	summary := fmt.Sprintf("Plan: %d to create, %d to update, %d to skip", counts[fs.ActionCreate], counts[fs.ActionUpdate], counts[fs.ActionSkip])
+	if counts[fs.ActionDelete] > 0 {
+		summary += fmt.Sprintf(", %d to delete", counts[fs.ActionDelete])
+	}
+	_, _ = fmt.Fprintln(w, summary) //nolint:errcheck // best-effort terminal output
+}
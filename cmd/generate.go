@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/ishuar/tfskel/internal/app"
 	"github.com/ishuar/tfskel/internal/config"
@@ -22,6 +25,18 @@ var (
 	ErrAppDirRequired = errors.New("app directory name is required (provide as argument)")
 	// ErrAccountMapping indicates account mapping is missing for the environment
 	ErrAccountMapping = errors.New("account mapping is required for environment in your configuration")
+	// ErrSubscriptionMapping indicates subscription mapping is missing for the environment
+	ErrSubscriptionMapping = errors.New("subscription mapping is required for environment in your configuration")
+	// ErrProjectMapping indicates project mapping is missing for the environment
+	ErrProjectMapping = errors.New("project mapping is required for environment in your configuration")
+	// ErrNoEnvironmentsConfigured indicates --all-envs was used without any environment mapping in config
+	ErrNoEnvironmentsConfigured = errors.New("no environments configured for --all-envs")
+	// ErrNoRegionsConfigured indicates --all-regions was used without any regions in config
+	ErrNoRegionsConfigured = errors.New("no regions configured for --all-regions")
+	// ErrBatchGenerationFailed indicates one or more targets of a batch generation failed
+	ErrBatchGenerationFailed = errors.New("generation failed for one or more targets")
+	// ErrUnknownRegion indicates a region is not in the region catalog
+	ErrUnknownRegion = errors.New("unknown region (use --allow-unknown-region or add it to region_aliases)")
 )
 
 var generateCmd = &cobra.Command{
@@ -31,12 +46,28 @@ var generateCmd = &cobra.Command{
 
 This command creates:
   - Environment directories (dev, stg, prd)
-  - Region-specific subdirectories (only for the specified region)
+  - Region-specific subdirectories (only for the specified regions)
   - Application directories
   - Terraform configuration files from templates
 
+Batch generation:
+  --env and --region may be repeated (or comma separated) to scaffold the app for
+  every environment x region combination in one run. --all-envs uses every environment
+  from the account mapping and --all-regions every region from the provider config.
+  A summary per target is printed at the end; use --continue-on-error to keep going
+  after a target fails.
+
+Template variables:
+  Templates can read user-defined values through .Vars. They come from vars in the
+  config file, overridden by environments.<env>.vars, apps[].vars and finally --set.
+
 Configuration:
-  The generate command reads .tfskel.yaml from the current directory by default.
+  The generate command finds the project root by walking up from the current
+  directory to the nearest directory with .tfskel.yaml or an envs/ directory; config
+  files in the environment, region and app directories of layout.app_path are
+  directory overrides, not roots.
+  It changes into the root, reads .tfskel.yaml from there and creates the app
+  directory relative to it, so it can be run from anywhere inside the project.
   Use --config flag to specify a different configuration file location.
   The --config flag takes precedence over the default location.
 
@@ -49,39 +80,72 @@ Arguments:
   tfskel generate myapp --config ./my-config.yaml --env dev --region us-east-1
 
   # Generate with custom templates
-  tfskel generate myapp --env stg --region eu-central-1 --templates-dir ./templates`,
+  tfskel generate myapp --env stg --region eu-central-1 --templates-dir ./templates
+
+  # Generate for every configured environment in two regions
+  tfskel generate myapp --all-envs --region eu-central-1 --region us-east-1
+
+  # Generate for the full environment x region matrix, reporting failures at the end
+  tfskel generate myapp --all-envs --all-regions --continue-on-error
+
+  # Preview which files would be created, updated or skipped
+  tfskel generate myapp --env dev --region us-east-1 --dry-run
+
+  # Pass template variables, available as {{.Vars.owner}} in every template
+  tfskel generate myapp --env dev --region us-east-1 --set owner=platform --set cost_center=1234
+
+  # Show a diff of backend.tf/versions.tf updates and confirm each one
+  tfskel generate myapp --env dev --region us-east-1 --diff --interactive`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGenerate,
 }
 
 var (
-	env                     string
-	region                  string
+	envs                    []string
+	regions                 []string
+	allEnvs                 bool
+	allRegions              bool
+	continueOnError         bool
 	templatesDir            string
 	s3BucketName            string
 	extraTemplateExtensions []string
 	createGithubWorkflows   bool
+	generateDryRun          bool
+	generateDiff            bool
+	generateInteractive     bool
+	generateNoColor         bool
+	setVars                 []string
+	generateNoHooks         bool
+	allowUnknownRegion      bool
 )
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
 
-	// Required flags for generation
-	generateCmd.Flags().StringVarP(&env, "env", "e", "", "target environment (e.g., dev, stg, prd) - required")
-	generateCmd.Flags().StringVarP(&region, "region", "r", "", "AWS region (e.g., us-east-1, eu-central-1) - required")
-	// These are critical flags - errors should be handled during command setup, but cobra handles this internally
-	if err := generateCmd.MarkFlagRequired("env"); err != nil {
-		panic(fmt.Sprintf("failed to mark env flag as required: %v", err))
-	}
-	if err := generateCmd.MarkFlagRequired("region"); err != nil {
-		panic(fmt.Sprintf("failed to mark region flag as required: %v", err))
-	}
+	// Target flags for generation, one of --env/--all-envs and one of --region/--all-regions is required
+	generateCmd.Flags().StringSliceVarP(&envs, "env", "e", nil, "target environment (e.g., dev, stg, prd), repeatable")
+	generateCmd.Flags().StringSliceVarP(&regions, "region", "r", nil, "cloud region (e.g., us-east-1, eu-central-1, westeurope), repeatable")
+	generateCmd.Flags().BoolVar(&allEnvs, "all-envs", false, "generate for every environment in the account mapping")
+	generateCmd.Flags().BoolVar(&allRegions, "all-regions", false, "generate for every region in the provider config")
+	generateCmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep generating the remaining targets when one fails")
+	generateCmd.Flags().BoolVar(&allowUnknownRegion, "allow-unknown-region", false, "accept AWS region names that are not in the region catalog")
+	generateCmd.MarkFlagsOneRequired("env", "all-envs")
+	generateCmd.MarkFlagsOneRequired("region", "all-regions")
+	generateCmd.MarkFlagsMutuallyExclusive("env", "all-envs")
+	generateCmd.MarkFlagsMutuallyExclusive("region", "all-regions")
 
 	// Optional flags
 	generateCmd.Flags().StringVar(&templatesDir, "templates-dir", "", "directory containing custom template files (overrides defaults)")
+	_ = generateCmd.MarkFlagDirname("templates-dir") //nolint:errcheck // flag is defined above
 	generateCmd.Flags().StringVar(&s3BucketName, "s3-bucket-name", "", "S3 bucket name for Terraform state")
 	generateCmd.Flags().StringSliceVar(&extraTemplateExtensions, "extra-template-extensions", []string{"tf.tmpl"}, "template file extensions to process from templates-dir (tf.tmpl always included)")
 	generateCmd.Flags().BoolVar(&createGithubWorkflows, "create-github-workflows", false, "create GitHub workflow files from default templates (disabled by default)")
+	generateCmd.Flags().BoolVar(&generateDryRun, "dry-run", false, "print the files that would be created, updated or skipped without writing anything")
+	generateCmd.Flags().BoolVar(&generateDiff, "diff", false, "show a unified diff for every managed file before it is updated")
+	generateCmd.Flags().BoolVar(&generateInteractive, "interactive", false, "ask for confirmation before updating each managed file (implies --diff)")
+	generateCmd.Flags().BoolVar(&generateNoColor, "no-color", false, "disable colored diff output")
+	generateCmd.Flags().BoolVar(&generateNoHooks, "no-hooks", false, "skip the hooks.post_generate commands from the config")
+	generateCmd.Flags().StringArrayVar(&setVars, "set", nil, "template variable as key=value, exposed as .Vars.key (repeatable, overrides vars from config)")
 
 	// Bind flags to viper for config file support (only for optional flags that can come from config)
 	// These bindings are non-critical, errors are logged but not fatal
@@ -109,12 +173,6 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	// Get app directory from positional argument
 	appDir := args[0]
 
-	// Validate generation parameters
-	if err := validateGenerateParams(env, region, appDir); err != nil {
-		cmd.SilenceUsage = true
-		return fmt.Errorf("invalid parameters: %w", err)
-	}
-
 	// Load configuration
 	cfg, err := config.Load(cmd, viper.GetViper())
 	if err != nil {
@@ -128,20 +186,46 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	// Validate that account mapping exists for the environment
-	if err := validateAccountMapping(cfg, env); err != nil {
+	// Resolve the environment x region matrix and validate generation parameters
+	targets, err := resolveGenerateTargets(cfg, appDir)
+	if err != nil {
 		cmd.SilenceUsage = true
-		return err
+		return fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	// Create filesystem abstraction
-	filesystem := fs.NewOSFileSystem()
+	// Create filesystem abstraction, recording writes instead of applying them in dry-run mode
+	filesystem, runLog := newRunFileSystem(generateDryRun, log)
 
 	// Create and run the generator with generation parameters
-	generator := app.NewGenerator(cfg, filesystem, log)
-	if err := generator.Run(env, region, appDir); err != nil {
-		cmd.SilenceUsage = true
-		return fmt.Errorf("generation failed: %w", err)
+	generator := app.NewGenerator(cfg, filesystem, runLog)
+	if generateNoHooks {
+		generator.SetHookRunner(nil)
+	}
+	if generateDiff || generateInteractive {
+		opts := &app.DiffOptions{Out: cmd.OutOrStdout(), UseColor: !generateNoColor}
+		if generateInteractive {
+			opts.Confirm = newConfirmFunc(cmd.InOrStdin(), cmd.OutOrStdout())
+		}
+		generator.SetDiffOptions(opts)
+	}
+	// A single target keeps the plain output, batches get a summary per target
+	if len(targets) == 1 {
+		if err := runTarget(cfg, generator, targets[0]); err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("generation failed: %w", err)
+		}
+	} else {
+		results := runTargets(cfg, generator, targets, continueOnError, runLog)
+		printGenerateSummary(cfg, results, len(targets), runLog)
+		if failed := countFailedTargets(results); failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%w: %d of %d failed", ErrBatchGenerationFailed, failed, len(targets))
+		}
+	}
+
+	if recorder, ok := filesystem.(fs.Recorder); ok {
+		printDryRunPlan(cmd.OutOrStdout(), recorder.Changes(), ".")
+		return nil
 	}
 
 	log.Success("Terraform directory scaffolding completed!")
@@ -162,11 +246,96 @@ func validateGenerateParams(env, region, appDir string) error {
 	return nil
 }
 
-// validateAccountMapping checks if the account mapping exists for the environment
+// resolveGenerateTargets builds the environment x region matrix from the flags and config
+// Every combination is validated with validateGenerateParams and AWS regions must be in the region catalog
+func resolveGenerateTargets(cfg *config.Config, appDir string) ([]target, error) {
+	targetEnvs := uniqueValues(envs)
+	if allEnvs {
+		targetEnvs = cfg.Environments()
+		if len(targetEnvs) == 0 {
+			return nil, fmt.Errorf("%w: add environments to %s", ErrNoEnvironmentsConfigured, cfg.EnvironmentMappingKey())
+		}
+	}
+
+	targetRegions := uniqueValues(regions)
+	if allRegions {
+		targetRegions = uniqueValues(cfg.GetRegions())
+		if len(targetRegions) == 0 {
+			return nil, fmt.Errorf("%w: add regions to provider.%s.regions", ErrNoRegionsConfigured, cfg.Cloud())
+		}
+	}
+
+	if len(targetEnvs) == 0 {
+		return nil, ErrEnvironmentRequired
+	}
+	if len(targetRegions) == 0 {
+		return nil, ErrRegionRequired
+	}
+
+	targets := make([]target, 0, len(targetEnvs)*len(targetRegions))
+	for _, targetEnv := range targetEnvs {
+		for _, targetRegion := range targetRegions {
+			if err := validateGenerateParams(targetEnv, targetRegion, appDir); err != nil {
+				return nil, err
+			}
+			if !allowUnknownRegion && !cfg.KnownRegion(targetRegion) {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownRegion, targetRegion)
+			}
+			targets = append(targets, target{app: appDir, env: targetEnv, region: targetRegion})
+		}
+	}
+	return targets, nil
+}
+
+// uniqueValues returns the non-empty values in their original order without duplicates
+func uniqueValues(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
+
+// validateAccountMapping checks if the account, subscription or project mapping exists for the environment
 func validateAccountMapping(cfg *config.Config, env string) error {
-	accountID := cfg.GetAccountID(env)
-	if accountID == "000000000000" {
-		return fmt.Errorf("%w '%s'", ErrAccountMapping, env)
+	switch cfg.Cloud() {
+	case config.CloudAzureRM:
+		if cfg.GetSubscriptionID(env) == config.DefaultSubscriptionID {
+			return fmt.Errorf("%w '%s'", ErrSubscriptionMapping, env)
+		}
+	case config.CloudGoogle:
+		if cfg.GetProjectID(env) == config.DefaultProjectID {
+			return fmt.Errorf("%w '%s'", ErrProjectMapping, env)
+		}
+	default:
+		if cfg.GetAccountID(env) == config.DefaultAccountID {
+			return fmt.Errorf("%w '%s'", ErrAccountMapping, env)
+		}
 	}
 	return nil
 }
+
+// newConfirmFunc returns a ConfirmFunc that prompts on out and reads yes/no answers from in
+// Anything other than "y" or "yes" keeps the existing file
+func newConfirmFunc(in io.Reader, out io.Writer) app.ConfirmFunc {
+	reader := bufio.NewReader(in)
+	return func(path string) (bool, error) {
+		_, _ = fmt.Fprintf(out, "Apply changes to %s? [y/N]: ", path) //nolint:errcheck // best-effort terminal output
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+}
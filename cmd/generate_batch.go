@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+// target is a single app in one environment and region
+type target struct {
+	app    string
+	env    string
+	region string
+}
+
+// path returns the directory of the target in the layout of cfg
+func (t target) path(cfg *config.Config) string {
+	return cfg.AppPath(t.env, t.region, t.app)
+}
+
+// targetResult is the outcome of generating one target
+type targetResult struct {
+	target
+	err error
+}
+
+// runTarget validates the environment mapping of a target and generates it
+func runTarget(cfg *config.Config, generator *app.Generator, t target) error {
+	if err := validateAccountMapping(cfg, t.env); err != nil {
+		return err
+	}
+	return generator.Run(t.env, t.region, t.app)
+}
+
+// runTargets generates every target with the same generator so templates are loaded once
+// It stops at the first failure unless continueOnError is set; targets that were not
+// attempted are left out of the results
+func runTargets(cfg *config.Config, generator *app.Generator, targets []target, continueOnError bool, log *logger.Logger) []targetResult {
+	results := make([]targetResult, 0, len(targets))
+	for i, t := range targets {
+		log.Infof("[%d/%d] Generating %s for %s/%s", i+1, len(targets), t.app, t.env, t.region)
+
+		err := runTarget(cfg, generator, t)
+		results = append(results, targetResult{target: t, err: err})
+		if err != nil {
+			log.Errorf("Generation failed for %s/%s: %v", t.env, t.region, err)
+			if !continueOnError {
+				break
+			}
+		}
+	}
+	return results
+}
+
+// printGenerateSummary logs one line per attempted target followed by the totals
+// total is the number of requested targets, those not attempted are reported as skipped
+func printGenerateSummary(cfg *config.Config, results []targetResult, total int, log *logger.Logger) {
+	log.Info("Generation summary:")
+	for _, result := range results {
+		appPath := result.path(cfg)
+		if result.err != nil {
+			log.Errorf("  %-30s failed: %v", appPath, result.err)
+			continue
+		}
+		log.Successf("  %-30s ok", appPath)
+	}
+
+	failed := countFailedTargets(results)
+	log.Infof("%d succeeded, %d failed, %d skipped", len(results)-failed, failed, total-len(results))
+}
+
+// countFailedTargets returns the number of results with an error
+func countFailedTargets(results []targetResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveGenerateTargets(t *testing.T) {
+	cfg := &config.Config{
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{
+				AccountMapping: map[string]string{"prd": "222222222222", "dev": "111111111111"},
+				Regions:        []string{"eu-central-1", "us-east-1"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		envs         []string
+		regions      []string
+		allEnvs      bool
+		allRegions   bool
+		allowUnknown bool
+		cfg          *config.Config
+		expected     []target
+		wantErr      error
+	}{
+		{
+			name:     "single target",
+			envs:     []string{"dev"},
+			regions:  []string{"us-east-1"},
+			expected: []target{{"myapp", "dev", "us-east-1"}},
+		},
+		{
+			name:     "repeated flags are deduplicated and keep their order",
+			envs:     []string{"stg", "dev", "stg"},
+			regions:  []string{"us-east-1", "eu-central-1"},
+			expected: []target{{"myapp", "stg", "us-east-1"}, {"myapp", "stg", "eu-central-1"}, {"myapp", "dev", "us-east-1"}, {"myapp", "dev", "eu-central-1"}},
+		},
+		{
+			name:       "all envs and all regions from config",
+			allEnvs:    true,
+			allRegions: true,
+			expected:   []target{{"myapp", "dev", "eu-central-1"}, {"myapp", "dev", "us-east-1"}, {"myapp", "prd", "eu-central-1"}, {"myapp", "prd", "us-east-1"}},
+		},
+		{
+			name:       "all regions without configured regions",
+			envs:       []string{"dev"},
+			allRegions: true,
+			cfg:        &config.Config{Provider: &config.Provider{AWS: &config.AWSProvider{}}},
+			wantErr:    ErrNoRegionsConfigured,
+		},
+		{
+			name:    "all envs without account mapping",
+			allEnvs: true,
+			regions: []string{"us-east-1"},
+			cfg:     &config.Config{Provider: &config.Provider{AWS: &config.AWSProvider{}}},
+			wantErr: ErrNoEnvironmentsConfigured,
+		},
+		{
+			name:    "empty env",
+			envs:    []string{""},
+			regions: []string{"us-east-1"},
+			wantErr: ErrEnvironmentRequired,
+		},
+		{
+			name:    "unknown region",
+			envs:    []string{"dev"},
+			regions: []string{"us-east-9"},
+			wantErr: ErrUnknownRegion,
+		},
+		{
+			name:         "unknown region allowed",
+			envs:         []string{"dev"},
+			regions:      []string{"us-east-9"},
+			allowUnknown: true,
+			expected:     []target{{"myapp", "dev", "us-east-9"}},
+		},
+		{
+			name:     "unknown region with an alias",
+			envs:     []string{"dev"},
+			regions:  []string{"us-east-9"},
+			cfg:      &config.Config{Provider: cfg.Provider, RegionAliases: map[string]string{"us-east-9": "use9"}},
+			expected: []target{{"myapp", "dev", "us-east-9"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envs, regions, allEnvs, allRegions, allowUnknownRegion = tt.envs, tt.regions, tt.allEnvs, tt.allRegions, tt.allowUnknown
+			t.Cleanup(func() {
+				envs, regions, allEnvs, allRegions, allowUnknownRegion = nil, nil, false, false, false
+			})
+
+			testCfg := cfg
+			if tt.cfg != nil {
+				testCfg = tt.cfg
+			}
+
+			targets, err := resolveGenerateTargets(testCfg, "myapp")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, targets)
+		})
+	}
+}
+
+func TestRunTargets(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{
+				Version:        "~> 6.0",
+				AccountMapping: map[string]string{"dev": "111111111111", "prd": "222222222222"},
+			},
+		},
+		Backend: &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+	}
+	targets := []target{{"myapp", "dev", "us-east-1"}, {"myapp", "stg", "us-east-1"}, {"myapp", "prd", "us-east-1"}}
+
+	tests := []struct {
+		name            string
+		continueOnError bool
+		expectedResults int
+		expectedSummary string
+	}{
+		{
+			name:            "stops at the first failure",
+			expectedResults: 2,
+			expectedSummary: "1 succeeded, 1 failed, 1 skipped",
+		},
+		{
+			name:            "continues after a failure",
+			continueOnError: true,
+			expectedResults: 3,
+			expectedSummary: "2 succeeded, 1 failed, 0 skipped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filesystem := fs.NewMemoryFileSystem()
+			var out bytes.Buffer
+			log := logger.NewWithWriters(false, &out, &out)
+			generator := app.NewGenerator(cfg, filesystem, log)
+
+			results := runTargets(cfg, generator, targets, tt.continueOnError, log)
+			require.Len(t, results, tt.expectedResults)
+			assert.NoError(t, results[0].err)
+			assert.ErrorIs(t, results[1].err, ErrAccountMapping)
+			assert.Equal(t, 1, countFailedTargets(results))
+
+			printGenerateSummary(cfg, results, len(targets), log)
+			assert.Contains(t, out.String(), tt.expectedSummary)
+			assert.True(t, filesystem.FileExists(filepath.Join("envs", "dev", "us-east-1", "myapp", "backend.tf")))
+			assert.Equal(t, tt.continueOnError, filesystem.FileExists(filepath.Join("envs", "prd", "us-east-1", "myapp", "backend.tf")))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAccountMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *config.Config
+		env     string
+		wantErr error
+	}{
+		{
+			name: "aws account mapped",
+			config: &config.Config{
+				Provider: &config.Provider{AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}}},
+			},
+			env: "dev",
+		},
+		{
+			name: "aws account missing",
+			config: &config.Config{
+				Provider: &config.Provider{AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}}},
+			},
+			env:     "prd",
+			wantErr: ErrAccountMapping,
+		},
+		{
+			name: "azurerm subscription missing",
+			config: &config.Config{
+				Provider: &config.Provider{AzureRM: &config.AzureRMProvider{SubscriptionMapping: map[string]string{"dev": "11111111-2222-3333-4444-555555555555"}}},
+			},
+			env:     "prd",
+			wantErr: ErrSubscriptionMapping,
+		},
+		{
+			name: "google project mapped",
+			config: &config.Config{
+				Provider: &config.Provider{Google: &config.GoogleProvider{ProjectMapping: map[string]string{"dev": "my-dev-project"}}},
+			},
+			env: "dev",
+		},
+		{
+			name: "google project missing",
+			config: &config.Config{
+				Provider: &config.Provider{Google: &config.GoogleProvider{ProjectMapping: map[string]string{"dev": "my-dev-project"}}},
+			},
+			env:     "prd",
+			wantErr: ErrProjectMapping,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAccountMapping(tt.config, tt.env)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), tt.env)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewConfirmFunc(t *testing.T) {
+	var out bytes.Buffer
+	confirm := newConfirmFunc(strings.NewReader("y\nno\nYES\n"), &out)
+
+	for _, expected := range []bool{true, false, true, false} {
+		apply, err := confirm("backend.tf")
+		require.NoError(t, err)
+		assert.Equal(t, expected, apply)
+	}
+	assert.Contains(t, out.String(), "Apply changes to backend.tf? [y/N]: ")
+}
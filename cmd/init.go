@@ -1,14 +1,16 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
+	"github.com/ishuar/tfskel/internal/app"
 	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
 	"github.com/ishuar/tfskel/internal/logger"
 	"github.com/ishuar/tfskel/internal/templates"
 	"github.com/spf13/cobra"
@@ -21,6 +23,10 @@ var (
 	ErrUnsupportedDataType = errors.New("unsupported data type for template rendering")
 	// ErrMissingAccountMapping indicates AWS account mapping configuration is missing
 	ErrMissingAccountMapping = errors.New("provider.aws.account_mapping is missing or empty")
+	// ErrMissingSubscriptionMapping indicates Azure subscription mapping configuration is missing
+	ErrMissingSubscriptionMapping = errors.New("provider.azurerm.subscription_mapping is missing or empty")
+	// ErrMissingProjectMapping indicates Google project mapping configuration is missing
+	ErrMissingProjectMapping = errors.New("provider.google.project_mapping is missing or empty")
 )
 
 var initCmd = &cobra.Command{
@@ -48,17 +54,24 @@ Recommendations:
   tfskel init --dir /path/to/project
 
   # Initialize with explicit config file
-  tfskel init --config /path/to/config.yaml`,
+  tfskel init --config /path/to/config.yaml
+
+  # Preview the files and directories init would create
+  tfskel init --dry-run`,
 	RunE: runInit,
 }
 
 var (
-	initDir string
+	initDir     string
+	initDryRun  bool
+	initNoHooks bool
 )
 
 func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().StringVarP(&initDir, "dir", "d", "", "directory to initialize (default: current directory)")
+	initCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "print the files and directories that would be created or skipped without writing anything")
+	initCmd.Flags().BoolVar(&initNoHooks, "no-hooks", false, "skip the hooks.post_init commands from .tfskel.yaml")
 }
 
 func runInit(cmd *cobra.Command, _ []string) error {
@@ -88,20 +101,35 @@ func runInit(cmd *cobra.Command, _ []string) error {
 
 	log.Infof("Initializing tfskel project structure in: %s", targetDir)
 
+	// Create filesystem abstraction, recording writes instead of applying them in dry-run mode
+	filesystem, runLog := newRunFileSystem(initDryRun, log)
+
 	// Determine environments, regions, and terraform version
 	// Priority: existing .tfskel.yaml in target dir > defaults
-	environments, terraformVersion, regions, err := determineInitParameters(targetDir, log)
+	environments, terraformVersion, regions, err := determineInitParameters(filesystem, targetDir, log)
 	if err != nil {
 		cmd.SilenceUsage = true
 		return err
 	}
 
 	// Create the project structure
-	if err := createProjectStructure(targetDir, terraformVersion, regions, environments, log); err != nil {
+	if err := createProjectStructure(filesystem, targetDir, terraformVersion, regions, environments, runLog); err != nil {
 		cmd.SilenceUsage = true
 		return err
 	}
 
+	if recorder, ok := filesystem.(fs.Recorder); ok {
+		printDryRunPlan(cmd.OutOrStdout(), recorder.Changes(), targetDir)
+		return nil
+	}
+
+	if !initNoHooks {
+		if err := runPostInitHooks(app.NewHookRunner(app.ExecCommand, log), filesystem, targetDir, terraformVersion, log); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
+
 	log.Successf("Successfully initialized tfskel project structure in: %s", targetDir)
 
 	return nil
@@ -130,7 +158,7 @@ func extractVersionFromConstraint(constraint string) string {
 
 // determineInitParameters determines environments, terraform version, and regions
 // Priority: existing .tfskel.yaml in target dir > defaults
-func determineInitParameters(targetDir string, log *logger.Logger) ([]string, string, []string, error) {
+func determineInitParameters(filesystem fs.FileSystem, targetDir string, log *logger.Logger) ([]string, string, []string, error) {
 	// Default values
 	defaultEnvironments := []string{"dev", "stg", "prd"}
 	defaultTerraformVersion := "1.13.1"
@@ -138,7 +166,7 @@ func determineInitParameters(targetDir string, log *logger.Logger) ([]string, st
 
 	// Check if .tfskel.yaml exists in target directory
 	configPath := filepath.Join(targetDir, ".tfskel.yaml")
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	if !filesystem.FileExists(configPath) {
 		// No config file exists, use defaults
 		log.Debugf("No .tfskel.yaml found in target directory, using default environments: %v", defaultEnvironments)
 		return defaultEnvironments, defaultTerraformVersion, defaultRegions, nil
@@ -147,45 +175,20 @@ func determineInitParameters(targetDir string, log *logger.Logger) ([]string, st
 	// Config file exists, read it
 	log.Debugf("Found existing .tfskel.yaml, reading configuration...")
 
-	// Create a new viper instance for reading the target directory's config
-	v := viper.New()
-	v.SetConfigFile(configPath)
-	v.SetConfigType("yaml")
-
-	if err := v.ReadInConfig(); err != nil {
+	cfg, err := readTargetConfig(filesystem, configPath)
+	if err != nil {
 		// If we can't read the config, warn and use defaults
-		log.Warnf("Failed to read existing .tfskel.yaml: %v, using defaults", err)
+		log.Warnf("%v, using defaults", err)
 		return defaultEnvironments, defaultTerraformVersion, defaultRegions, nil
 	}
 
-	// Unmarshal into config struct
-	cfg := &config.Config{}
-	if err := v.Unmarshal(cfg); err != nil {
-		log.Warnf("Failed to parse .tfskel.yaml: %v, using defaults", err)
-		return defaultEnvironments, defaultTerraformVersion, defaultRegions, nil
-	}
-
-	// Ensure nested structures exist
-	if cfg.Provider == nil {
-		cfg.Provider = &config.Provider{}
-	}
-	if cfg.Provider.AWS == nil {
-		cfg.Provider.AWS = &config.AWSProvider{}
-	}
-
-	// Extract environments from account_mapping
-	var environments []string
-	if len(cfg.Provider.AWS.AccountMapping) > 0 {
-		// Use keys from account_mapping as environments
-		for env := range cfg.Provider.AWS.AccountMapping {
-			environments = append(environments, env)
-		}
-		// Sort for consistent output
-		sort.Strings(environments)
-		log.Infof("Using %d environment(s) from config account_mapping: %v", len(environments), environments)
+	// Extract environments from account_mapping (or subscription_mapping for azurerm)
+	environments := cfg.Environments()
+	if len(environments) > 0 {
+		log.Infof("Using %d environment(s) from config %s: %v", len(environments), cfg.EnvironmentMappingKey(), environments)
 	} else {
 		// Config exists but no account_mapping - this is an error
-		return nil, "", nil, fmt.Errorf("existing .tfskel.yaml found but %w; account mappings are required. Please add environment mappings to .tfskel.yaml", ErrMissingAccountMapping)
+		return nil, "", nil, fmt.Errorf("existing .tfskel.yaml found but %w; account mappings are required. Please add environment mappings to .tfskel.yaml", missingMappingError(cfg))
 	}
 
 	// Extract terraform version
@@ -207,12 +210,119 @@ func determineInitParameters(targetDir string, log *logger.Logger) ([]string, st
 	return environments, terraformVersion, regions, nil
 }
 
-func createProjectStructure(baseDir string, terraformVersion string, regions []string, environments []string, log *logger.Logger) error {
+// readTargetConfig reads the .tfskel.yaml of the directory being initialized
+func readTargetConfig(filesystem fs.FileSystem, configPath string) (*config.Config, error) {
+	// Create a new viper instance for reading the target directory's config
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	content, err := filesystem.ReadFile(configPath)
+	if err == nil {
+		err = v.ReadConfig(bytes.NewReader(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing .tfskel.yaml: %w", err)
+	}
+
+	// Unmarshal into config struct
+	cfg := &config.Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse .tfskel.yaml: %w", err)
+	}
+	return cfg, nil
+}
+
+// targetLayout returns the config of the directory being initialized to place its directories with
+// It falls back to the default layout when the directory has no readable .tfskel.yaml yet
+func targetLayout(filesystem fs.FileSystem, configPath string) *config.Config {
+	if filesystem.FileExists(configPath) {
+		if cfg, err := readTargetConfig(filesystem, configPath); err == nil {
+			return cfg
+		}
+	}
+	return &config.Config{}
+}
+
+// runPostInitHooks runs hooks.post_init from the target directory's .tfskel.yaml
+func runPostInitHooks(runner *app.HookRunner, filesystem fs.FileSystem, targetDir, terraformVersion string, log *logger.Logger) error {
+	configPath := filepath.Join(targetDir, ".tfskel.yaml")
+	if !filesystem.FileExists(configPath) {
+		return nil
+	}
+	cfg, err := readTargetConfig(filesystem, configPath)
+	if err != nil {
+		log.Warnf("%v, skipping post_init hooks", err)
+		return nil
+	}
+	hooks := cfg.PostInitHooks()
+	if len(hooks) == 0 {
+		return nil
+	}
+	return runner.Run("post_init", hooks, targetDir, &templates.Data{TerraformVersion: terraformVersion})
+}
+
+// missingMappingError returns the missing environment mapping error for the configured cloud
+func missingMappingError(cfg *config.Config) error {
+	switch cfg.Cloud() {
+	case config.CloudAzureRM:
+		return ErrMissingSubscriptionMapping
+	case config.CloudGoogle:
+		return ErrMissingProjectMapping
+	default:
+		return ErrMissingAccountMapping
+	}
+}
+
+// initManifest records the files init renders from templates in the manifest of the project, like generate does
+// .tfskel.yaml is not recorded, it is owned by the user rather than by a template
+type initManifest struct {
+	filesystem fs.FileSystem
+	baseDir    string
+	manifest   *app.Manifest
+	tracked    int
+}
+
+// create runs create for the file at path and records the file when it did not exist before
+// tmplPath is the embedded template the file is rendered from
+func (m *initManifest) create(path, tmplPath string, create func() error) error {
+	existed := m.filesystem.FileExists(path)
+	if err := create(); err != nil || existed {
+		return err
+	}
+
+	content, err := m.filesystem.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	rel, err := filepath.Rel(m.baseDir, path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	m.manifest.TrackFile(rel, tmplPath, "embedded:"+tmplPath, string(content))
+	m.tracked++
+	return nil
+}
+
+// save writes the manifest when files were recorded, except in dry-run mode
+func (m *initManifest) save() error {
+	if _, ok := m.filesystem.(fs.Recorder); ok || m.tracked == 0 {
+		return nil
+	}
+	return m.manifest.Save(m.filesystem, filepath.Join(m.baseDir, app.ManifestFile))
+}
+
+func createProjectStructure(filesystem fs.FileSystem, baseDir string, terraformVersion string, regions []string, environments []string, log *logger.Logger) error {
 	// Create base directory if it doesn't exist
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
+	if err := filesystem.MkdirAll(baseDir, 0755); err != nil {
 		return fmt.Errorf("failed to create base directory: %w", err)
 	}
 
+	manifest, err := app.LoadManifest(filesystem, filepath.Join(baseDir, app.ManifestFile))
+	if err != nil {
+		return err
+	}
+	written := &initManifest{filesystem: filesystem, baseDir: baseDir, manifest: manifest}
+
 	// Create root configuration files from templates
 	rootConfigFiles := []struct {
 		filename     string
@@ -225,39 +335,56 @@ func createProjectStructure(baseDir string, terraformVersion string, regions []s
 	}
 
 	for _, file := range rootConfigFiles {
-		if err := createFileFromTemplate(filepath.Join(baseDir, file.filename), file.templateName, nil, log); err != nil {
+		path := filepath.Join(baseDir, file.filename)
+		if err := written.create(path, file.templateName, func() error {
+			return createFileFromTemplate(filesystem, path, file.templateName, nil, log)
+		}); err != nil {
 			return err
 		}
 	}
 
+	// Read the layout before .tfskel.yaml is created, the default config uses the default layout
+	configPath := filepath.Join(baseDir, ".tfskel.yaml")
+	layout := targetLayout(filesystem, configPath)
+
 	// Create .tfskel.yaml config file
-	if err := createDefaultConfig(filepath.Join(baseDir, ".tfskel.yaml"), log); err != nil {
+	if err := createDefaultConfig(filesystem, configPath, log); err != nil {
 		return err
 	}
 
 	// Create environment directories using provided environments list
+	// Their location follows layout.app_path, envs/<env>/<region> by default
 	log.Debugf("Creating directory structure for %d environment(s): %v", len(environments), environments)
+	versionFiles := make(map[string]bool)
 	for _, env := range environments {
-		envPath := filepath.Join(baseDir, "envs", env)
+		envPath := filepath.Join(baseDir, layout.AppPath(env, "", ""))
 
-		// Create .terraform-version file
+		// Create .terraform-version file, once when environments share a directory
 		tfVersionPath := filepath.Join(envPath, ".terraform-version")
-		data := map[string]string{
-			"TerraformVersion": terraformVersion,
-		}
-		if err := createFileFromTemplate(tfVersionPath, "root/.terraform-version.tmpl", data, log); err != nil {
-			return err
+		if !versionFiles[tfVersionPath] {
+			versionFiles[tfVersionPath] = true
+			data := map[string]string{
+				"TerraformVersion": terraformVersion,
+			}
+			if err := written.create(tfVersionPath, "root/.terraform-version.tmpl", func() error {
+				return createFileFromTemplate(filesystem, tfVersionPath, "root/.terraform-version.tmpl", data, log)
+			}); err != nil {
+				return err
+			}
 		}
 
 		// Create region directories
 		for _, region := range regions {
-			regionPath := filepath.Join(envPath, region)
+			regionPath := filepath.Join(baseDir, layout.AppPath(env, region, ""))
+			if regionPath == envPath {
+				// The layout has no region directory above the app directory
+				continue
+			}
 
 			// Check if directory already exists
-			_, err := os.Stat(regionPath)
-			dirExists := err == nil
+			dirExists := filesystem.DirExists(regionPath)
 
-			if err := os.MkdirAll(regionPath, 0755); err != nil {
+			if err := filesystem.MkdirAll(regionPath, 0755); err != nil {
 				return fmt.Errorf("failed to create region directory %s: %w", regionPath, err)
 			}
 
@@ -269,27 +396,29 @@ func createProjectStructure(baseDir string, terraformVersion string, regions []s
 			}
 			if dirExists {
 				log.Infof("Directory %s/ already exists", relPath)
+				recordSkip(filesystem, fs.Change{Path: regionPath, IsDir: true, Reason: "already exists"})
 			} else {
 				log.Successf("Created directory: %s/", relPath)
 			}
 		}
 	}
 
-	return nil
+	return written.save()
 }
 
-func createFileFromTemplate(targetPath string, templateName string, data any, log *logger.Logger) error {
+func createFileFromTemplate(filesystem fs.FileSystem, targetPath string, templateName string, data any, log *logger.Logger) error {
 	// Check if file already exists
-	if _, err := os.Stat(targetPath); err == nil {
+	if filesystem.FileExists(targetPath) {
 		// File exists, skip creation
 		baseName := filepath.Base(targetPath)
 		log.Infof("%s already exists, skipping", baseName)
+		recordSkip(filesystem, fs.Change{Path: targetPath, Reason: "already exists"})
 		return nil
 	}
 
 	// Ensure parent directory exists
 	dir := filepath.Dir(targetPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := filesystem.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
@@ -307,7 +436,7 @@ func createFileFromTemplate(targetPath string, templateName string, data any, lo
 			return fmt.Errorf("failed to render template %s: %w", templateName, err)
 		}
 
-		if err := os.WriteFile(targetPath, []byte(content), 0644); err != nil {
+		if err := filesystem.WriteFile(targetPath, []byte(content), 0644); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", targetPath, err)
 		}
 		baseName := filepath.Base(targetPath)
@@ -325,18 +454,7 @@ func createFileFromTemplate(targetPath string, templateName string, data any, lo
 			return fmt.Errorf("failed to render template %s: %w", templateName, err)
 		}
 
-		file, err := os.Create(targetPath)
-		if err != nil {
-			return fmt.Errorf("failed to create file %s: %w", targetPath, err)
-		}
-		defer func() {
-			if closeErr := file.Close(); closeErr != nil {
-				// Log the error but don't override the main error
-				fmt.Fprintf(os.Stderr, "Warning: failed to close file %s: %v\n", targetPath, closeErr)
-			}
-		}()
-
-		if _, err := file.WriteString(content); err != nil {
+		if err := filesystem.WriteFile(targetPath, []byte(content), 0644); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", targetPath, err)
 		}
 
@@ -350,15 +468,17 @@ func createFileFromTemplate(targetPath string, templateName string, data any, lo
 	return ErrUnsupportedDataType
 }
 
-func createDefaultConfig(configPath string, log *logger.Logger) error {
+func createDefaultConfig(filesystem fs.FileSystem, configPath string, log *logger.Logger) error {
 	// Check if config file already exists
-	if _, err := os.Stat(configPath); err == nil {
+	if filesystem.FileExists(configPath) {
 		// File exists, skip creation
 		log.Infof(".tfskel.yaml already exists, skipping")
+		recordSkip(filesystem, fs.Change{Path: configPath, Reason: "already exists"})
 		return nil
 	}
 
 	defaultConfig := map[string]any{
+		"version":           config.CurrentVersion,
 		"terraform_version": "~> 1.13",
 		"provider": map[string]any{
 			"aws": map[string]any{
@@ -396,12 +516,13 @@ func createDefaultConfig(configPath string, log *logger.Logger) error {
 # Optional: Customize terraform_version, provider versions, regions, and default_tags as needed
 #
 # For more information, visit: https://github.com/ishuar/tfskel
+# yaml-language-server: $schema=https://raw.githubusercontent.com/ishuar/tfskel/main/schema/tfskel.schema.json
 
 `
 	fullContent := []byte(header + string(data))
 
 	// Write to file
-	if err := os.WriteFile(configPath, fullContent, 0644); err != nil {
+	if err := filesystem.WriteFile(configPath, fullContent, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"bytes"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
 	"github.com/ishuar/tfskel/internal/logger"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +23,7 @@ func TestCreateProjectStructure(t *testing.T) {
 		baseDir := t.TempDir()
 		log := logger.New(false)
 		environments := []string{"dev", "stg", "prd"}
-		err := createProjectStructure(baseDir, "1.13.1", []string{"eu-central-1"}, environments, log)
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.13.1", []string{"eu-central-1"}, environments, log)
 		require.NoError(t, err)
 
 		// Verify all root configuration files are created
@@ -52,7 +59,7 @@ func TestCreateProjectStructure(t *testing.T) {
 		log := logger.New(false)
 		environments := []string{"dev", "stg", "prd"}
 		regions := []string{"eu-central-1", "us-east-1", "ap-south-1"}
-		err := createProjectStructure(baseDir, "1.10.0", regions, environments, log)
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.10.0", regions, environments, log)
 		require.NoError(t, err)
 
 		// Verify all regions are created for all environments
@@ -68,7 +75,7 @@ func TestCreateProjectStructure(t *testing.T) {
 		baseDir := t.TempDir()
 		log := logger.New(false)
 		environments := []string{"dev", "stg", "prd"}
-		err := createProjectStructure(baseDir, "1.13.1", []string{"eu-central-1"}, environments, log)
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.13.1", []string{"eu-central-1"}, environments, log)
 		require.NoError(t, err)
 
 		// Test specifically for the refactored loop - ensure all files are created
@@ -101,7 +108,7 @@ func TestCreateProjectStructure(t *testing.T) {
 		require.NoError(t, err)
 
 		// Run create structure
-		err = createProjectStructure(baseDir, "1.13.1", []string{"eu-central-1"}, environments, log)
+		err = createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.13.1", []string{"eu-central-1"}, environments, log)
 		require.NoError(t, err)
 
 		// Verify existing file wasn't overwritten
@@ -120,7 +127,7 @@ func TestCreateProjectStructure(t *testing.T) {
 		version := "1.9.5"
 		environments := []string{"dev", "stg", "prd"}
 
-		err := createProjectStructure(baseDir, version, []string{"eu-central-1"}, environments, log)
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, version, []string{"eu-central-1"}, environments, log)
 		require.NoError(t, err)
 
 		for _, env := range []string{"dev", "stg", "prd"} {
@@ -135,7 +142,7 @@ func TestCreateProjectStructure(t *testing.T) {
 		baseDir := t.TempDir()
 		log := logger.New(false)
 		customEnvs := []string{"dev", "qa", "uat", "prd"}
-		err := createProjectStructure(baseDir, "1.13.1", []string{"eu-central-1"}, customEnvs, log)
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.13.1", []string{"eu-central-1"}, customEnvs, log)
 		require.NoError(t, err)
 
 		// Verify all custom environments are created
@@ -154,6 +161,62 @@ func TestCreateProjectStructure(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, len(customEnvs), len(entries), "Should only have custom environments")
 	})
+
+	t.Run("records the created files in the manifest", func(t *testing.T) {
+		baseDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(baseDir, ".gitignore"), []byte("# existing content"), 0644))
+
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.13.1", []string{"eu-central-1"}, []string{"dev"}, logger.New(false))
+		require.NoError(t, err)
+
+		manifest, err := app.LoadManifest(fs.NewOSFileSystem(), filepath.Join(baseDir, app.ManifestFile))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{".pre-commit-config.yaml", ".tflint.hcl", "trivy.yaml", "envs/dev/.terraform-version"}, slices.Collect(maps.Keys(manifest.Files)))
+		assert.Equal(t, "root/.terraform-version.tmpl", manifest.Files["envs/dev/.terraform-version"].Template)
+
+		// The user fills in .tfskel.yaml, status does not report it
+		configPath := filepath.Join(baseDir, ".tfskel.yaml")
+		content, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		edited := strings.ReplaceAll(string(content), "REPLACE_WITH_YOUR_DEV_ACCOUNT_ID", "123456789012")
+		require.NoError(t, os.WriteFile(configPath, []byte(edited), 0644))
+
+		t.Chdir(baseDir)
+		statuses, err := app.NewGenerator(&config.Config{}, fs.NewOSFileSystem(), logger.New(false)).Status()
+		require.NoError(t, err)
+		require.Len(t, statuses, len(manifest.Files))
+		for _, status := range statuses {
+			assert.Equal(t, app.StateClean, status.State, status.Path)
+		}
+	})
+
+	t.Run("follows layout.app_path of an existing config", func(t *testing.T) {
+		baseDir := t.TempDir()
+		configContent := "layout:\n  app_path: accounts/{{.Env}}/{{.Region}}/{{.AppDir}}\n"
+		require.NoError(t, os.WriteFile(filepath.Join(baseDir, ".tfskel.yaml"), []byte(configContent), 0644))
+
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.13.1", []string{"eu-central-1"}, []string{"dev", "prd"}, logger.New(false))
+		require.NoError(t, err)
+
+		for _, env := range []string{"dev", "prd"} {
+			assert.FileExists(t, filepath.Join(baseDir, "accounts", env, ".terraform-version"))
+			assert.DirExists(t, filepath.Join(baseDir, "accounts", env, "eu-central-1"))
+		}
+		assert.NoDirExists(t, filepath.Join(baseDir, "envs"))
+	})
+
+	t.Run("layout without environment directories writes one version file", func(t *testing.T) {
+		baseDir := t.TempDir()
+		configContent := "layout:\n  app_path: '{{.AppDir}}/{{.Env}}'\n"
+		require.NoError(t, os.WriteFile(filepath.Join(baseDir, ".tfskel.yaml"), []byte(configContent), 0644))
+
+		err := createProjectStructure(fs.NewOSFileSystem(), baseDir, "1.13.1", []string{"eu-central-1"}, []string{"dev", "prd"}, logger.New(false))
+		require.NoError(t, err)
+
+		assert.FileExists(t, filepath.Join(baseDir, ".terraform-version"))
+		assert.NoDirExists(t, filepath.Join(baseDir, "envs"))
+		assert.NoDirExists(t, filepath.Join(baseDir, "eu-central-1"))
+	})
 }
 
 func TestCreateFileFromTemplate(t *testing.T) {
@@ -162,7 +225,7 @@ func TestCreateFileFromTemplate(t *testing.T) {
 		targetPath := filepath.Join(tmpDir, "test", "file.txt")
 		log := logger.New(false)
 
-		err := createFileFromTemplate(targetPath, "root/.gitignore.tmpl", nil, log)
+		err := createFileFromTemplate(fs.NewOSFileSystem(), targetPath, "root/.gitignore.tmpl", nil, log)
 		require.NoError(t, err)
 
 		assert.FileExists(t, targetPath)
@@ -176,7 +239,7 @@ func TestCreateFileFromTemplate(t *testing.T) {
 		targetPath := filepath.Join(tmpDir, "test", "file.txt")
 		log := logger.New(false)
 
-		err := createFileFromTemplate(targetPath, "root/.terraform-version.tmpl", map[string]string{
+		err := createFileFromTemplate(fs.NewOSFileSystem(), targetPath, "root/.terraform-version.tmpl", map[string]string{
 			"TerraformVersion": "1.13.1",
 		}, log)
 		require.NoError(t, err)
@@ -197,7 +260,7 @@ func TestCreateFileFromTemplate(t *testing.T) {
 		require.NoError(t, err)
 
 		// Try to create from template
-		err = createFileFromTemplate(targetPath, "root/.gitignore.tmpl", nil, log)
+		err = createFileFromTemplate(fs.NewOSFileSystem(), targetPath, "root/.gitignore.tmpl", nil, log)
 		require.NoError(t, err)
 
 		// Verify file wasn't overwritten
@@ -211,7 +274,7 @@ func TestCreateFileFromTemplate(t *testing.T) {
 		targetPath := filepath.Join(tmpDir, "deep", "nested", "path", "file.txt")
 		log := logger.New(false)
 
-		err := createFileFromTemplate(targetPath, "root/.gitignore.tmpl", nil, log)
+		err := createFileFromTemplate(fs.NewOSFileSystem(), targetPath, "root/.gitignore.tmpl", nil, log)
 		require.NoError(t, err)
 
 		assert.FileExists(t, targetPath)
@@ -225,7 +288,7 @@ func TestCreateFileFromTemplate(t *testing.T) {
 		files := []string{".gitignore", ".tflint.hcl", "trivy.yaml"}
 		for _, filename := range files {
 			targetPath := filepath.Join(tmpDir, filename)
-			err := createFileFromTemplate(targetPath, "root/"+filename+".tmpl", nil, log)
+			err := createFileFromTemplate(fs.NewOSFileSystem(), targetPath, "root/"+filename+".tmpl", nil, log)
 			require.NoError(t, err)
 			assert.FileExists(t, targetPath)
 		}
@@ -238,7 +301,7 @@ func TestCreateDefaultConfig(t *testing.T) {
 		configPath := filepath.Join(tmpDir, ".tfskel.yaml")
 		log := logger.New(false)
 
-		err := createDefaultConfig(configPath, log)
+		err := createDefaultConfig(fs.NewOSFileSystem(), configPath, log)
 		require.NoError(t, err)
 
 		assert.FileExists(t, configPath)
@@ -268,7 +331,7 @@ func TestCreateDefaultConfig(t *testing.T) {
 		err := os.WriteFile(configPath, []byte("existing"), 0644)
 		require.NoError(t, err)
 
-		err = createDefaultConfig(configPath, log)
+		err = createDefaultConfig(fs.NewOSFileSystem(), configPath, log)
 		require.NoError(t, err)
 
 		// Should not overwrite
@@ -283,7 +346,7 @@ func TestDetermineInitParameters(t *testing.T) {
 		tmpDir := t.TempDir()
 		log := logger.New(false)
 
-		envs, tfVersion, regions, err := determineInitParameters(tmpDir, log)
+		envs, tfVersion, regions, err := determineInitParameters(fs.NewOSFileSystem(), tmpDir, log)
 		require.NoError(t, err)
 
 		assert.Equal(t, []string{"dev", "stg", "prd"}, envs)
@@ -313,7 +376,7 @@ provider:
 		err := os.WriteFile(configPath, []byte(configContent), 0644)
 		require.NoError(t, err)
 
-		envs, tfVersion, regions, err := determineInitParameters(tmpDir, log)
+		envs, tfVersion, regions, err := determineInitParameters(fs.NewOSFileSystem(), tmpDir, log)
 		require.NoError(t, err)
 
 		// Check that all environments from account_mapping are present
@@ -343,7 +406,7 @@ provider:
 		err := os.WriteFile(configPath, []byte(configContent), 0644)
 		require.NoError(t, err)
 
-		_, _, _, err = determineInitParameters(tmpDir, log)
+		_, _, _, err = determineInitParameters(fs.NewOSFileSystem(), tmpDir, log)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "account_mapping is missing or empty")
 	})
@@ -365,7 +428,7 @@ provider:
 		err := os.WriteFile(configPath, []byte(configContent), 0644)
 		require.NoError(t, err)
 
-		_, _, _, err = determineInitParameters(tmpDir, log)
+		_, _, _, err = determineInitParameters(fs.NewOSFileSystem(), tmpDir, log)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "account_mapping is missing or empty")
 	})
@@ -380,7 +443,7 @@ provider:
 		err := os.WriteFile(configPath, []byte(configContent), 0644)
 		require.NoError(t, err)
 
-		envs, tfVersion, regions, err := determineInitParameters(tmpDir, log)
+		envs, tfVersion, regions, err := determineInitParameters(fs.NewOSFileSystem(), tmpDir, log)
 		require.NoError(t, err)
 
 		// Should fall back to defaults
@@ -403,7 +466,7 @@ provider:
 		err := os.WriteFile(configPath, []byte(configContent), 0644)
 		require.NoError(t, err)
 
-		_, tfVersion, _, err := determineInitParameters(tmpDir, log)
+		_, tfVersion, _, err := determineInitParameters(fs.NewOSFileSystem(), tmpDir, log)
 		require.NoError(t, err)
 
 		assert.Equal(t, "1.10.2", tfVersion)
@@ -423,7 +486,7 @@ provider:
 		err := os.WriteFile(configPath, []byte(configContent), 0644)
 		require.NoError(t, err)
 
-		_, _, regions, err := determineInitParameters(tmpDir, log)
+		_, _, regions, err := determineInitParameters(fs.NewOSFileSystem(), tmpDir, log)
 		require.NoError(t, err)
 
 		assert.Equal(t, []string{"eu-central-1"}, regions)
@@ -473,6 +536,53 @@ func TestRunInit(t *testing.T) {
 	})
 }
 
+func TestRunInit_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	initDir = tmpDir
+	initDryRun = true
+	t.Cleanup(func() {
+		initDir = ""
+		initDryRun = false
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("custom\n"), 0644))
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.Flags().String("config", "", "config file")
+	cmd.SetOut(&out)
+
+	require.NoError(t, runInit(cmd, []string{}))
+
+	// Only the pre-existing file is on disk
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	plan := out.String()
+	assert.Contains(t, plan, "= skip   file .gitignore (already exists)")
+	assert.Contains(t, plan, "+ create file .tfskel.yaml")
+	assert.Contains(t, plan, "+ create dir  "+filepath.Join("envs", "dev", "eu-central-1")+string(filepath.Separator))
+	assert.Contains(t, plan, "+ create file "+filepath.Join("envs", "prd", ".terraform-version"))
+}
+
+func TestDetermineInitParameters_FileSystem(t *testing.T) {
+	filesystem := fs.NewMemoryFileSystem()
+	require.NoError(t, filesystem.WriteFile(filepath.Join("project", ".tfskel.yaml"), []byte(`
+terraform_version: "~> 1.12"
+provider:
+  aws:
+    account_mapping:
+      dev: "123456789012"
+    regions: [us-east-1]
+`), 0644))
+
+	envs, tfVersion, regions, err := determineInitParameters(filesystem, "project", logger.New(false))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, envs)
+	assert.Equal(t, "1.12.0", tfVersion)
+	assert.Equal(t, []string{"us-east-1"}, regions)
+}
+
 func TestInitCmd(t *testing.T) {
 	assert.NotNil(t, initCmd)
 	assert.Equal(t, "init", initCmd.Use)
@@ -482,3 +592,32 @@ func TestInitCmd(t *testing.T) {
 	assert.NotNil(t, dirFlag)
 	assert.Equal(t, "d", dirFlag.Shorthand)
 }
+
+func TestRunPostInitHooks(t *testing.T) {
+	filesystem := fs.NewMemoryFileSystem()
+	require.NoError(t, filesystem.WriteFile(filepath.Join("project", ".tfskel.yaml"), []byte(`
+provider:
+  aws:
+    account_mapping:
+      dev: "123456789012"
+hooks:
+  post_init:
+    - name: git
+      command: ["git", "init"]
+    - command: ["echo", "terraform {{.TerraformVersion}}"]
+`), 0644))
+
+	var calls [][]string
+	runner := app.NewHookRunner(func(dir, name string, args ...string) ([]byte, error) {
+		assert.Equal(t, "project", dir)
+		calls = append(calls, append([]string{name}, args...))
+		return nil, nil
+	}, logger.New(false))
+
+	require.NoError(t, runPostInitHooks(runner, filesystem, "project", "1.13.1", logger.New(false)))
+	assert.Equal(t, [][]string{{"git", "init"}, {"echo", "terraform 1.13.1"}}, calls)
+
+	// Projects without a config have no hooks to run
+	require.NoError(t, runPostInitHooks(runner, filesystem, "other", "1.13.1", logger.New(false)))
+	assert.Len(t, calls, 2)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/drift"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every app across environments and regions",
+	Long: `Walk the app directories of layout.app_path (envs/<env>/<region>/<app> by default)
+and print an inventory of them.
+
+Each row has the app, environment, region and short region, the backend type with
+the location of the state (bucket and key for s3, bucket and prefix for gcs,
+container and key for azurerm, organization and workspace for cloud, empty for
+http and local), the Terraform and AWS provider version constraints, whether
+GitHub workflows exist for the app and whether backend.tf or versions.tf carry
+the tfskel metadata that marks them as managed.
+
+Use --format json or --format csv to feed the inventory to other tooling.`,
+	Example: `  # Show every app as a table
+  tfskel list
+
+  # Export the dev inventory as CSV
+  tfskel list --env dev --format csv > apps.csv
+
+  # Find apps without workflows
+  tfskel list --format json | jq '.[] | select(.workflows | not) | .path'`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+var (
+	listFormat string
+	listEnv    string
+	listRegion string
+)
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", formatTable, "Output format: table, json, csv")
+	listCmd.Flags().StringVarP(&listEnv, "env", "e", "", "only list apps of this environment")
+	listCmd.Flags().StringVarP(&listRegion, "region", "r", "", "only list apps in this region")
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting list command")
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	// Keep stdout machine-readable for JSON/CSV
+	if listFormat == formatJSON || listFormat == formatCSV {
+		log.SetOutput(os.Stderr)
+	}
+
+	generator := app.NewGenerator(cfg, fs.NewOSFileSystem(), log)
+	entries, err := generator.Inventory()
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to list apps: %w", err)
+	}
+	entries = filterInventory(entries, listEnv, listRegion)
+
+	if err := printInventory(cmd.OutOrStdout(), entries, listFormat); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	if len(entries) == 0 && listFormat == formatTable {
+		log.Warn("No app directories found")
+	}
+	return nil
+}
+
+// filterInventory keeps the entries matching env and region, empty values match everything
+func filterInventory(entries []app.InventoryEntry, env, region string) []app.InventoryEntry {
+	filtered := make([]app.InventoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		if (env == "" || entry.Env == env) && (region == "" || entry.Region == region) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// inventoryHeader is the column order of the table and CSV inventory
+var inventoryHeader = []string{"APP", "ENV", "REGION", "SHORT", "BACKEND", "BUCKET", "KEY", "TERRAFORM", "AWS", "WORKFLOWS", "MANAGED"}
+
+// printInventory writes the inventory in the requested format
+func printInventory(w io.Writer, entries []app.InventoryEntry, format string) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, joinColumns(inventoryHeader))
+		for _, entry := range entries {
+			fmt.Fprintln(tw, joinColumns(inventoryRow(entry, "-")))
+		}
+		return tw.Flush()
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(entries)
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		header := make([]string, len(inventoryHeader))
+		for i, column := range inventoryHeader {
+			header[i] = strings.ToLower(column)
+		}
+		if err := writer.Write(header); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		for _, entry := range entries {
+			if err := writer.Write(inventoryRow(entry, "")); err != nil {
+				return fmt.Errorf("failed to write CSV: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("%w: %s", drift.ErrUnsupportedFormat, format)
+	}
+}
+
+// inventoryRow returns the columns of an entry, empty values are replaced with placeholder
+func inventoryRow(entry app.InventoryEntry, placeholder string) []string {
+	row := []string{
+		entry.App, entry.Env, entry.Region, entry.ShortRegion, entry.Backend, entry.Bucket, entry.Key,
+		entry.TerraformVersion, entry.AWSProvider, strconv.FormatBool(entry.Workflows), strconv.FormatBool(entry.Managed),
+	}
+	for i, value := range row {
+		if value == "" {
+			row[i] = placeholder
+		}
+	}
+	return row
+}
+
+// joinColumns joins columns with tabs for a tabwriter
+func joinColumns(columns []string) string {
+	return strings.Join(columns, "\t")
+}
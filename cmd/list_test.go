@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/drift"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintInventory(t *testing.T) {
+	entries := []app.InventoryEntry{
+		{
+			App: "payments", Env: "dev", Region: "us-east-1", ShortRegion: "use1", Path: "envs/dev/us-east-1/payments",
+			Backend: "s3", Bucket: "tfstate", Key: "payments-dev-us-east-1/terraform.tfstate",
+			TerraformVersion: "~> 1.13", AWSProvider: "~> 6.0", Workflows: true, Managed: true,
+		},
+		{App: "legacy", Env: "prd", Region: "eu-central-1", ShortRegion: "euc1", Path: "envs/prd/eu-central-1/legacy"},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printInventory(&out, entries, formatCSV))
+		assert.Equal(t, "app,env,region,short,backend,bucket,key,terraform,aws,workflows,managed\n"+
+			"payments,dev,us-east-1,use1,s3,tfstate,payments-dev-us-east-1/terraform.tfstate,~> 1.13,~> 6.0,true,true\n"+
+			"legacy,prd,eu-central-1,euc1,,,,,,false,false\n", out.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printInventory(&out, entries, formatJSON))
+		assert.Contains(t, out.String(), `"terraformVersion": "~> 1.13"`)
+		assert.Contains(t, out.String(), `"path": "envs/prd/eu-central-1/legacy"`)
+	})
+
+	t.Run("table uses placeholders", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printInventory(&out, entries, formatTable))
+		assert.Regexp(t, `legacy\s+prd\s+eu-central-1\s+euc1\s+-\s+-`, out.String())
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		assert.ErrorIs(t, printInventory(&bytes.Buffer{}, entries, "xml"), drift.ErrUnsupportedFormat)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		assert.Len(t, filterInventory(entries, "dev", ""), 1)
+		assert.Len(t, filterInventory(entries, "", "eu-central-1"), 1)
+		assert.Len(t, filterInventory(entries, "", ""), 2)
+	})
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var moveCmd = &cobra.Command{
+	Use:   "move <app-dir>",
+	Short: "Rename an app or move it to another region",
+	Long: `Move the app directory envs/<env>/<region>/<app-dir>, or its layout.app_path
+directory, to a new name and/or region.
+
+move renames the directory, re-renders the files that were not edited since they were
+generated, points the backend state attributes (e.g. the S3 key
+<app>-<env>-<region>/terraform.tfstate) at the new target and renames the GitHub
+workflows with their path filters updated. Files that were edited are moved as-is
+and listed so you can review them.
+
+The remote state itself is not moved. move prints the exact terraform init
+-migrate-state procedure, and for S3 an aws s3 cp alternative, to copy the state
+from the old location to the new one.`,
+	Example: `  # Rename the payments app to billing
+  tfskel move payments --env dev --region us-east-1 --to-name billing
+
+  # Move the payments app to eu-west-1
+  tfskel move payments --env dev --region us-east-1 --to-region eu-west-1
+
+  # Preview the move
+  tfskel move payments --env dev --region us-east-1 --to-name billing --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMove,
+}
+
+var (
+	moveEnv      string
+	moveRegion   string
+	moveToName   string
+	moveToRegion string
+	moveDryRun   bool
+)
+
+func init() {
+	rootCmd.AddCommand(moveCmd)
+
+	moveCmd.Flags().StringVarP(&moveEnv, "env", "e", "", "environment of the app to move (e.g., dev, stg, prd)")
+	moveCmd.Flags().StringVarP(&moveRegion, "region", "r", "", "current region of the app (e.g., us-east-1)")
+	moveCmd.Flags().StringVar(&moveToName, "to-name", "", "new app directory name")
+	moveCmd.Flags().StringVar(&moveToRegion, "to-region", "", "region to move the app to")
+	moveCmd.Flags().BoolVar(&moveDryRun, "dry-run", false, "print the planned changes without moving anything")
+	moveCmd.MarkFlagsOneRequired("to-name", "to-region")
+}
+
+func runMove(cmd *cobra.Command, args []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting move command")
+
+	appDir := args[0]
+	if err := validateGenerateParams(moveEnv, moveRegion, appDir); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	toName, toRegion := moveToName, moveToRegion
+	if toName == "" {
+		toName = appDir
+	}
+	if toRegion == "" {
+		toRegion = moveRegion
+	}
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	filesystem, runLog := newRunFileSystem(moveDryRun, log)
+	generator := app.NewGenerator(cfg, filesystem, runLog)
+
+	result, err := generator.Move(moveEnv, moveRegion, appDir, toRegion, toName)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("move failed: %w", err)
+	}
+
+	if recorder, ok := filesystem.(fs.Recorder); ok {
+		printDryRunPlan(cmd.OutOrStdout(), recorder.Changes(), ".")
+	} else {
+		log.Successf("Moved %s to %s and renamed %d workflows", result.From, result.To, len(result.Workflows))
+	}
+
+	for _, path := range result.Modified {
+		log.Warnf("%s was edited after generation and moved as-is, check it for references to the old location", path)
+	}
+	if _, declared := cfg.FindApp(appDir); declared && toName != appDir {
+		log.Warnf("%s is declared in the apps inventory, rename it to %s in the configuration file", appDir, toName)
+	}
+
+	printMigrationSteps(cmd.OutOrStdout(), result)
+	return nil
+}
+
+// printMigrationSteps prints the procedure that moves the remote state of a moved app
+func printMigrationSteps(w io.Writer, result *app.MoveResult) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "State migration:")
+	for _, step := range app.MigrationSteps(result) {
+		if step == "" {
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintln(w, "  "+step)
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ErrSameEnvironment indicates promote was asked to copy an app onto itself
+var ErrSameEnvironment = errors.New("--from and --to must be different environments")
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote <app-dir>",
+	Short: "Copy an app from one environment to another",
+	Long: `Promote envs/<from>/<region>/<app-dir> to envs/<to>/<region>/<app-dir>, or the
+directories layout.app_path places them in.
+
+The .tf files of the source app are copied to the target environment, files that
+already exist in the target are never overwritten. backend.tf, versions.tf, the
+GitHub workflows and the files of managed and always templates are not copied but
+generated for the target environment, exactly like generate does. Files of
+create-only templates, e.g. a main.tf from templates_dir, are copied from the source.
+
+--include and --exclude take glob patterns. Patterns without a slash match the
+file name in any subdirectory (e.g. *.tf), patterns with a slash match the path
+relative to the app directory (e.g. modules/*/main.tf). Only *.tf files are
+promoted by default.
+
+After the promotion every selected file that differs between the two environments,
+or exists in only one of them, is reported; --diff prints the differences.`,
+	Example: `  # Promote the payments app from dev to stg
+  tfskel promote payments --from dev --to stg --region us-east-1
+
+  # Preview the promotion
+  tfskel promote payments --from dev --to stg --region us-east-1 --dry-run
+
+  # Promote .tf and .tfvars files except the dev-only seed data
+  tfskel promote payments --from dev --to stg --region us-east-1 \
+    --include '*.tf' --include '*.tfvars' --exclude seed.tf --diff`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPromote,
+}
+
+var (
+	promoteFrom    string
+	promoteTo      string
+	promoteRegion  string
+	promoteInclude []string
+	promoteExclude []string
+	promoteDryRun  bool
+	promoteDiff    bool
+	promoteNoColor bool
+	promoteNoHooks bool
+)
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+
+	promoteCmd.Flags().StringVar(&promoteFrom, "from", "", "environment to promote the app from (e.g., dev)")
+	promoteCmd.Flags().StringVar(&promoteTo, "to", "", "environment to promote the app to (e.g., stg)")
+	promoteCmd.Flags().StringVarP(&promoteRegion, "region", "r", "", "region of the app (e.g., us-east-1)")
+	promoteCmd.Flags().StringSliceVar(&promoteInclude, "include", nil, "glob of files to promote (repeatable, default *.tf)")
+	promoteCmd.Flags().StringSliceVar(&promoteExclude, "exclude", nil, "glob of files to leave out (repeatable)")
+	promoteCmd.Flags().BoolVar(&promoteDryRun, "dry-run", false, "print the planned changes without writing anything")
+	promoteCmd.Flags().BoolVar(&promoteDiff, "diff", false, "show a unified diff for files that differ between the environments and for managed file updates")
+	promoteCmd.Flags().BoolVar(&promoteNoColor, "no-color", false, "disable colored diff output")
+	promoteCmd.Flags().BoolVar(&promoteNoHooks, "no-hooks", false, "skip post_generate hooks")
+	_ = promoteCmd.MarkFlagRequired("from") //nolint:errcheck // flag is defined above
+	_ = promoteCmd.MarkFlagRequired("to")   //nolint:errcheck // flag is defined above
+}
+
+func runPromote(cmd *cobra.Command, args []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting promote command")
+
+	appDir := args[0]
+	if err := validateGenerateParams(promoteTo, promoteRegion, appDir); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+	if promoteFrom == promoteTo {
+		return fmt.Errorf("invalid parameters: %w", ErrSameEnvironment)
+	}
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	if err := validateAccountMapping(cfg, promoteTo); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	filesystem, runLog := newRunFileSystem(promoteDryRun, log)
+	generator := app.NewGenerator(cfg, filesystem, runLog)
+	if promoteNoHooks {
+		generator.SetHookRunner(nil)
+	}
+	if promoteDiff {
+		generator.SetDiffOptions(&app.DiffOptions{Out: cmd.OutOrStdout(), UseColor: !promoteNoColor})
+	}
+
+	result, err := generator.Promote(appDir, promoteFrom, promoteTo, promoteRegion, app.PromoteOptions{
+		Include: promoteInclude,
+		Exclude: promoteExclude,
+	})
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("promote failed: %w", err)
+	}
+
+	if recorder, ok := filesystem.(fs.Recorder); ok {
+		printDryRunPlan(cmd.OutOrStdout(), recorder.Changes(), ".")
+	} else {
+		log.Successf("Promoted %s to %s: %d files copied, %d kept", result.From, result.To, len(result.Copied), len(result.Skipped))
+	}
+	printPromoteDifferences(cmd.OutOrStdout(), result, promoteDiff, !promoteNoColor)
+	return nil
+}
+
+// printPromoteDifferences lists the files that differ between the environments after a promotion
+func printPromoteDifferences(w io.Writer, result *app.PromoteResult, showDiff, useColor bool) {
+	if len(result.Differences) == 0 {
+		fmt.Fprintf(w, "\nThe promoted files of %s and %s are identical\n", result.From, result.To)
+		return
+	}
+
+	fmt.Fprintf(w, "\nFiles that differ between %s and %s:\n", result.From, result.To)
+	for _, difference := range result.Differences {
+		fmt.Fprintf(w, "  %s (%s)\n", difference.Path, difference.Reason)
+	}
+	if !showDiff {
+		return
+	}
+	for _, difference := range result.Differences {
+		if difference.Diff != "" {
+			fmt.Fprintln(w)
+			fmt.Fprint(w, app.ColorizeDiff(difference.Diff, useColor))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintPromoteDifferences(t *testing.T) {
+	result := &app.PromoteResult{
+		From: "envs/dev/us-east-1/payments",
+		To:   "envs/stg/us-east-1/payments",
+		Differences: []app.PromoteDifference{
+			{Path: "main.tf", Reason: "content differs", Diff: "--- a/main.tf\n+++ b/main.tf\n"},
+			{Path: "stg.tf", Reason: "only in envs/stg/us-east-1/payments"},
+		},
+	}
+
+	var out bytes.Buffer
+	printPromoteDifferences(&out, result, false, false)
+	assert.Contains(t, out.String(), "  main.tf (content differs)")
+	assert.Contains(t, out.String(), "  stg.tf (only in envs/stg/us-east-1/payments)")
+	assert.NotContains(t, out.String(), "+++ b/main.tf")
+
+	out.Reset()
+	printPromoteDifferences(&out, result, true, false)
+	assert.Contains(t, out.String(), "+++ b/main.tf")
+
+	out.Reset()
+	printPromoteDifferences(&out, &app.PromoteResult{From: result.From, To: result.To}, true, false)
+	assert.Contains(t, out.String(), "are identical")
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var removeCmd = &cobra.Command{
+	Use:   "remove <app-dir>",
+	Short: "Remove an app scaffold and its GitHub workflows",
+	Long: `Remove the app directory envs/<env>/<region>/<app-dir> (or its layout.app_path
+directory) and the GitHub workflows generated for it
+(.github/workflows/<app>-<env>-<short-region>-*.yaml, or the names produced by
+name_template). Reusable workflows are shared and always kept.
+
+remove refuses to delete a directory that contains .tf files tfskel did not generate
+(neither recorded in .tfskel.lock nor produced by a template) unless --force is passed.
+
+remove only deletes files: the remote state configured in backend.tf and the
+infrastructure it tracks still exist. Run terraform destroy first if the resources
+should go as well.`,
+	Example: `  # Remove the payments app in dev/us-east-1
+  tfskel remove payments --env dev --region us-east-1
+
+  # Preview what would be deleted
+  tfskel remove payments --env dev --region us-east-1 --dry-run
+
+  # Also delete hand-written .tf files in the app directory
+  tfskel remove payments --env dev --region us-east-1 --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemove,
+}
+
+var (
+	removeEnv    string
+	removeRegion string
+	removeForce  bool
+	removeDryRun bool
+)
+
+func init() {
+	rootCmd.AddCommand(removeCmd)
+
+	removeCmd.Flags().StringVarP(&removeEnv, "env", "e", "", "environment of the app to remove (e.g., dev, stg, prd)")
+	removeCmd.Flags().StringVarP(&removeRegion, "region", "r", "", "region of the app to remove (e.g., us-east-1)")
+	removeCmd.Flags().BoolVar(&removeForce, "force", false, "remove the app directory even if it contains .tf files that were not generated")
+	removeCmd.Flags().BoolVar(&removeDryRun, "dry-run", false, "print the files that would be deleted without deleting anything")
+}
+
+func runRemove(cmd *cobra.Command, args []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting remove command")
+
+	appDir := args[0]
+	if err := validateGenerateParams(removeEnv, removeRegion, appDir); err != nil {
+		return fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	filesystem, runLog := newRunFileSystem(removeDryRun, log)
+	generator := app.NewGenerator(cfg, filesystem, runLog)
+
+	result, err := generator.Remove(removeEnv, removeRegion, appDir, removeForce)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("remove failed: %w", err)
+	}
+
+	if recorder, ok := filesystem.(fs.Recorder); ok {
+		printDryRunPlan(cmd.OutOrStdout(), recorder.Changes(), ".")
+	} else {
+		log.Successf("Removed %d files and %d workflows of %s", len(result.Files), len(result.Workflows), appDir)
+	}
+	printStateReminder(result, log)
+	return nil
+}
+
+// printStateReminder warns that the remote state of a removed app still exists
+func printStateReminder(result *app.RemoveResult, log *logger.Logger) {
+	if result.State == nil {
+		log.Warn("The remote state of this app still exists, delete it from your backend once it is no longer needed")
+		return
+	}
+	log.Warnf("The remote state of %s still exists: %s", result.AppPath, result.State)
+	log.Warn("Delete it from your backend once the infrastructure was destroyed and the state is no longer needed")
+}
@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/ishuar/tfskel/internal/config"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -36,10 +39,26 @@ What you get:
   - Simple, declarative customization via .tfskel.yaml
 
 Configuration:
-  tfskel automatically loads .tfskel.yaml from the current directory.
-  Use --config to point to a different file (this always takes precedence).`,
+  tfskel finds the project root from the current directory: the nearest directory
+  at or above it with .tfskel.yaml or an envs/ directory. Config files in the app
+  directories of layout.app_path (envs/<env>/<region>/<app> by default) and the
+  directories above them belong to the project, not to a root of their own.
+  tfskel loads .tfskel.yaml from the root and resolves every output path relative to it.
+  Use --config to point to a different file (this always takes precedence).
+  The file may extend a shared base file with extends: <path>, and .tfskel.yaml files
+  in the directories below are merged in for the apps they contain.`,
 
 	Version: Version,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		if err := enterProjectRoot(cmd); err != nil {
+			return err
+		}
+		if err := initConfig(); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -49,10 +68,9 @@ func Execute() error {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is .tfskel.yaml in current directory)")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is .tfskel.yaml in the project root)")
+	_ = rootCmd.MarkPersistentFlagFilename("config", "yaml", "yml") //nolint:errcheck // flag is defined above
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 
 	// Bind flags to viper
@@ -62,15 +80,63 @@ func init() {
 	}
 }
 
+// enterProjectRoot changes into the project root found from the working directory, see config.FindRoot,
+// so that the config file and every output path are resolved from it. File and directory flags
+// are made absolute first to keep pointing where they were given. tfskel init runs where it is invoked
+func enterProjectRoot(cmd *cobra.Command) error {
+	if cmd == initCmd {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	root, ok := config.FindRoot(cwd)
+	if !ok {
+		return nil
+	}
+	if err := absolutePathFlags(cmd, cwd); err != nil {
+		return err
+	}
+
+	if root != cwd || verbose {
+		fmt.Fprintln(os.Stderr, "Using project root:", root)
+	}
+	if root == cwd {
+		return nil
+	}
+	if err := os.Chdir(root); err != nil {
+		return fmt.Errorf("failed to change into project root %s: %w", root, err)
+	}
+	return nil
+}
+
+// absolutePathFlags makes the values of the file and directory flags of cmd absolute, relative to dir
+// Path flags are the ones marked with MarkFlagFilename or MarkFlagDirname
+func absolutePathFlags(cmd *cobra.Command, dir string) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		_, file := flag.Annotations[cobra.BashCompFilenameExt]
+		_, subdirs := flag.Annotations[cobra.BashCompSubdirsInDir]
+		value := flag.Value.String()
+		if err != nil || (!file && !subdirs) || value == "" || filepath.IsAbs(value) {
+			return
+		}
+		err = flag.Value.Set(filepath.Join(dir, value))
+	})
+	return err
+}
+
 // initConfig reads in config file and ENV variables if set.
-// Similar to Trivy's approach: checks current directory by default,
+// Similar to Trivy's approach: checks the project root by default,
 // --config | -c flag takes precedence if specified.
-func initConfig() {
+// Errors of the files the config file extends are returned, a partially merged config is never used
+func initConfig() error {
 	if cfgFile != "" {
 		// Use config file from the flag (takes precedence).
 		viper.SetConfigFile(cfgFile)
 	} else {
-		// Search config in current directory first (Trivy-like behavior)
+		// Search config in the project root, the working directory by now (Trivy-like behavior)
 		viper.AddConfigPath(".")
 		viper.SetConfigType("yaml")
 		viper.SetConfigName(".tfskel")
@@ -78,8 +144,16 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil && verbose {
+	// If a config file is found, read it in along with the files it extends.
+	// Errors of the config file itself are reported by the commands that load the configuration
+	if viper.ReadInConfig() != nil {
+		return nil
+	}
+	if verbose {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+	if err := config.MergeExtends(viper.GetViper()); err != nil {
+		return fmt.Errorf("failed to load %s: %w", viper.ConfigFileUsed(), err)
+	}
+	return nil
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnterProjectRoot(t *testing.T) {
+	project, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "envs", "dev"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".tfskel.yaml"), []byte("terraform_version: \"~> 1.13\"\n"), 0644))
+	t.Chdir(filepath.Join(project, "envs", "dev"))
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("path", ".", "")
+	cmd.Flags().String("plan-file", "", "")
+	cmd.Flags().String("name", "app", "")
+	require.NoError(t, cmd.MarkFlagDirname("path"))
+	require.NoError(t, cmd.MarkFlagFilename("plan-file", "json"))
+	require.NoError(t, cmd.Flags().Set("plan-file", "plan.json"))
+
+	require.NoError(t, enterProjectRoot(cmd))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, project, cwd)
+	assert.Equal(t, filepath.Join(project, "envs", "dev"), cmd.Flag("path").Value.String())
+	assert.Equal(t, filepath.Join(project, "envs", "dev", "plan.json"), cmd.Flag("plan-file").Value.String())
+	assert.Equal(t, "app", cmd.Flag("name").Value.String(), "other flags are left alone")
+	assert.False(t, cmd.Flag("path").Changed)
+}
+
+func TestInitConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Cleanup(viper.Reset)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".tfskel.yaml"), []byte("extends: base.yaml\nterraform_version: \"~> 1.13\"\n"), 0644))
+	err := initConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "base.yaml")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("provider:\n  aws:\n    version: \"~> 6.0\"\n"), 0644))
+	viper.Reset()
+	require.NoError(t, initConfig())
+	assert.Equal(t, "~> 6.0", viper.GetString("provider.aws.version"))
+	assert.Equal(t, "~> 1.13", viper.GetString("terraform_version"))
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether generated files are clean, modified, outdated or missing",
+	Long: `Compare every file recorded in .tfskel.lock with the repository.
+
+generate and sync record each file they write in .tfskel.lock together with the
+template it came from, the environment, region and app it was rendered for and
+the hashes of the rendered and written content. init records the project files it
+renders from templates, which are only checked for edits and removal; .tfskel.yaml
+is not tracked. status reports each file as:
+
+  clean     unchanged since generation and up to date
+  modified  edited after generation
+  outdated  the template or config changed, generate would produce different content
+  missing   deleted after generation
+
+Commit .tfskel.lock with the generated files so the whole team sees the same status.`,
+	Example: `  # Show the status of every generated file
+  tfskel status
+
+  # Fail in CI when a generated file is not clean
+  tfskel status --exit-code`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+var statusExitCode bool
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().BoolVar(&statusExitCode, "exit-code", false, "exit with code 1 when a generated file is not clean")
+}
+
+func runStatus(cmd *cobra.Command, _ []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting status command")
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	generator := app.NewGenerator(cfg, fs.NewOSFileSystem(), log)
+	statuses, err := generator.Status()
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to compute status: %w", err)
+	}
+
+	if len(statuses) == 0 {
+		log.Warnf("No generated files recorded in %s, run tfskel generate or tfskel sync first", app.ManifestFile)
+		return nil
+	}
+
+	if dirty := printStatus(cmd.OutOrStdout(), statuses); dirty > 0 && statusExitCode {
+		cmd.SilenceUsage = true
+		return NewExitError(1, fmt.Sprintf("%d generated files are not clean", dirty))
+	}
+	return nil
+}
+
+// printStatus prints one line per generated file and a summary, returning the number of files that are not clean
+func printStatus(w io.Writer, statuses []app.FileStatus) int {
+	counts := make(map[app.FileState]int)
+
+	_, _ = fmt.Fprintf(w, "Generated files (%s):\n", app.ManifestFile) //nolint:errcheck // best-effort terminal output
+	for _, status := range statuses {
+		counts[status.State]++
+
+		line := fmt.Sprintf("  %-8s %s", status.State, status.Path)
+		if status.Reason != "" {
+			line += fmt.Sprintf(" (%s)", status.Reason)
+		}
+		_, _ = fmt.Fprintln(w, line) //nolint:errcheck // best-effort terminal output
+	}
+
+	_, _ = fmt.Fprintf(w, "Status: %d clean, %d modified, %d outdated, %d missing\n", //nolint:errcheck // best-effort terminal output
+		counts[app.StateClean], counts[app.StateModified], counts[app.StateOutdated], counts[app.StateMissing])
+
+	return len(statuses) - counts[app.StateClean]
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintStatus(t *testing.T) {
+	var out bytes.Buffer
+	dirty := printStatus(&out, []app.FileStatus{
+		{Path: "envs/dev/us-east-1/payments/backend.tf", State: app.StateOutdated, Reason: "template or config changed"},
+		{Path: "envs/dev/us-east-1/payments/versions.tf", State: app.StateClean},
+	})
+
+	assert.Equal(t, 1, dirty)
+	assert.Contains(t, out.String(), "  outdated envs/dev/us-east-1/payments/backend.tf (template or config changed)")
+	assert.Contains(t, out.String(), "  clean    envs/dev/us-east-1/payments/versions.tf")
+	assert.Contains(t, out.String(), "Status: 1 clean, 0 modified, 1 outdated, 0 missing")
+}
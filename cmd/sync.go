@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// ErrNoAppsDeclared indicates sync was run without any apps in the configuration
+	ErrNoAppsDeclared = errors.New("no apps declared in configuration (add them under apps)")
+	// ErrAppWithoutTargets indicates a declared app resolves to no environment or no region
+	ErrAppWithoutTargets = errors.New("app has no environments or regions to sync")
+	// ErrSyncFailed indicates one or more declared app directories could not be reconciled
+	ErrSyncFailed = errors.New("sync failed for one or more apps")
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Reconcile app directories with the apps declared in configuration",
+	Long: `Reconcile the repository layout against the apps declared in .tfskel.yaml.
+
+For every app under apps, sync generates envs/<env>/<region>/<app> for each of the
+app's environments and regions: missing directories and files are created and the
+tfskel managed files (backend.tf, versions.tf) are updated, exactly like generate.
+An app without envs or regions uses every configured environment or region.
+
+App directories found in the layout that are not declared are reported but never
+modified or removed. Directories are placed by layout.app_path, envs/<env>/<region>/<app>
+by default.
+
+Example configuration:
+  apps:
+    - name: payments
+      envs: [dev, prd]
+      regions: [eu-central-1]
+    - name: orders`,
+	Example: `  # Create and update every declared app
+  tfskel sync
+
+  # Preview what sync would create, update or skip
+  tfskel sync --dry-run
+
+  # Keep reconciling the remaining apps when one fails
+  tfskel sync --continue-on-error`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+var (
+	syncDryRun          bool
+	syncContinueOnError bool
+	syncNoHooks         bool
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "print the files that would be created, updated or skipped without writing anything")
+	syncCmd.Flags().BoolVar(&syncContinueOnError, "continue-on-error", false, "keep reconciling the remaining apps when one fails")
+	syncCmd.Flags().BoolVar(&syncNoHooks, "no-hooks", false, "skip the hooks.post_generate commands from the config")
+}
+
+func runSync(cmd *cobra.Command, _ []string) error {
+	log := logger.New(viper.GetBool("verbose"))
+	log.Debug("Starting sync command")
+
+	cfg, err := config.Load(cmd, viper.GetViper())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	targets, err := resolveSyncTargets(cfg)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	log.Infof("Syncing %d declared apps (%d directories)...", len(cfg.Apps), len(targets))
+
+	filesystem, runLog := newRunFileSystem(syncDryRun, log)
+	generator := app.NewGenerator(cfg, filesystem, runLog)
+	if syncNoHooks {
+		generator.SetHookRunner(nil)
+	}
+
+	results := runTargets(cfg, generator, targets, syncContinueOnError, runLog)
+	printGenerateSummary(cfg, results, len(targets), runLog)
+
+	// Undeclared apps are only reported, sync never deletes directories
+	undeclared, err := app.FindUndeclaredApps(filesystem, cfg)
+	if err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed to scan app directories: %w", err)
+	}
+	reportUndeclaredApps(undeclared, log)
+
+	if failed := countFailedTargets(results); failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%w: %d of %d failed", ErrSyncFailed, failed, len(targets))
+	}
+
+	if recorder, ok := filesystem.(fs.Recorder); ok {
+		printDryRunPlan(cmd.OutOrStdout(), recorder.Changes(), ".")
+		return nil
+	}
+
+	log.Success("Sync completed!")
+	return nil
+}
+
+// resolveSyncTargets expands every declared app into its environment x region targets
+func resolveSyncTargets(cfg *config.Config) ([]target, error) {
+	if len(cfg.Apps) == 0 {
+		return nil, ErrNoAppsDeclared
+	}
+
+	var targets []target
+	for _, declared := range cfg.Apps {
+		appEnvs := uniqueValues(cfg.AppEnvironments(declared))
+		appRegions := uniqueValues(cfg.AppRegions(declared))
+		if len(appEnvs) == 0 {
+			return nil, fmt.Errorf("%w: %s declares no envs and %s is empty", ErrAppWithoutTargets, declared.Name, cfg.EnvironmentMappingKey())
+		}
+		if len(appRegions) == 0 {
+			return nil, fmt.Errorf("%w: %s declares no regions and provider.%s.regions is empty", ErrAppWithoutTargets, declared.Name, cfg.Cloud())
+		}
+
+		for _, env := range appEnvs {
+			for _, region := range appRegions {
+				targets = append(targets, target{app: declared.Name, env: env, region: region})
+			}
+		}
+	}
+	return targets, nil
+}
+
+// reportUndeclaredApps warns about app directories on disk that are missing from apps
+func reportUndeclaredApps(undeclared []string, log *logger.Logger) {
+	if len(undeclared) == 0 {
+		return
+	}
+	log.Warnf("Found %d app directories that are not declared in apps:", len(undeclared))
+	for _, path := range undeclared {
+		log.Warnf("  %s", path)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/app"
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSyncTargets(t *testing.T) {
+	provider := &config.Provider{
+		AWS: &config.AWSProvider{
+			AccountMapping: map[string]string{"dev": "111111111111", "prd": "222222222222"},
+			Regions:        []string{"eu-central-1", "us-east-1"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		expected []target
+		wantErr  error
+	}{
+		{
+			name:    "no apps declared",
+			cfg:     &config.Config{Provider: provider},
+			wantErr: ErrNoAppsDeclared,
+		},
+		{
+			name: "declared envs and regions",
+			cfg: &config.Config{
+				Provider: provider,
+				Apps:     []config.App{{Name: "payments", Envs: []string{"prd", "dev"}, Regions: []string{"eu-central-1"}}},
+			},
+			expected: []target{{"payments", "prd", "eu-central-1"}, {"payments", "dev", "eu-central-1"}},
+		},
+		{
+			name: "defaults to every configured env and region",
+			cfg: &config.Config{
+				Provider: provider,
+				Apps:     []config.App{{Name: "orders"}},
+			},
+			expected: []target{
+				{"orders", "dev", "eu-central-1"}, {"orders", "dev", "us-east-1"},
+				{"orders", "prd", "eu-central-1"}, {"orders", "prd", "us-east-1"},
+			},
+		},
+		{
+			name: "no regions to default to",
+			cfg: &config.Config{
+				Provider: &config.Provider{AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "111111111111"}}},
+				Apps:     []config.App{{Name: "orders"}},
+			},
+			wantErr: ErrAppWithoutTargets,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := resolveSyncTargets(tt.cfg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, targets)
+		})
+	}
+}
+
+func TestSync_ReconcilesDeclaredApps(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{
+				Version:        "~> 6.0",
+				AccountMapping: map[string]string{"dev": "111111111111", "prd": "222222222222"},
+			},
+		},
+		Backend: &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Apps:    []config.App{{Name: "payments", Envs: []string{"dev", "prd"}, Regions: []string{"eu-central-1"}}},
+	}
+
+	filesystem := fs.NewMemoryFileSystem()
+	require.NoError(t, filesystem.MkdirAll(filepath.Join("envs", "dev", "eu-central-1", "legacy"), 0755))
+
+	var out bytes.Buffer
+	log := logger.NewWithWriters(false, &out, &out)
+
+	targets, err := resolveSyncTargets(cfg)
+	require.NoError(t, err)
+	results := runTargets(cfg, app.NewGenerator(cfg, filesystem, log), targets, false, log)
+	assert.Zero(t, countFailedTargets(results))
+	assert.True(t, filesystem.FileExists(filepath.Join("envs", "dev", "eu-central-1", "payments", "backend.tf")))
+	assert.True(t, filesystem.FileExists(filepath.Join("envs", "prd", "eu-central-1", "payments", "backend.tf")))
+
+	undeclared, err := app.FindUndeclaredApps(filesystem, cfg)
+	require.NoError(t, err)
+	reportUndeclaredApps(undeclared, log)
+	assert.Contains(t, out.String(), "Found 1 app directories that are not declared in apps")
+	assert.Contains(t, out.String(), filepath.Join("envs", "dev", "eu-central-1", "legacy"))
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+// backendSetting is a single backend.tf setting sourced from config
+// The value may contain Go template syntax, e.g. "{{.Env}}-tfstate"
+type backendSetting struct {
+	key   string
+	value string
+}
+
+// backendSpec describes how a backend type is kept in sync by the generator:
+// which settings are read from config and which of them are tracked in the backend.tf metadata
+// Tracked settings are updated in place in existing files; attributes maps the ones whose
+// attribute differs from the setting key, with nested blocks separated by a dot
+type backendSpec struct {
+	settings     func(cfg *config.Config, data *templates.Data) []backendSetting
+	metadataKeys []string
+	attributes   map[string]string
+}
+
+// backendSpecs registers every supported backend type
+// The matching template lives at backend/<type>.tf.tmpl
+var backendSpecs = map[string]backendSpec{
+	config.BackendS3: {
+		settings: func(_ *config.Config, data *templates.Data) []backendSetting {
+			// bucket_name is rendered into S3BucketName before the backend settings are resolved
+			return []backendSetting{{"bucket", data.S3BucketName}}
+		},
+		metadataKeys: []string{"bucket"},
+	},
+	config.BackendAzureRM: {
+		settings: func(cfg *config.Config, data *templates.Data) []backendSetting {
+			b := cfg.Backend.AzureRM
+			return []backendSetting{
+				{"resource_group_name", b.ResourceGroupName},
+				{"storage_account_name", b.StorageAccountName},
+				{"container_name", b.ContainerName},
+				{"key", valueOrDefault(b.Key, config.DefaultStateKey)},
+				{"subscription_id", cfg.GetSubscriptionID(data.Env)},
+			}
+		},
+		metadataKeys: []string{"resource_group_name", "storage_account_name", "container_name", "key"},
+	},
+	config.BackendGCS: {
+		settings: func(cfg *config.Config, _ *templates.Data) []backendSetting {
+			b := cfg.Backend.GCS
+			return []backendSetting{
+				{"bucket", b.Bucket},
+				{"prefix", valueOrDefault(b.Prefix, config.DefaultStatePrefix)},
+			}
+		},
+		metadataKeys: []string{"bucket", "prefix"},
+	},
+	config.BackendHTTP: {
+		settings: func(cfg *config.Config, _ *templates.Data) []backendSetting {
+			b := cfg.Backend.HTTP
+			return []backendSetting{
+				{"address", b.Address},
+				{"lock_address", b.LockAddress},
+				{"unlock_address", b.UnlockAddress},
+			}
+		},
+		metadataKeys: []string{"address", "lock_address", "unlock_address"},
+	},
+	config.BackendCloud: {
+		settings: func(cfg *config.Config, _ *templates.Data) []backendSetting {
+			b := cfg.Backend.Cloud
+			return []backendSetting{
+				{"organization", b.Organization},
+				{"hostname", b.Hostname},
+				{"project", b.Project},
+				{"workspace", valueOrDefault(b.Workspace, config.DefaultWorkspaceName)},
+			}
+		},
+		metadataKeys: []string{"organization", "hostname", "project", "workspace"},
+		attributes: map[string]string{
+			"project":   "workspaces.project",
+			"workspace": "workspaces.name",
+		},
+	},
+	config.BackendLocal: {
+		settings: func(cfg *config.Config, _ *templates.Data) []backendSetting {
+			return []backendSetting{
+				{"path", valueOrDefault(cfg.Backend.Local.Path, config.DefaultLocalStatePath)},
+			}
+		},
+		metadataKeys: []string{"path"},
+	},
+}
+
+// valueOrDefault returns value, or fallback when value is empty
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// backendMetadata creates the backend.tf metadata for the backend type selected in data
+func backendMetadata(data *templates.Data) map[string]string {
+	spec, ok := backendSpecs[data.BackendType]
+	if !ok || data.BackendType == config.BackendS3 {
+		return buildBackendMetadata(data.S3BucketName)
+	}
+
+	metadata := make(map[string]string, len(spec.metadataKeys))
+	for _, key := range spec.metadataKeys {
+		metadata[key] = data.Backend[key]
+	}
+	return metadata
+}
+
+// applyBackendData resolves the settings of the selected backend type into data.Backend,
+// rendering any Go template syntax against the already prepared data
+func (g *Generator) applyBackendData(cfg *config.Config, data *templates.Data) error {
+	spec, ok := backendSpecs[data.BackendType]
+	if !ok {
+		return fmt.Errorf("%w '%s'", config.ErrUnsupportedBackendType, data.BackendType)
+	}
+
+	data.Backend = make(map[string]string)
+	for _, setting := range spec.settings(cfg, data) {
+		rendered, err := renderValue(setting.key, setting.value, data)
+		if err != nil {
+			return fmt.Errorf("failed to render %s backend %s template: %w", data.BackendType, setting.key, err)
+		}
+		data.Backend[setting.key] = rendered
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+func TestBackendMetadata(t *testing.T) {
+	t.Run("s3", func(t *testing.T) {
+		data := &templates.Data{S3BucketName: "my-bucket"}
+		assert.Equal(t, map[string]string{"bucket": "my-bucket"}, backendMetadata(data))
+	})
+
+	t.Run("azurerm only tracks its metadata keys", func(t *testing.T) {
+		data := &templates.Data{
+			BackendType: config.BackendAzureRM,
+			Backend: map[string]string{
+				"resource_group_name":  "rg",
+				"storage_account_name": "sa",
+				"container_name":       "c",
+				"key":                  "k",
+				"subscription_id":      "11111111-2222-3333-4444-555555555555",
+			},
+		}
+		expected := map[string]string{
+			"resource_group_name":  "rg",
+			"storage_account_name": "sa",
+			"container_name":       "c",
+			"key":                  "k",
+		}
+		assert.Equal(t, expected, backendMetadata(data))
+	})
+}
+
+func TestBackendSpecs_CoverAllBackendTypes(t *testing.T) {
+	renderer, err := templates.NewRenderer()
+	require.NoError(t, err)
+
+	for _, backendType := range config.BackendTypes {
+		t.Run(backendType, func(t *testing.T) {
+			_, ok := backendSpecs[backendType]
+			assert.True(t, ok, "backend type %s has no spec", backendType)
+
+			_, err := renderer.Render("tf/backend.tf.tmpl", &templates.Data{BackendType: backendType})
+			assert.NoError(t, err, "backend type %s has no template", backendType)
+		})
+	}
+}
+
+func TestGenerator_BackendTypes(t *testing.T) {
+	newConfig := func(backend *config.Backend) *config.Config {
+		return &config.Config{
+			TerraformVersion: "~> 1.13",
+			Provider: &config.Provider{
+				AWS: &config.AWSProvider{
+					Version:        "~> 6.0",
+					AccountMapping: map[string]string{"dev": "123456789012"},
+				},
+			},
+			Backend: backend,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		backend  *config.Backend
+		expected []string
+	}{
+		{
+			name: "http backend",
+			backend: &config.Backend{
+				Type: config.BackendHTTP,
+				HTTP: &config.HTTPBackend{Address: "https://state.example.com/{{.Env}}/{{.AppDir}}"},
+			},
+			expected: []string{`address        = "https://state.example.com/dev/myapp"`},
+		},
+		{
+			name: "cloud block with default workspace name",
+			backend: &config.Backend{
+				Type:  config.BackendCloud,
+				Cloud: &config.CloudBackend{Organization: "acme"},
+			},
+			expected: []string{`organization = "acme"`, `name    = "myapp-dev-us-east-1"`},
+		},
+		{
+			name:     "local backend inferred from block",
+			backend:  &config.Backend{Local: &config.LocalBackend{}},
+			expected: []string{`backend "local"`, `path = "terraform.tfstate"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filesystem := fs.NewMemoryFileSystem()
+			gen := NewGenerator(newConfig(tt.backend), filesystem, logger.New(false))
+
+			renderer, err := templates.NewRenderer()
+			require.NoError(t, err)
+			gen.renderer = renderer
+
+			appPath := "envs/dev/us-east-1/myapp"
+			require.NoError(t, gen.generateFiles(appPath, "dev", "us-east-1", "myapp"))
+
+			content, err := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
+			require.NoError(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, string(content), expected)
+			}
+
+			// A second run with unchanged config must not report backend changes
+			data, err := gen.prepareTemplateData("dev", "us-east-1", "myapp")
+			require.NoError(t, err)
+			needsUpdate, changes, err := gen.shouldUpdateBackend(filepath.Join(appPath, "backend.tf"), data)
+			require.NoError(t, err)
+			assert.False(t, needsUpdate, "unexpected changes: %v", changes)
+		})
+	}
+
+	t.Run("updates cloud block when organization changes", func(t *testing.T) {
+		filesystem := fs.NewMemoryFileSystem()
+		renderer, err := templates.NewRenderer()
+		require.NoError(t, err)
+		appPath := "envs/dev/us-east-1/myapp"
+
+		for _, organization := range []string{"acme", "acme-platform"} {
+			gen := NewGenerator(newConfig(&config.Backend{Cloud: &config.CloudBackend{Organization: organization}}), filesystem, logger.New(false))
+			gen.renderer = renderer
+			require.NoError(t, gen.generateFiles(appPath, "dev", "us-east-1", "myapp"))
+		}
+
+		content, err := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `organization = "acme-platform"`)
+	})
+
+	t.Run("unsupported backend type", func(t *testing.T) {
+		gen := NewGenerator(newConfig(&config.Backend{Type: "consul"}), fs.NewMemoryFileSystem(), logger.New(false))
+		_, err := gen.prepareTemplateData("dev", "us-east-1", "myapp")
+		assert.ErrorIs(t, err, config.ErrUnsupportedBackendType)
+	})
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ishuar/tfskel/internal/config"
+)
+
+// checkApp is the app name used to render bucket names and workflow names for targets without a declared app
+const checkApp = "example"
+
+// workflowFileNamePattern matches workflow file names that are safe on every platform and in shell commands
+var workflowFileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// checkTarget is an environment, region and app the config is rendered for
+type checkTarget struct {
+	env, region, app string
+}
+
+// CheckConfig runs config.Check and the checks that need rendered templates: the S3 bucket name
+// and the workflow name_template are rendered for every configured environment and region
+func (g *Generator) CheckConfig() []config.Issue {
+	issues := g.config.Check()
+	targets := g.checkTargets()
+	issues = append(issues, g.checkBucketNames(targets)...)
+	return append(issues, g.checkWorkflowNames(targets)...)
+}
+
+// checkTargets lists every configured environment and region once with a placeholder app,
+// followed by every target of the declared apps
+func (g *Generator) checkTargets() []checkTarget {
+	var targets []checkTarget
+	for _, env := range g.config.Environments() {
+		for _, region := range g.config.GetRegions() {
+			targets = append(targets, checkTarget{env, region, checkApp})
+		}
+	}
+	for _, app := range g.config.Apps {
+		for _, env := range g.config.AppEnvironments(app) {
+			for _, region := range g.config.AppRegions(app) {
+				targets = append(targets, checkTarget{env, region, app.Name})
+			}
+		}
+	}
+	return targets
+}
+
+// checkBucketNames checks the rendered S3 bucket name of every target using the s3 backend
+// Each config key is reported once, for the first target its bucket name is invalid for
+func (g *Generator) checkBucketNames(targets []checkTarget) []config.Issue {
+	var issues []config.Issue
+	reported := make(map[string]bool)
+	for _, target := range targets {
+		path := g.bucketNameKey(target.env, target.app)
+		if reported[path] || g.config.ForTarget(target.env, target.app).BackendType() != config.BackendS3 {
+			continue
+		}
+		data, err := g.prepareTemplateData(target.env, target.region, target.app)
+		if err == nil {
+			err = config.ValidateS3BucketName(data.S3BucketName)
+		}
+		if err != nil {
+			reported[path] = true
+			issues = append(issues, config.Issue{Path: path, Message: fmt.Sprintf("%v (rendered for %s/%s/%s)", err, target.env, target.region, target.app)})
+		}
+	}
+	return issues
+}
+
+// bucketNameKey returns the config key the bucket name of a target comes from
+func (g *Generator) bucketNameKey(env, appName string) string {
+	for i, app := range g.config.Apps {
+		if app.Name == appName && app.Backend != nil && app.Backend.S3 != nil && app.Backend.S3.BucketName != "" {
+			return fmt.Sprintf("apps[%d].backend.s3.bucket_name", i)
+		}
+	}
+	if environment, ok := g.config.Envs[env]; ok && environment.Backend != nil && environment.Backend.S3 != nil && environment.Backend.S3.BucketName != "" {
+		return "environments." + env + ".backend.s3.bucket_name"
+	}
+	return "backend.s3.bucket_name"
+}
+
+// checkWorkflowNames checks that name_template renders a safe workflow file name for every target
+func (g *Generator) checkWorkflowNames(targets []checkTarget) []config.Issue {
+	workflows := g.config.Generate
+	if workflows == nil || workflows.GithubWorkflows == nil || workflows.GithubWorkflows.NameTemplate == "" {
+		return nil
+	}
+
+	const path = "generate.github_workflows.name_template"
+	for _, target := range targets {
+		data, err := g.prepareTemplateData(target.env, target.region, target.app)
+		if err != nil {
+			continue // the target cannot be rendered at all, generate reports why
+		}
+		fileName, err := g.customWorkflowFileName(workflows.GithubWorkflows.NameTemplate, "terraform", data)
+		switch {
+		case err != nil:
+			return []config.Issue{{Path: path, Message: fmt.Sprintf("%v (rendered for %s/%s/%s)", err, target.env, target.region, target.app)}}
+		case !workflowFileNamePattern.MatchString(fileName):
+			return []config.Issue{{Path: path, Message: fmt.Sprintf("renders '%s' for %s/%s/%s, use letters, digits, dots, hyphens and underscores only", fileName, target.env, target.region, target.app)}}
+		}
+	}
+	return nil
+}
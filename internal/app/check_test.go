@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_CheckConfig(t *testing.T) {
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Version:          config.CurrentVersion,
+			TerraformVersion: "~> 1.13",
+			Provider: &config.Provider{AWS: &config.AWSProvider{
+				Version:        "~> 6.0",
+				AccountMapping: map[string]string{"dev": "123456789012", "prd": "210987654321"},
+				Regions:        []string{"eu-central-1", "us-east-1"},
+			}},
+			Backend: &config.Backend{S3: &config.S3Backend{BucketName: "acme-tfstate-{{.Env}}-{{.ShortRegion}}"}},
+		}
+	}
+
+	t.Run("valid config", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Generate = &config.Generate{GithubWorkflows: &config.GithubWorkflows{NameTemplate: "{{.AppDir}}-{{.Env}}"}}
+		assert.Empty(t, NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false)).CheckConfig())
+	})
+
+	t.Run("bucket name is checked after rendering", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Envs = map[string]config.Environment{
+			"prd": {Overrides: config.Overrides{Backend: &config.Backend{S3: &config.S3Backend{BucketName: "Acme_{{.Env}}"}}}},
+		}
+		issues := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false)).CheckConfig()
+		require.Len(t, issues, 1)
+		assert.Equal(t, "environments.prd.backend.s3.bucket_name", issues[0].Path)
+		assert.Contains(t, issues[0].Message, "'Acme_prd'")
+		assert.Contains(t, issues[0].Message, "prd/eu-central-1/example")
+	})
+
+	t.Run("bucket name of a declared app", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Backend.S3.BucketName = "{{.AppDir}}"
+		cfg.Apps = []config.App{{Name: "x"}}
+		issues := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false)).CheckConfig()
+		require.Len(t, issues, 1)
+		assert.Equal(t, "backend.s3.bucket_name", issues[0].Path)
+		assert.Contains(t, issues[0].Message, "'x' must be between 3 and 63 characters")
+	})
+
+	t.Run("unsafe name_template", func(t *testing.T) {
+		for _, nameTemplate := range []string{"../{{.AppDir}}", "{{.AppDir}} {{.Env}}", "{{.Missing"} {
+			cfg := newConfig()
+			cfg.Generate = &config.Generate{GithubWorkflows: &config.GithubWorkflows{NameTemplate: nameTemplate}}
+			issues := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false)).CheckConfig()
+			require.Len(t, issues, 1, nameTemplate)
+			assert.Equal(t, "generate.github_workflows.name_template", issues[0].Path)
+		}
+	})
+}
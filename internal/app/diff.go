@@ -0,0 +1,107 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// errUpdateDeclined indicates the user declined an update to a managed file
+var errUpdateDeclined = errors.New("update declined")
+
+// ConfirmFunc asks whether the update of the file at path should be applied
+type ConfirmFunc func(path string) (bool, error)
+
+// DiffOptions controls how updates to managed files are previewed before they are written
+type DiffOptions struct {
+	// Out receives the unified diff of every managed file that is about to change
+	Out io.Writer
+	// UseColor enables colored diff output
+	UseColor bool
+	// Confirm is asked before each update when set; a false answer keeps the existing file
+	Confirm ConfirmFunc
+}
+
+// SetDiffOptions enables diff previews for updates of managed files
+func (g *Generator) SetDiffOptions(opts *DiffOptions) {
+	g.diff = opts
+}
+
+// unifiedDiff returns a unified diff between the current and the updated content of path
+func unifiedDiff(path, current, updated string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(current),
+		B:        difflib.SplitLines(updated),
+		FromFile: "a/" + path,
+		ToFile:   "b/" + path,
+		Context:  3,
+	})
+}
+
+// ColorizeDiff colors added, removed and hunk header lines of a unified diff
+func ColorizeDiff(diff string, useColor bool) string {
+	if !useColor {
+		return diff
+	}
+
+	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	removedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	hunkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = headerStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = hunkStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = addedStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = removedStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// reviewUpdate shows the diff of a managed file update and asks for confirmation when configured
+// It returns errUpdateDeclined when the user chose to keep the existing file
+func (g *Generator) reviewUpdate(path string, updated string) error {
+	if g.diff == nil {
+		return nil
+	}
+
+	current, err := g.fs.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	diff, err := unifiedDiff(path, string(current), updated)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s: %w", path, err)
+	}
+	if diff == "" {
+		return nil
+	}
+
+	if g.diff.Out != nil {
+		_, _ = fmt.Fprint(g.diff.Out, ColorizeDiff(diff, g.diff.UseColor)) //nolint:errcheck // best-effort terminal output
+	}
+
+	if g.diff.Confirm == nil {
+		return nil
+	}
+	apply, err := g.diff.Confirm(path)
+	if err != nil {
+		return fmt.Errorf("failed to confirm update of %s: %w", path, err)
+	}
+	if !apply {
+		return errUpdateDeclined
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	diff, err := unifiedDiff("backend.tf", "a\nbucket = \"old\"\nc\n", "a\nbucket = \"new\"\nc\n")
+	require.NoError(t, err)
+
+	assert.Contains(t, diff, "--- a/backend.tf")
+	assert.Contains(t, diff, "+++ b/backend.tf")
+	assert.Contains(t, diff, "-bucket = \"old\"")
+	assert.Contains(t, diff, "+bucket = \"new\"")
+
+	unchanged, err := unifiedDiff("backend.tf", "same\n", "same\n")
+	require.NoError(t, err)
+	assert.Empty(t, unchanged)
+}
+
+func TestColorizeDiff(t *testing.T) {
+	diff := "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-old\n+new\n"
+	assert.Equal(t, diff, ColorizeDiff(diff, false))
+	assert.Contains(t, ColorizeDiff(diff, true), "+new")
+}
+
+func TestGenerator_DiffAndConfirm(t *testing.T) {
+	newConfig := func(bucketName string) *config.Config {
+		return &config.Config{
+			TerraformVersion: "~> 1.13",
+			Provider: &config.Provider{
+				AWS: &config.AWSProvider{
+					Version:        "~> 6.0",
+					AccountMapping: map[string]string{"dev": "123456789012"},
+				},
+			},
+			Backend: &config.Backend{S3: &config.S3Backend{BucketName: bucketName}},
+		}
+	}
+	backendPath := filepath.Join("envs", "dev", "us-east-1", "myapp", "backend.tf")
+
+	setup := func(t *testing.T) *fs.MemoryFileSystem {
+		t.Helper()
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(newConfig("old-bucket"), filesystem, logger.New(false)).Run("dev", "us-east-1", "myapp"))
+		return filesystem
+	}
+
+	tests := []struct {
+		name           string
+		confirm        ConfirmFunc
+		expectedBucket string
+		wantErr        bool
+	}{
+		{
+			name:           "diff only applies the update",
+			expectedBucket: "new-bucket",
+		},
+		{
+			name:           "confirmed update is applied",
+			confirm:        func(string) (bool, error) { return true, nil },
+			expectedBucket: "new-bucket",
+		},
+		{
+			name:           "declined update keeps the existing file",
+			confirm:        func(string) (bool, error) { return false, nil },
+			expectedBucket: "old-bucket",
+		},
+		{
+			name:    "confirmation error aborts generation",
+			confirm: func(string) (bool, error) { return false, errors.New("stdin closed") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filesystem := setup(t)
+			var out bytes.Buffer
+			var asked []string
+
+			gen := NewGenerator(newConfig("new-bucket"), filesystem, logger.New(false))
+			opts := &DiffOptions{Out: &out}
+			if tt.confirm != nil {
+				opts.Confirm = func(path string) (bool, error) {
+					asked = append(asked, path)
+					return tt.confirm(path)
+				}
+			}
+			gen.SetDiffOptions(opts)
+
+			err := gen.Run("dev", "us-east-1", "myapp")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Contains(t, out.String(), `-## tfskel-metadata: {"bucket": "old-bucket"}`)
+			assert.Contains(t, out.String(), `+## tfskel-metadata: {"bucket": "new-bucket"}`)
+			assert.NotContains(t, out.String(), "versions.tf", "unchanged files must not be diffed")
+			if tt.confirm != nil {
+				assert.Equal(t, []string{backendPath}, asked)
+			}
+
+			content, err := filesystem.ReadFile(backendPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), `bucket              = "`+tt.expectedBucket+`"`)
+		})
+	}
+}
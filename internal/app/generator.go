@@ -14,15 +14,17 @@ import (
 	"github.com/ishuar/tfskel/internal/fs"
 	"github.com/ishuar/tfskel/internal/logger"
 	"github.com/ishuar/tfskel/internal/templates"
-	"github.com/ishuar/tfskel/internal/util"
 )
 
 var (
 	// ErrMetadataKeyNotFound indicates the requested metadata key was not found in template metadata
 	ErrMetadataKeyNotFound = errors.New("metadata key not found")
 
-	// Template category constants
-	categoryGithub = "github"
+	// errUnsafeWorkflowFileName indicates name_template rendered a workflow file name that is not a plain file name
+	errUnsafeWorkflowFileName = errors.New("name_template renders an unsafe file name")
+
+	// trailingCommaPattern matches a trailing comma before the closing brace of metadata JSON
+	trailingCommaPattern = regexp.MustCompile(`,\s*}$`)
 )
 
 // extractMetadata extracts JSON metadata from a comment line in format: ## tfskel-metadata: {...}
@@ -36,8 +38,11 @@ func extractMetadata(content, metadataKey string) (map[string]string, error) {
 		return nil, fmt.Errorf("%w: %s", ErrMetadataKeyNotFound, metadataKey)
 	}
 
+	// Tolerate the trailing comma the tags comment is rendered with, e.g. {"team": "a", }
+	raw := trailingCommaPattern.ReplaceAllString(matches[1], "}")
+
 	var metadata map[string]string
-	if err := json.Unmarshal([]byte(matches[1]), &metadata); err != nil {
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
 		return nil, fmt.Errorf("failed to parse metadata JSON: %w", err)
 	}
 
@@ -59,6 +64,34 @@ func buildVersionsMetadata(tfVersion, awsProviderVersion string) map[string]stri
 	}
 }
 
+// buildAzureRMVersionsMetadata creates metadata map for an azurerm versions.tf
+func buildAzureRMVersionsMetadata(tfVersion, azurermProviderVersion string) map[string]string {
+	return map[string]string{
+		"tf_ver":               tfVersion,
+		"azurerm_provider_ver": azurermProviderVersion,
+	}
+}
+
+// buildGoogleVersionsMetadata creates metadata map for a google versions.tf
+func buildGoogleVersionsMetadata(tfVersion, googleProviderVersion string) map[string]string {
+	return map[string]string{
+		"tf_ver":              tfVersion,
+		"google_provider_ver": googleProviderVersion,
+	}
+}
+
+// versionsMetadata creates the versions.tf metadata for the cloud selected in data
+func versionsMetadata(data *templates.Data) map[string]string {
+	switch data.Cloud {
+	case config.CloudAzureRM:
+		return buildAzureRMVersionsMetadata(data.TerraformVersion, data.AzureRMProviderVersion)
+	case config.CloudGoogle:
+		return buildGoogleVersionsMetadata(data.TerraformVersion, data.GoogleProviderVersion)
+	default:
+		return buildVersionsMetadata(data.TerraformVersion, data.AWSProviderVersion)
+	}
+}
+
 // compareMetadata returns true if metadata maps differ, along with list of changes
 func compareMetadata(fileMetadata, configMetadata map[string]string) (bool, []string) {
 	var changes []string
@@ -93,6 +126,10 @@ type Generator struct {
 	fs       fs.FileSystem
 	log      *logger.Logger
 	renderer *templates.Renderer
+	diff     *DiffOptions
+	hooks    *HookRunner
+	manifest *Manifest // Generated files, loaded on the first run
+	tracked  []string  // Manifest keys written since the manifest was last saved
 }
 
 // NewGenerator creates a new Generator instance
@@ -101,31 +138,41 @@ func NewGenerator(cfg *config.Config, filesystem fs.FileSystem, log *logger.Logg
 		config: cfg,
 		fs:     filesystem,
 		log:    log,
+		hooks:  NewHookRunner(ExecCommand, log),
 	}
 }
 
+// SetHookRunner replaces the runner used for post_generate hooks; nil disables hooks
+func (g *Generator) SetHookRunner(runner *HookRunner) {
+	g.hooks = runner
+}
+
 // Run executes the generation process with the provided generation parameters
+// The template renderer is loaded on the first run and reused by later runs of the same generator,
+// so templates_dir and extra_template_extensions are read from the root configuration
 func (g *Generator) Run(env, region, appDir string) error {
-	// Initialize template renderer with custom templates if provided
-	var renderer *templates.Renderer
-	var err error
-	if g.config.TemplatesDir != "" {
-		g.log.Infof("Using custom templates from: %s", g.config.TemplatesDir)
-		renderer, err = templates.NewRendererWithCustomTemplates(
-			g.config.TemplatesDir,
-			g.config.ExtraTemplateExtensions,
-		)
-	} else {
-		g.log.Debug("Using default embedded templates")
-		renderer, err = templates.NewRenderer()
+	if err := g.loadRenderer(); err != nil {
+		return err
 	}
+
+	// Create the app directory of layout.app_path, envs/<env>/<region>/<app> by default
+	appPath := g.config.AppPath(env, region, appDir)
+
+	// Generate with the config files of the env and app directories merged in
+	root := g.config
+	cfg, err := root.ForDir(appPath)
 	if err != nil {
-		return fmt.Errorf("failed to initialize template renderer: %w", err)
+		return err
 	}
-	g.renderer = renderer
+	g.config = cfg
+	defer func() { g.config = root }()
 
-	// Create directory structure: envs/<env>/<region>/<app>
-	appPath := filepath.Join("envs", env, region, appDir)
+	if err := g.loadManifest(); err != nil {
+		return err
+	}
+	if err := g.checkRegionCodes(region); err != nil {
+		return err
+	}
 
 	// Check if directory already exists
 	dirExists := g.fs.DirExists(appPath)
@@ -137,6 +184,7 @@ func (g *Generator) Run(env, region, appDir string) error {
 	// Show appropriate message based on whether directory was created or already existed
 	if dirExists {
 		g.log.Infof("Directory %s already exists", appPath)
+		g.record(fs.Change{Action: fs.ActionSkip, Path: appPath, IsDir: true, Reason: "already exists"})
 	} else {
 		g.log.Successf("Created directory structure: %s", appPath)
 	}
@@ -146,6 +194,11 @@ func (g *Generator) Run(env, region, appDir string) error {
 		return err
 	}
 
+	// Record the generated files in .tfskel.lock
+	if err := g.saveManifest(); err != nil {
+		return err
+	}
+
 	// Display success message
 	absPath, err := filepath.Abs(appPath)
 	if err != nil {
@@ -156,22 +209,39 @@ func (g *Generator) Run(env, region, appDir string) error {
 	return nil
 }
 
-// findProjectRoot returns the project root directory (containing envs folder) from an app path
-// appPath is in format: envs/<env>/<region>/<app>
-func findProjectRoot(appPath string) string { //nolint:unparam // keeping for clarity and future use
-	// Navigate up from appPath to find project root
-	// Example: envs/dev/us-east-1/myapp -> current directory (project root)
-	parts := strings.Split(filepath.ToSlash(appPath), "/")
-	if len(parts) > 0 && parts[0] == "envs" {
-		// Return current directory (".") which is the project root
-		return "."
+// loadRenderer initializes the template renderer with custom templates if provided
+func (g *Generator) loadRenderer() error {
+	if g.renderer != nil {
+		return nil
 	}
-	// Fallback to current directory
-	return "."
+
+	var renderer *templates.Renderer
+	var err error
+	if g.config.TemplatesDir != "" {
+		g.log.Infof("Using custom templates from: %s", g.config.TemplatesDir)
+		renderer, err = templates.NewRendererWithCustomTemplates(
+			g.config.TemplatesDir,
+			g.config.ExtraTemplateExtensions,
+		)
+	} else {
+		g.log.Debug("Using default embedded templates")
+		renderer, err = templates.NewRenderer()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to initialize template renderer: %w", err)
+	}
+	g.renderer = renderer
+	return nil
 }
 
+// projectRoot is the project root directory (containing the envs folder)
+// Commands run in the project root, the command line changes into it before they start
+const projectRoot = "."
+
 // determineOutputPath converts template path to output location based on category
 // Template paths are like: root/.gitignore.tmpl, tf/backend.tf.tmpl, github/workflow.yaml.tmpl
+// Subdirectories below root/ and tf/ are kept, user-defined categories are placed in the
+// directory mapped in template_categories
 func (g *Generator) determineOutputPath(tmplPath, appPath string, data *templates.Data) (string, bool) {
 	// Normalize path separators
 	tmplPath = filepath.ToSlash(tmplPath)
@@ -184,18 +254,17 @@ func (g *Generator) determineOutputPath(tmplPath, appPath string, data *template
 
 	category := parts[0]
 	fileName := strings.TrimSuffix(parts[len(parts)-1], ".tmpl")
+	relPath := strings.TrimSuffix(filepath.Join(parts[1:]...), ".tmpl")
 
 	switch category {
-	case "root":
+	case templates.CategoryRoot:
 		// Place at project root
-		projectRoot := findProjectRoot(appPath)
-		return filepath.Join(projectRoot, fileName), true
-	case "tf":
+		return filepath.Join(projectRoot, relPath), true
+	case templates.CategoryTF:
 		// Place in app directory
-		return filepath.Join(appPath, fileName), true
-	case categoryGithub:
+		return filepath.Join(appPath, relPath), true
+	case templates.CategoryGithub:
 		// Place in .github/workflows/ directory at project root with dynamic naming
-		projectRoot := findProjectRoot(appPath)
 
 		// Check if this is a reusable workflow (no .tmpl extension in original, just .yaml)
 		if strings.HasPrefix(fileName, "reusable-") {
@@ -207,9 +276,31 @@ func (g *Generator) determineOutputPath(tmplPath, appPath string, data *template
 		dynamicFileName := g.generateWorkflowFileName(fileName, data)
 		return filepath.Join(projectRoot, ".github", "workflows", dynamicFileName), true
 	default:
+		return g.customCategoryOutputPath(category, relPath, appPath, data)
+	}
+}
+
+// customCategoryOutputPath places a template of a user-defined category in the directory
+// mapped in template_categories; the mapping may use template data like {{.AppDir}}
+func (g *Generator) customCategoryOutputPath(category, relPath, appPath string, data *templates.Data) (string, bool) {
+	outputDir, ok := g.config.TemplateCategories[category]
+	if !ok {
 		// Unknown category
 		return "", false
 	}
+
+	outputDir, err := renderValue("template_categories."+category, outputDir, data)
+	if err != nil {
+		g.log.Warnf("Failed to render output of template category %s: %v", category, err)
+		return "", false
+	}
+
+	outputPath := filepath.Join(outputDir, relPath)
+	if !filepath.IsLocal(outputPath) {
+		g.log.Warnf("Output %s of template category %s is outside the project", outputPath, category)
+		return "", false
+	}
+	return filepath.Join(projectRoot, outputPath), true
 }
 
 // sanitizeWorkflowFileName validates and sanitizes a workflow filename to prevent path traversal
@@ -248,11 +339,25 @@ func (g *Generator) generateWorkflowFileName(originalFileName string, data *temp
 	nameTemplate := g.config.Generate.GithubWorkflows.NameTemplate
 	workflowType := strings.TrimSuffix(originalFileName, ".yaml")
 
+	fileName, err := g.customWorkflowFileName(nameTemplate, workflowType, data)
+	switch {
+	case errors.Is(err, errUnsafeWorkflowFileName):
+		g.log.Warnf("name_template produced invalid filename (potential path traversal), using default naming")
+		return g.generateDefaultWorkflowFileName(originalFileName, data)
+	case err != nil:
+		g.log.Warnf("Failed to render name_template, using default naming: %v", err)
+		return g.generateDefaultWorkflowFileName(originalFileName, data)
+	}
+	return fileName
+}
+
+// customWorkflowFileName renders name_template into the file name of a workflow type
+// It returns errUnsafeWorkflowFileName when the rendered name is not a plain file name
+func (g *Generator) customWorkflowFileName(nameTemplate, workflowType string, data *templates.Data) (string, error) {
 	// Parse and execute the custom template
 	rendered, err := g.renderCustomWorkflowName(nameTemplate, data)
 	if err != nil {
-		g.log.Warnf("Failed to render name_template, using default naming: %v", err)
-		return g.generateDefaultWorkflowFileName(originalFileName, data)
+		return "", err
 	}
 
 	// Normalize: strip trailing .yaml if user included it
@@ -267,11 +372,9 @@ func (g *Generator) generateWorkflowFileName(originalFileName string, data *temp
 	// Validate and sanitize the filename to prevent path traversal
 	sanitized, valid := sanitizeWorkflowFileName(baseFileName)
 	if !valid {
-		g.log.Warnf("name_template produced invalid filename (potential path traversal), using default naming")
-		return g.generateDefaultWorkflowFileName(originalFileName, data)
+		return "", fmt.Errorf("%w: %q", errUnsafeWorkflowFileName, baseFileName)
 	}
-
-	return sanitized
+	return sanitized, nil
 }
 
 // renderCustomWorkflowName renders a custom workflow name template
@@ -281,11 +384,12 @@ func (g *Generator) renderCustomWorkflowName(nameTemplate string, data *template
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	dataMap := map[string]string{
+	dataMap := map[string]any{
 		"AppDir":      data.AppDir,
 		"Env":         data.Env,
 		"Region":      data.Region,
 		"ShortRegion": data.ShortRegion,
+		"Vars":        data.Vars,
 	}
 
 	var buf bytes.Buffer
@@ -330,46 +434,83 @@ func (g *Generator) generateFiles(appPath, env, region, appDir string) error {
 	}
 
 	// Process all templates
-	return g.processTemplates(appPath, data)
+	if err := g.processTemplates(appPath, data); err != nil {
+		return err
+	}
+
+	return g.runPostGenerateHooks(appPath, data)
+}
+
+// runPostGenerateHooks runs hooks.post_generate in the app directory
+// Hooks are skipped when disabled and when generating against a recording (dry-run) filesystem
+func (g *Generator) runPostGenerateHooks(appPath string, data *templates.Data) error {
+	hooks := g.config.PostGenerateHooks()
+	if len(hooks) == 0 || g.hooks == nil {
+		return nil
+	}
+	if _, ok := g.fs.(fs.Recorder); ok {
+		g.log.Infof("Skipping %d post_generate hooks in dry-run mode", len(hooks))
+		return nil
+	}
+	return g.hooks.Run("post_generate", hooks, appPath, data)
 }
 
 // prepareTemplateData extracts config values and builds template data
+// Settings are resolved for the target, so the config files of its directories
+// and the environments.<env> and apps[] overrides apply
 func (g *Generator) prepareTemplateData(env, region, appDir string) (*templates.Data, error) {
-	shortRegion := util.TransformRegionName(region)
+	appPath := g.config.AppPath(env, region, appDir)
+	dirConfig, err := g.config.ForDir(appPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := dirConfig.ForTarget(env, appDir)
+	shortRegion := cfg.ShortRegion(region)
 
 	// Extract nested config values with nil checks
 	awsProviderVersion := "~> 6.0"
 	defaultTags := make(map[string]string)
 	s3BucketName := "CHANGE_ME_WITH_YOUR_GLOBALLY_UNIQUE_S3_BUCKET_NAME"
 
-	if g.config.Provider != nil && g.config.Provider.AWS != nil {
-		if g.config.Provider.AWS.Version != "" {
-			awsProviderVersion = g.config.Provider.AWS.Version
+	if cfg.Provider != nil && cfg.Provider.AWS != nil {
+		if cfg.Provider.AWS.Version != "" {
+			awsProviderVersion = cfg.Provider.AWS.Version
 		}
-		if g.config.Provider.AWS.DefaultTags != nil {
-			defaultTags = g.config.Provider.AWS.DefaultTags
+		if cfg.Provider.AWS.DefaultTags != nil {
+			defaultTags = cfg.Provider.AWS.DefaultTags
 		}
 	}
 
-	if g.config.Backend != nil && g.config.Backend.S3 != nil && g.config.Backend.S3.BucketName != "" {
-		s3BucketName = g.config.Backend.S3.BucketName
+	if cfg.Backend != nil && cfg.Backend.S3 != nil && cfg.Backend.S3.BucketName != "" {
+		s3BucketName = cfg.Backend.S3.BucketName
 	}
 
 	// Build AWS role ARN for terraform workflows
-	awsRoleArn := g.buildAWSRoleArn(env)
+	awsRoleArn := buildAWSRoleArn(cfg, env)
 
 	// Create initial data for template rendering
 	data := &templates.Data{
 		Env:                env,
 		Region:             region,
 		AppDir:             appDir,
-		AccountID:          g.config.GetAccountID(env),
+		AppPath:            filepath.ToSlash(appPath),
+		AccountID:          cfg.GetAccountID(env),
 		ShortRegion:        shortRegion,
 		S3BucketName:       s3BucketName,
-		TerraformVersion:   g.config.TerraformVersion,
+		TerraformVersion:   cfg.TerraformVersion,
 		AWSProviderVersion: awsProviderVersion,
 		DefaultTags:        defaultTags,
 		AWSRoleArn:         awsRoleArn,
+		Cloud:              cfg.Cloud(),
+		BackendType:        cfg.BackendType(),
+		Vars:               cfg.ResolveVars(env, appDir),
+	}
+
+	switch data.Cloud {
+	case config.CloudAzureRM:
+		applyAzureRMProviderData(cfg, data)
+	case config.CloudGoogle:
+		applyGoogleProviderData(cfg, data)
 	}
 
 	// Render bucket_name as a template if it contains Go template syntax
@@ -381,18 +522,44 @@ func (g *Generator) prepareTemplateData(env, region, appDir string) (*templates.
 		data.S3BucketName = renderedBucketName
 	}
 
+	if err := g.applyBackendData(cfg, data); err != nil {
+		return nil, err
+	}
+
 	return data, nil
 }
 
+// applyAzureRMProviderData fills the azurerm provider fields of data from config
+func applyAzureRMProviderData(cfg *config.Config, data *templates.Data) {
+	azurerm := cfg.Provider.AzureRM
+	data.AzureRMProviderVersion = azurerm.Version
+	data.SubscriptionID = cfg.GetSubscriptionID(data.Env)
+	data.AzureRMFeatures = azurerm.Features
+	if azurerm.DefaultTags != nil {
+		data.DefaultTags = azurerm.DefaultTags
+	}
+}
+
+// applyGoogleProviderData fills the google provider fields of data from config
+func applyGoogleProviderData(cfg *config.Config, data *templates.Data) {
+	google := cfg.Provider.Google
+	data.GoogleProviderVersion = google.Version
+	data.ProjectID = cfg.GetProjectID(data.Env)
+	// default_labels take the place of default_tags in versions.tf and its tfskel-tags metadata
+	if google.DefaultLabels != nil {
+		data.DefaultTags = google.DefaultLabels
+	}
+}
+
 // buildAWSRoleArn constructs AWS role ARN from config or returns explicit ARN
 // Priority: aws_role_arn > aws_role_name > default placeholder
-func (g *Generator) buildAWSRoleArn(env string) string {
-	if g.config.Generate == nil || g.config.Generate.GithubWorkflows == nil {
+func buildAWSRoleArn(cfg *config.Config, env string) string {
+	if cfg.Generate == nil || cfg.Generate.GithubWorkflows == nil {
 		// Return default placeholder
-		return fmt.Sprintf("arn:aws:iam::%s:role/REPLACE_WITH_ROLE_TO_ASSUME", g.config.GetAccountID(env))
+		return fmt.Sprintf("arn:aws:iam::%s:role/REPLACE_WITH_ROLE_TO_ASSUME", cfg.GetAccountID(env))
 	}
 
-	workflows := g.config.Generate.GithubWorkflows
+	workflows := cfg.Generate.GithubWorkflows
 
 	// If explicit ARN is provided, use it
 	if workflows.AWSRoleArn != "" {
@@ -401,30 +568,38 @@ func (g *Generator) buildAWSRoleArn(env string) string {
 
 	// If role name is provided, construct ARN
 	if workflows.AWSRoleName != "" {
-		return fmt.Sprintf("arn:aws:iam::%s:role/%s", g.config.GetAccountID(env), workflows.AWSRoleName)
+		return fmt.Sprintf("arn:aws:iam::%s:role/%s", cfg.GetAccountID(env), workflows.AWSRoleName)
 	}
 
 	// Return default placeholder
-	return fmt.Sprintf("arn:aws:iam::%s:role/REPLACE_WITH_ROLE_TO_ASSUME", g.config.GetAccountID(env))
+	return fmt.Sprintf("arn:aws:iam::%s:role/REPLACE_WITH_ROLE_TO_ASSUME", cfg.GetAccountID(env))
 }
 
-// updateBackendIfNeeded checks and updates backend.tf if bucket_name changed
+// updateBackendIfNeeded checks and updates backend.tf if the backend configuration changed
 func (g *Generator) updateBackendIfNeeded(appPath string, data *templates.Data) error {
 	backendPath := filepath.Join(appPath, "backend.tf")
 	if !g.fs.FileExists(backendPath) {
 		return nil
 	}
 
-	needsUpdate, err := g.shouldUpdateBackend(backendPath, data.S3BucketName)
+	needsUpdate, changes, err := g.shouldUpdateBackend(backendPath, data)
 	if err != nil {
 		return fmt.Errorf("failed to check backend.tf for updates: %w", err)
 	}
 
 	if needsUpdate {
 		if err := g.updateBackendFile(backendPath, data); err != nil {
+			if errors.Is(err, errUpdateDeclined) {
+				g.log.Warn("Kept existing backend.tf - update declined")
+				g.record(fs.Change{Action: fs.ActionSkip, Path: backendPath, Reason: "update declined"})
+				return nil
+			}
 			return fmt.Errorf("failed to update backend.tf: %w", err)
 		}
-		g.log.Successf("Updated backend.tf with new bucket_name: %s", data.S3BucketName)
+		for _, change := range changes {
+			g.log.Successf("Updated backend.tf - %s", change)
+		}
+		g.explain(backendPath, strings.Join(changes, "; "))
 	}
 
 	return nil
@@ -444,11 +619,17 @@ func (g *Generator) updateVersionsIfNeeded(appPath string, data *templates.Data)
 
 	if needsUpdate {
 		if err := g.updateVersionsFile(versionsPath, data); err != nil {
+			if errors.Is(err, errUpdateDeclined) {
+				g.log.Warn("Kept existing versions.tf - update declined")
+				g.record(fs.Change{Action: fs.ActionSkip, Path: versionsPath, Reason: "update declined"})
+				return nil
+			}
 			return fmt.Errorf("failed to update versions.tf: %w", err)
 		}
 		for _, change := range changes {
 			g.log.Successf("Updated versions.tf - %s", change)
 		}
+		g.explain(versionsPath, strings.Join(changes, "; "))
 	}
 
 	return nil
@@ -468,30 +649,93 @@ func (g *Generator) processTemplates(appPath string, data *templates.Data) error
 }
 
 // processTemplate handles generation of a single template file
+// The template front-matter decides the category, output path, condition and write mode
 func (g *Generator) processTemplate(tmplPath, appPath string, data *templates.Data) error {
-	// Normalize path and check if it's a root template
-	normalizedPath := filepath.ToSlash(tmplPath)
-	parts := strings.Split(normalizedPath, "/")
+	frontMatter := g.renderer.GetFrontMatter(tmplPath)
+	parts := templateLayout(tmplPath, frontMatter)
+	layoutPath := strings.Join(parts, "/")
 
-	// Skip root templates - they are only handled by init command
-	if len(parts) > 0 && parts[0] == "root" {
+	// Skip default root templates - they are only handled by init command
+	// Root templates from templates_dir are generated at the project root
+	if len(parts) > 0 && parts[0] == templates.CategoryRoot && g.isEmbeddedTemplate(tmplPath) {
 		g.log.Debugf("Skipping root template (init only): %s", tmplPath)
 		return nil
 	}
 
 	// Skip github templates if create_github_workflows is not enabled
-	if len(parts) > 0 && parts[0] == categoryGithub {
+	if len(parts) > 0 && parts[0] == templates.CategoryGithub {
 		if g.config.Generate == nil || g.config.Generate.GithubWorkflows == nil || !g.config.Generate.GithubWorkflows.Create {
 			g.log.Debugf("Skipping github template (create-github-workflows not enabled): %s", tmplPath)
+			g.record(fs.Change{Action: fs.ActionSkip, Path: g.skipPath(tmplPath, appPath, data), Reason: "github category disabled, enable with --create-github-workflows"})
 			return nil
 		}
 	}
 
-	// Create a copy of data for this template to avoid modifying shared data
+	// Skip templates whose when condition does not hold for this target
+	render, err := templates.EvaluateCondition(frontMatter.When, data)
+	if err != nil {
+		return fmt.Errorf("template %s: %w", tmplPath, err)
+	}
+	if !render {
+		g.log.Debugf("Skipping %s: when condition %q is false", tmplPath, frontMatter.When)
+		g.record(fs.Change{Action: fs.ActionSkip, Path: g.skipPath(tmplPath, appPath, data), Reason: "when condition is false"})
+		return nil
+	}
+
+	templateData := g.templateData(tmplPath, parts, data)
+
+	// Determine output path
+	if frontMatter.Output != "" {
+		outputPath, valid := g.frontMatterOutputPath(tmplPath, frontMatter.Output, appPath, &templateData)
+		if !valid {
+			g.record(fs.Change{Action: fs.ActionSkip, Path: filepath.Join(appPath, tmplPath), Reason: "invalid front-matter output"})
+			return nil
+		}
+		return g.writeTemplate(tmplPath, outputPath, frontMatter.WriteMode(), &templateData)
+	}
+
+	outputPath, valid := g.determineOutputPath(layoutPath, appPath, &templateData)
+	if !valid {
+		if len(parts) > 1 && g.config.TemplateCategories[parts[0]] == "" {
+			g.log.Warnf("Skipping %s: template category %s has no output in template_categories", tmplPath, parts[0])
+			g.record(fs.Change{Action: fs.ActionSkip, Path: filepath.Join(appPath, tmplPath), Reason: "no output for template category " + parts[0]})
+			return nil
+		}
+		g.log.Debugf("Skipping template with invalid path format: %s", tmplPath)
+		return nil
+	}
+
+	return g.writeTemplate(tmplPath, outputPath, frontMatter.WriteMode(), &templateData)
+}
+
+// outputPath returns the path a template is written to for the target of data, like processTemplate
+func (g *Generator) outputPath(tmplPath, appPath string, data *templates.Data) (string, bool) {
+	frontMatter := g.renderer.GetFrontMatter(tmplPath)
+	parts := templateLayout(tmplPath, frontMatter)
+	templateData := g.templateData(tmplPath, parts, data)
+	if frontMatter.Output != "" {
+		return g.frontMatterOutputPath(tmplPath, frontMatter.Output, appPath, &templateData)
+	}
+	return g.determineOutputPath(strings.Join(parts, "/"), appPath, &templateData)
+}
+
+// templateLayout returns the parts of a template path, with the category replaced
+// by the front-matter category so the template is placed as if it was stored there
+func templateLayout(tmplPath string, frontMatter templates.FrontMatter) []string {
+	parts := strings.Split(filepath.ToSlash(tmplPath), "/")
+	if frontMatter.Category != "" && len(parts) > 1 {
+		parts[0] = frontMatter.Category
+	}
+	return parts
+}
+
+// templateData returns a copy of data for a single template to avoid modifying shared data
+// GitHub workflow templates get the workflow filename injected for self-reference
+func (g *Generator) templateData(tmplPath string, parts []string, data *templates.Data) templates.Data {
 	templateData := *data
 
 	// For github workflow templates (.tmpl files), compute and inject the workflow filename
-	if len(parts) > 0 && parts[0] == categoryGithub && strings.HasSuffix(tmplPath, ".tmpl") {
+	if len(parts) > 0 && parts[0] == templates.CategoryGithub && strings.HasSuffix(tmplPath, ".tmpl") {
 		// Extract the original filename (e.g., "lint.yaml.tmpl" -> "lint.yaml")
 		fileName := parts[len(parts)-1]
 		fileName = strings.TrimSuffix(fileName, ".tmpl")
@@ -505,17 +749,34 @@ func (g *Generator) processTemplate(tmplPath, appPath string, data *templates.Da
 		}
 	}
 
-	// Determine output path
-	outputPath, valid := g.determineOutputPath(tmplPath, appPath, &templateData)
-	if !valid {
-		g.log.Debugf("Skipping template with invalid path format: %s", tmplPath)
-		return nil
+	return templateData
+}
+
+// frontMatterOutputPath resolves the front-matter output of a template relative to the project root
+func (g *Generator) frontMatterOutputPath(tmplPath, output, appPath string, data *templates.Data) (string, bool) {
+	outputPath, err := renderValue(tmplPath+" output", output, data)
+	if err != nil {
+		g.log.Warnf("Skipping %s: failed to render output: %v", tmplPath, err)
+		return "", false
 	}
 
-	// Skip if file already exists
+	if !filepath.IsLocal(outputPath) {
+		g.log.Warnf("Skipping %s: output %s is outside the project", tmplPath, outputPath)
+		return "", false
+	}
+	return filepath.Join(projectRoot, outputPath), true
+}
+
+// writeTemplate renders a template to outputPath according to its write mode
+// create-only templates never touch existing files, managed and always templates update them
+func (g *Generator) writeTemplate(tmplPath, outputPath, mode string, data *templates.Data) error {
 	outputName := filepath.Base(outputPath)
-	if g.fs.FileExists(outputPath) {
+	exists := g.fs.FileExists(outputPath)
+
+	// Skip if file already exists
+	if exists && mode == templates.ModeCreateOnly {
 		g.log.Infof("%s already exists, skipping", outputName)
+		g.record(fs.Change{Action: fs.ActionSkip, Path: outputPath, Reason: "already exists"})
 		return nil
 	}
 
@@ -526,15 +787,21 @@ func (g *Generator) processTemplate(tmplPath, appPath string, data *templates.Da
 	}
 
 	// Render and write template (use templateData which contains computed values)
-	content, err := g.renderer.Render(tmplPath, &templateData)
+	content, err := g.renderer.Render(tmplPath, data)
 	if err != nil {
 		g.log.Infof("Skipping %s: failed to render: %v", outputName, err)
+		g.record(fs.Change{Action: fs.ActionSkip, Path: outputPath, Reason: fmt.Sprintf("failed to render: %v", err)})
 		return nil
 	}
 
+	if exists {
+		return g.updateTemplateOutput(tmplPath, outputPath, content, mode, data)
+	}
+
 	if err := g.fs.WriteFile(outputPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", outputName, err)
 	}
+	g.trackFile(outputPath, tmplPath, data, content, content)
 
 	// Log success
 	templateSource := g.renderer.GetTemplateSource(tmplPath)
@@ -546,26 +813,101 @@ func (g *Generator) processTemplate(tmplPath, appPath string, data *templates.Da
 	return nil
 }
 
-// renderBucketName renders a bucket name template with context variables
-func (g *Generator) renderBucketName(bucketTemplate string, data *templates.Data) (string, error) {
-	tmpl, err := template.New("bucket_name").Parse(bucketTemplate)
+// updateTemplateOutput rewrites an existing file of a managed or always template when its content changed
+// Updates of managed templates are shown and confirmed like backend.tf and versions.tf updates
+func (g *Generator) updateTemplateOutput(tmplPath, outputPath, content, mode string, data *templates.Data) error {
+	outputName := filepath.Base(outputPath)
+
+	current, err := g.fs.ReadFile(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", outputName, err)
+	}
+	if string(current) == content {
+		g.log.Infof("%s is up to date", outputName)
+		g.record(fs.Change{Action: fs.ActionSkip, Path: outputPath, Reason: "up to date"})
+		return nil
+	}
+
+	if mode == templates.ModeManaged {
+		if err := g.reviewUpdate(outputPath, content); err != nil {
+			if errors.Is(err, errUpdateDeclined) {
+				g.log.Warnf("Kept existing %s - update declined", outputName)
+				g.record(fs.Change{Action: fs.ActionSkip, Path: outputPath, Reason: "update declined"})
+				return nil
+			}
+			return err
+		}
+	}
+
+	if err := g.fs.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputName, err)
+	}
+	g.trackFile(outputPath, tmplPath, data, content, content)
+	g.log.Successf("Updated %s - template mode %s", outputName, mode)
+	g.explain(outputPath, "template mode "+mode)
+
+	return nil
+}
+
+// skipPath returns the path a skipped template is listed under in the plan
+// It is the output path of the template, or the template path below the app directory
+// when there is none, so the skips of every target in a batch are listed
+func (g *Generator) skipPath(tmplPath, appPath string, data *templates.Data) string {
+	if outputPath, valid := g.outputPath(tmplPath, appPath, data); valid {
+		return outputPath
+	}
+	return filepath.Join(appPath, tmplPath)
+}
+
+// isEmbeddedTemplate reports whether a template comes from the embedded defaults
+func (g *Generator) isEmbeddedTemplate(tmplPath string) bool {
+	return strings.HasPrefix(g.renderer.GetTemplateSource(tmplPath), "embedded:")
+}
+
+// record adds a change to the plan when running against a recording filesystem
+func (g *Generator) record(change fs.Change) {
+	if recorder, ok := g.fs.(fs.Recorder); ok {
+		recorder.Record(change)
+	}
+}
+
+// explain attaches a reason to a recorded change when running against a recording filesystem
+func (g *Generator) explain(path, reason string) {
+	if recorder, ok := g.fs.(fs.Recorder); ok {
+		recorder.Explain(path, reason)
+	}
+}
+
+// renderValue renders value as a template against data if it contains Go template syntax
+func renderValue(name, value string, data *templates.Data) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New(name).Parse(value)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse bucket_name template: %w", err)
+		return "", fmt.Errorf("failed to parse %s template: %w", name, err)
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute bucket_name template: %w", err)
+		return "", fmt.Errorf("failed to execute %s template: %w", name, err)
 	}
 
 	return buf.String(), nil
 }
 
-// shouldUpdateBackend checks if the backend.tf needs updating due to bucket_name changes
-func (g *Generator) shouldUpdateBackend(backendPath string, expectedBucketName string) (bool, error) {
+// renderBucketName renders a bucket name template with context variables
+func (g *Generator) renderBucketName(bucketTemplate string, data *templates.Data) (string, error) {
+	return renderValue("bucket_name", bucketTemplate, data)
+}
+
+// shouldUpdateBackend checks if the backend.tf needs updating due to backend configuration changes
+// The tracked settings depend on the backend type, see backendSpecs
+func (g *Generator) shouldUpdateBackend(backendPath string, data *templates.Data) (bool, []string, error) {
 	content, err := g.fs.ReadFile(backendPath)
 	if err != nil {
-		return false, fmt.Errorf("failed to read backend.tf: %w", err)
+		return false, nil, fmt.Errorf("failed to read backend.tf: %w", err)
 	}
 
 	// Extract metadata from file
@@ -574,14 +916,14 @@ func (g *Generator) shouldUpdateBackend(backendPath string, expectedBucketName s
 		// If no metadata found, file was not generated by tfskel with metadata support
 		// This is expected for old files, so return true (needs update) but no error
 		g.log.Debug("No metadata found in backend.tf, will regenerate to add metadata")
-		return true, nil //nolint:nilerr // missing metadata is expected for old files, not an error
+		return true, []string{"backend.tf needs metadata initialization"}, nil //nolint:nilerr // missing metadata is expected for old files, not an error
 	}
 
 	// Compare metadata with expected values
-	configMetadata := buildBackendMetadata(expectedBucketName)
-	needsUpdate, _ := compareMetadata(fileMetadata, configMetadata)
+	configMetadata := backendMetadata(data)
+	needsUpdate, changes := compareMetadata(fileMetadata, configMetadata)
 
-	return needsUpdate, nil
+	return needsUpdate, changes, nil
 }
 
 // shouldUpdateVersions checks if the versions.tf needs updating due to terraform_version,
@@ -604,7 +946,7 @@ func (g *Generator) shouldUpdateVersions(versionsPath string, data *templates.Da
 		return true, []string{"versions.tf needs metadata initialization"}, nil //nolint:nilerr // missing metadata is expected for old files, not an error
 	}
 
-	configMetadata := buildVersionsMetadata(data.TerraformVersion, data.AWSProviderVersion)
+	configMetadata := versionsMetadata(data)
 	needsUpdate, changes := compareMetadata(fileMetadata, configMetadata)
 	if needsUpdate {
 		allChanges = append(allChanges, changes...)
@@ -629,7 +971,24 @@ func (g *Generator) shouldUpdateVersions(versionsPath string, data *templates.Da
 	return len(allChanges) > 0, allChanges, nil
 }
 
-// updateBackendFile regenerates the backend.tf file with updated configuration
+// updateInPlace applies the owned settings of data to the existing file at path
+// It returns the fully rendered content when the file cannot be updated in place,
+// e.g. because it does not parse or tfskel's blocks were removed by hand
+func (g *Generator) updateInPlace(path, rendered string, data *templates.Data, update func([]byte, string, *templates.Data) ([]byte, error)) string {
+	current, err := g.fs.ReadFile(path)
+	if err != nil {
+		return rendered
+	}
+
+	updated, err := update(current, rendered, data)
+	if err != nil {
+		g.log.Warnf("Regenerating %s from template: %v", filepath.Base(path), err)
+		return rendered
+	}
+	return string(updated)
+}
+
+// updateBackendFile updates the backend.tf file with updated configuration
 func (g *Generator) updateBackendFile(backendPath string, data *templates.Data) error {
 	// Find backend.tf.tmpl template in tf/ category
 	templateName := "tf/backend.tf.tmpl"
@@ -640,15 +999,25 @@ func (g *Generator) updateBackendFile(backendPath string, data *templates.Data)
 		return fmt.Errorf("failed to render backend template: %w", err)
 	}
 
+	// Update only the attributes tfskel owns so hand edits survive, re-render when that is not possible
+	rendered := content
+	content = g.updateInPlace(backendPath, content, data, updateBackendContent)
+
+	// Show the diff and ask for confirmation when enabled
+	if err := g.reviewUpdate(backendPath, content); err != nil {
+		return err
+	}
+
 	// Write updated file
 	if err := g.fs.WriteFile(backendPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write backend.tf: %w", err)
 	}
+	g.trackFile(backendPath, templateName, data, rendered, content)
 
 	return nil
 }
 
-// updateVersionsFile regenerates the versions.tf file with updated configuration
+// updateVersionsFile updates the versions.tf file with updated configuration
 func (g *Generator) updateVersionsFile(versionsPath string, data *templates.Data) error {
 	// Find versions.tf.tmpl template in tf/ category
 	templateName := "tf/versions.tf.tmpl"
@@ -659,10 +1028,20 @@ func (g *Generator) updateVersionsFile(versionsPath string, data *templates.Data
 		return fmt.Errorf("failed to render versions template: %w", err)
 	}
 
+	// Update only the attributes tfskel owns so hand edits survive, re-render when that is not possible
+	rendered := content
+	content = g.updateInPlace(versionsPath, content, data, updateVersionsContent)
+
+	// Show the diff and ask for confirmation when enabled
+	if err := g.reviewUpdate(versionsPath, content); err != nil {
+		return err
+	}
+
 	// Write updated file
 	if err := g.fs.WriteFile(versionsPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write versions.tf: %w", err)
 	}
+	g.trackFile(versionsPath, templateName, data, rendered, content)
 
 	return nil
 }
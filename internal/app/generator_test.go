@@ -2,7 +2,9 @@ package app
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -117,6 +119,343 @@ func TestGenerator_generateFiles(t *testing.T) {
 	})
 }
 
+func TestGenerator_DryRun(t *testing.T) {
+	newConfig := func(bucketName string) *config.Config {
+		return &config.Config{
+			TerraformVersion: "~> 1.13",
+			Provider: &config.Provider{
+				AWS: &config.AWSProvider{
+					Version:        "~> 6.0",
+					AccountMapping: map[string]string{"dev": "123456789012"},
+				},
+			},
+			Backend: &config.Backend{S3: &config.S3Backend{BucketName: bucketName}},
+		}
+	}
+	appPath := filepath.Join("envs", "dev", "us-east-1", "myapp")
+	backendPath := filepath.Join(appPath, "backend.tf")
+
+	base := fs.NewMemoryFileSystem()
+	require.NoError(t, NewGenerator(newConfig("old-bucket"), base, logger.New(false)).Run("dev", "us-east-1", "myapp"))
+	original, err := base.ReadFile(backendPath)
+	require.NoError(t, err)
+
+	dryRun := fs.NewDryRunFileSystem(base)
+	require.NoError(t, NewGenerator(newConfig("new-bucket"), dryRun, logger.New(false)).Run("dev", "us-east-1", "myapp"))
+
+	changes := make(map[string]fs.Change)
+	for _, change := range dryRun.Changes() {
+		changes[change.Path] = change
+	}
+
+	assert.Equal(t, fs.ActionSkip, changes[appPath].Action)
+	assert.Equal(t, fs.ActionUpdate, changes[backendPath].Action)
+	assert.Contains(t, changes[backendPath].Reason, "new-bucket")
+	assert.Equal(t, fs.Change{Action: fs.ActionSkip, Path: filepath.Join(appPath, "versions.tf"), Reason: "already exists"}, changes[filepath.Join(appPath, "versions.tf")])
+	assert.Contains(t, changes[filepath.Join(".github", "workflows", "myapp-dev-use1-lint.yaml")].Reason, "github category disabled")
+
+	// Skips are listed for every target of a batch
+	require.NoError(t, NewGenerator(newConfig("new-bucket"), dryRun, logger.New(false)).Run("dev", "us-east-1", "otherapp"))
+	var skipped []string
+	for _, change := range dryRun.Changes() {
+		if strings.HasSuffix(change.Path, "-dev-use1-lint.yaml") {
+			skipped = append(skipped, change.Path)
+		}
+	}
+	assert.Equal(t, []string{
+		filepath.Join(".github", "workflows", "myapp-dev-use1-lint.yaml"),
+		filepath.Join(".github", "workflows", "otherapp-dev-use1-lint.yaml"),
+	}, skipped)
+
+	// Nothing was written to the underlying filesystem
+	content, err := base.ReadFile(backendPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, content)
+}
+
+func TestGenerator_AzureRM(t *testing.T) {
+	newAzureConfig := func(containerName string) *config.Config {
+		return &config.Config{
+			TerraformVersion: "~> 1.13",
+			Provider: &config.Provider{
+				AzureRM: &config.AzureRMProvider{
+					Version: "~> 4.0",
+					SubscriptionMapping: map[string]string{
+						"dev": "11111111-2222-3333-4444-555555555555",
+					},
+				},
+			},
+			Backend: &config.Backend{
+				AzureRM: &config.AzureRMBackend{
+					ResourceGroupName:  "rg-tfstate-{{.Env}}",
+					StorageAccountName: "sttfstate{{.Env}}",
+					ContainerName:      containerName,
+				},
+			},
+		}
+	}
+
+	t.Run("generates azurerm backend and provider", func(t *testing.T) {
+		filesystem := fs.NewMemoryFileSystem()
+		gen := NewGenerator(newAzureConfig("tfstate"), filesystem, logger.New(false))
+
+		renderer, err := templates.NewRenderer()
+		require.NoError(t, err)
+		gen.renderer = renderer
+
+		appPath := "envs/dev/westeurope/myapp"
+		err = gen.generateFiles(appPath, "dev", "westeurope", "myapp")
+		require.NoError(t, err)
+
+		backend, err := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(backend), `resource_group_name  = "rg-tfstate-dev"`)
+		assert.Contains(t, string(backend), `storage_account_name = "sttfstatedev"`)
+		assert.Contains(t, string(backend), `key                  = "myapp-dev-westeurope/terraform.tfstate"`)
+
+		versions, err := filesystem.ReadFile(filepath.Join(appPath, "versions.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(versions), `provider "azurerm"`)
+		assert.Contains(t, string(versions), `subscription_id = "11111111-2222-3333-4444-555555555555"`)
+	})
+
+	t.Run("updates backend.tf when container changes", func(t *testing.T) {
+		filesystem := fs.NewMemoryFileSystem()
+		appPath := "envs/dev/westeurope/myapp"
+
+		gen := NewGenerator(newAzureConfig("tfstate"), filesystem, logger.New(false))
+		renderer, err := templates.NewRenderer()
+		require.NoError(t, err)
+		gen.renderer = renderer
+		require.NoError(t, gen.generateFiles(appPath, "dev", "westeurope", "myapp"))
+
+		gen = NewGenerator(newAzureConfig("state"), filesystem, logger.New(false))
+		gen.renderer = renderer
+		require.NoError(t, gen.generateFiles(appPath, "dev", "westeurope", "myapp"))
+
+		backend, err := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(backend), `container_name       = "state"`)
+	})
+}
+
+func TestGenerator_Google(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			Google: &config.GoogleProvider{
+				Version:        "~> 7.0",
+				ProjectMapping: map[string]string{"dev": "my-dev-project"},
+				DefaultLabels:  map[string]string{"team": "platform"},
+			},
+		},
+		Backend: &config.Backend{
+			GCS: &config.GCSBackend{Bucket: "{{.Env}}-tfstate"},
+		},
+	}
+	filesystem := fs.NewMemoryFileSystem()
+	gen := NewGenerator(cfg, filesystem, logger.New(false))
+
+	renderer, err := templates.NewRenderer()
+	require.NoError(t, err)
+	gen.renderer = renderer
+
+	appPath := "envs/dev/europe-west1/myapp"
+	require.NoError(t, gen.generateFiles(appPath, "dev", "europe-west1", "myapp"))
+
+	backend, err := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(backend), `backend "gcs"`)
+	assert.Contains(t, string(backend), `bucket = "dev-tfstate"`)
+	assert.Contains(t, string(backend), `prefix = "myapp-dev-europe-west1"`)
+
+	versions, err := filesystem.ReadFile(filepath.Join(appPath, "versions.tf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(versions), `source  = "hashicorp/google"`)
+	assert.Contains(t, string(versions), `project = "my-dev-project"`)
+	assert.Contains(t, string(versions), `team = "platform"`)
+
+	data, err := gen.prepareTemplateData("dev", "europe-west1", "myapp")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"bucket": "dev-tfstate", "prefix": "myapp-dev-europe-west1"}, backendMetadata(data))
+	assert.Equal(t, map[string]string{"tf_ver": "~> 1.13", "google_provider_ver": "~> 7.0"}, versionsMetadata(data))
+}
+
+func TestGenerator_ProviderRegionLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *config.Provider
+		region   string
+		want     string
+	}{
+		{
+			name:     "aws",
+			provider: &config.Provider{AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}}},
+			region:   "eu-central-1",
+			want:     `region = "eu-central-1"`,
+		},
+		{
+			name:     "azurerm",
+			provider: &config.Provider{AzureRM: &config.AzureRMProvider{SubscriptionMapping: map[string]string{"dev": "11111111-2222-3333-4444-555555555555"}}},
+			region:   "westeurope",
+			want:     `location = "westeurope"`,
+		},
+		{
+			name:     "google",
+			provider: &config.Provider{Google: &config.GoogleProvider{ProjectMapping: map[string]string{"dev": "my-dev-project"}}},
+			region:   "europe-west1",
+			want:     `region  = "europe-west1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Provider: tt.provider,
+				Layout:   &config.Layout{AppPath: "{{.AppDir}}/{{.Env}}"},
+			}
+			filesystem := fs.NewMemoryFileSystem()
+			require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", tt.region, "payments"))
+
+			versions, err := filesystem.ReadFile(filepath.Join("payments", "dev", "versions.tf"))
+			require.NoError(t, err)
+			assert.Contains(t, string(versions), tt.want)
+			assert.NotContains(t, string(versions), "path.cwd")
+		})
+	}
+}
+
+func TestGenerator_Vars(t *testing.T) {
+	templatesDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "owners.tf.tmpl"),
+		[]byte(`# owner={{.Vars.owner}} cost_center={{.Vars.cost_center}} cidr={{.Vars.vpc_cidr}}`), 0644))
+
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012", "prd": "210987654321"}},
+		},
+		Backend:                 &config.Backend{S3: &config.S3Backend{BucketName: "{{.Vars.team}}-{{.Env}}-tfstate"}},
+		Generate:                &config.Generate{GithubWorkflows: &config.GithubWorkflows{NameTemplate: "{{.Vars.team}}-{{.AppDir}}"}},
+		TemplatesDir:            templatesDir,
+		ExtraTemplateExtensions: []string{"tf.tmpl"},
+		Vars:                    map[string]any{"team": "platform", "owner": "platform", "cost_center": "1000", "vpc_cidr": "10.0.0.0/16"},
+		Envs:                    map[string]config.Environment{"prd": {Vars: map[string]any{"owner": "sre", "vpc_cidr": "10.1.0.0/16"}}},
+		Apps:                    []config.App{{Name: "payments", Vars: map[string]any{"cost_center": "2000"}}},
+		SetVars:                 map[string]string{"vpc_cidr": "10.9.0.0/16"},
+	}
+	filesystem := fs.NewMemoryFileSystem()
+	gen := NewGenerator(cfg, filesystem, logger.New(false))
+	require.NoError(t, gen.Run("prd", "eu-central-1", "payments"))
+
+	appPath := filepath.Join("envs", "prd", "eu-central-1", "payments")
+	owners, err := filesystem.ReadFile(filepath.Join(appPath, "owners.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, "# owner=sre cost_center=2000 cidr=10.9.0.0/16", string(owners))
+
+	backend, err := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(backend), `tfskel-metadata: {"bucket": "platform-prd-tfstate"}`)
+
+	data, err := gen.prepareTemplateData("prd", "eu-central-1", "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "platform-payments-lint.yaml", gen.generateWorkflowFileName("lint.yaml", data))
+}
+
+func TestGenerator_FrontMatter(t *testing.T) {
+	templatesDir := t.TempDir()
+	writeTemplate := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(templatesDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(templatesDir, name), []byte(content), 0644))
+	}
+	writeTemplate("README.md.tmpl", "## tfskel:\n##   output: envs/{{.Env}}/README.md\n##   mode: always\n# {{.Env}} owned by {{.Vars.owner}}\n")
+	writeTemplate("alarms.tf.tmpl", "## tfskel:\n##   when: eq .Env \"prd\"\n# alarms for {{.AppDir}}\n")
+	writeTemplate("locals.tf.tmpl", "## tfskel:\n##   mode: managed\n# owner = {{.Vars.owner}}\n")
+	writeTemplate("CODEOWNERS.tf.tmpl", "## tfskel:\n##   category: root\n# {{.Vars.owner}}\n")
+	writeTemplate("notes.tf.tmpl", "# notes owner {{.Vars.owner}}\n")
+
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012", "prd": "210987654321"}},
+		},
+		Backend:                 &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		TemplatesDir:            templatesDir,
+		ExtraTemplateExtensions: []string{"tf.tmpl", "md.tmpl"},
+		Vars:                    map[string]any{"owner": "platform"},
+	}
+	filesystem := fs.NewMemoryFileSystem()
+	require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+
+	appPath := filepath.Join("envs", "dev", "us-east-1", "payments")
+	assertContent := func(path, expected string) {
+		t.Helper()
+		content, err := filesystem.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(content))
+	}
+	assertContent(filepath.Join("envs", "dev", "README.md"), "# dev owned by platform\n")
+	assertContent(filepath.Join(appPath, "locals.tf"), "# owner = platform\n")
+	assertContent("CODEOWNERS.tf", "# platform\n")
+	assert.False(t, filesystem.FileExists(filepath.Join(appPath, "alarms.tf")), "when condition is false for dev")
+
+	// A second run with a changed variable updates managed and always files only
+	cfg.Vars["owner"] = "sre"
+	require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+	assertContent(filepath.Join("envs", "dev", "README.md"), "# dev owned by sre\n")
+	assertContent(filepath.Join(appPath, "locals.tf"), "# owner = sre\n")
+	assertContent(filepath.Join(appPath, "notes.tf"), "# notes owner platform\n")
+	assertContent("CODEOWNERS.tf", "# platform\n")
+
+	require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("prd", "us-east-1", "payments"))
+	assertContent(filepath.Join("envs", "prd", "us-east-1", "payments", "alarms.tf"), "# alarms for payments\n")
+
+	// A when expression that does not evaluate fails the run instead of dropping the file
+	writeTemplate("alarms.tf.tmpl", "## tfskel:\n##   when: eq .Env\n# alarms for {{.AppDir}}\n")
+	err := NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments")
+	require.ErrorIs(t, err, templates.ErrInvalidFrontMatter)
+	assert.Contains(t, err.Error(), "alarms.tf.tmpl")
+}
+
+func TestGenerator_Overrides(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{
+				Version:        "~> 6.0",
+				AccountMapping: map[string]string{"dev": "123456789012", "prd": "210987654321"},
+				DefaultTags:    map[string]string{"team": "platform"},
+			},
+		},
+		Backend: &config.Backend{S3: &config.S3Backend{BucketName: "{{.Env}}-tfstate"}},
+		Envs: map[string]config.Environment{
+			"dev": {Overrides: config.Overrides{
+				TerraformVersion: "~> 1.14",
+				Provider:         &config.Provider{AWS: &config.AWSProvider{DefaultTags: map[string]string{"cost": "low"}}},
+				Backend:          &config.Backend{S3: &config.S3Backend{BucketName: "sandbox-tfstate"}},
+			}},
+		},
+		Apps: []config.App{
+			{Name: "payments", Overrides: config.Overrides{Provider: &config.Provider{AWS: &config.AWSProvider{Version: "~> 6.2"}}}},
+		},
+	}
+	gen := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false))
+
+	data, err := gen.prepareTemplateData("dev", "eu-central-1", "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "~> 1.14", data.TerraformVersion)
+	assert.Equal(t, "~> 6.2", data.AWSProviderVersion)
+	assert.Equal(t, map[string]string{"team": "platform", "cost": "low"}, data.DefaultTags)
+	assert.Equal(t, "sandbox-tfstate", data.S3BucketName)
+
+	data, err = gen.prepareTemplateData("prd", "eu-central-1", "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "~> 1.13", data.TerraformVersion)
+	assert.Equal(t, "~> 6.0", data.AWSProviderVersion)
+	assert.Equal(t, map[string]string{"team": "platform"}, data.DefaultTags)
+	assert.Equal(t, "prd-tfstate", data.S3BucketName)
+}
+
 func TestGenerator_Run_Integration(t *testing.T) {
 	t.Run("full generation workflow", func(t *testing.T) {
 		cfg := &config.Config{
@@ -186,6 +525,38 @@ func TestGenerator_Run_Integration(t *testing.T) {
 		appPath := "envs/stg/us-west-2/myapp"
 		assert.True(t, filesystem.DirExists(appPath))
 	})
+
+	t.Run("custom templates keep their category layout", func(t *testing.T) {
+		templatesDir := t.TempDir()
+		customTemplates := map[string]string{
+			"tf/modules/network.tf.tmpl": "# {{.AppDir}} network\n",
+			"root/CODEOWNERS.tmpl":       "* @platform\n",
+			"docs/runbook.md.tmpl":       "# {{.AppDir}} in {{.Env}}\n",
+		}
+		for name, content := range customTemplates {
+			path := filepath.Join(templatesDir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+
+		cfg := &config.Config{
+			TerraformVersion:        "~> 1.13",
+			Provider:                &config.Provider{AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}}},
+			TemplatesDir:            templatesDir,
+			ExtraTemplateExtensions: []string{"tf.tmpl", "md.tmpl", "tmpl"},
+			TemplateCategories:      map[string]string{"docs": "docs/{{.AppDir}}"},
+		}
+		filesystem := fs.NewMemoryFileSystem()
+		gen := NewGenerator(cfg, filesystem, logger.New(false))
+		require.NoError(t, gen.Run("dev", "eu-central-1", "myapp"))
+
+		assert.True(t, filesystem.FileExists(filepath.Join("envs", "dev", "eu-central-1", "myapp", "modules", "network.tf")))
+		assert.True(t, filesystem.FileExists("CODEOWNERS"), "custom root templates are generated at the project root")
+		assert.False(t, filesystem.FileExists(".gitignore"), "default root templates stay init only")
+		content, err := filesystem.ReadFile(filepath.Join("docs", "myapp", "runbook.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# myapp in dev\n", string(content))
+	})
 }
 
 func TestGenerator_renderBucketName(t *testing.T) {
@@ -261,7 +632,7 @@ terraform {
 }`
 		_ = filesystem.WriteFile("backend.tf", []byte(backendContent), 0644)
 
-		needsUpdate, err := gen.shouldUpdateBackend("backend.tf", "new-bucket-name")
+		needsUpdate, _, err := gen.shouldUpdateBackend("backend.tf", &templates.Data{S3BucketName: "new-bucket-name"})
 		assert.NoError(t, err)
 		assert.True(t, needsUpdate)
 	})
@@ -281,7 +652,7 @@ terraform {
 }`
 		_ = filesystem.WriteFile("backend.tf", []byte(backendContent), 0644)
 
-		needsUpdate, err := gen.shouldUpdateBackend("backend.tf", "same-bucket-name")
+		needsUpdate, _, err := gen.shouldUpdateBackend("backend.tf", &templates.Data{S3BucketName: "same-bucket-name"})
 		assert.NoError(t, err)
 		assert.False(t, needsUpdate)
 	})
@@ -291,7 +662,7 @@ terraform {
 		filesystem := fs.NewMemoryFileSystem()
 		gen := NewGenerator(cfg, filesystem, logger.New(false))
 
-		needsUpdate, err := gen.shouldUpdateBackend("nonexistent.tf", "bucket-name")
+		needsUpdate, _, err := gen.shouldUpdateBackend("nonexistent.tf", &templates.Data{S3BucketName: "bucket-name"})
 		assert.Error(t, err)
 		assert.False(t, needsUpdate)
 	})
@@ -309,7 +680,7 @@ terraform {
 }`
 		_ = filesystem.WriteFile("backend.tf", []byte(backendContent), 0644)
 
-		needsUpdate, err := gen.shouldUpdateBackend("backend.tf", "old-style-bucket")
+		needsUpdate, _, err := gen.shouldUpdateBackend("backend.tf", &templates.Data{S3BucketName: "old-style-bucket"})
 		assert.NoError(t, err)
 		assert.True(t, needsUpdate) // Should regenerate to add metadata
 	})
@@ -1150,7 +1521,9 @@ terraform {
 		// Verify backend.tf was updated
 		backendContent, readErr := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
 		assert.NoError(t, readErr)
-		assert.Contains(t, string(backendContent), `bucket              = "new-bucket-name"`)
+		// Only the bucket value is replaced, the file's own formatting is kept
+		assert.Contains(t, string(backendContent), `bucket = "new-bucket-name"`)
+		assert.Contains(t, string(backendContent), `key    = "terraform.tfstate"`)
 		assert.NotContains(t, string(backendContent), "old-bucket-name")
 		assert.Contains(t, string(backendContent), `## tfskel-metadata:`)
 
@@ -1212,7 +1585,7 @@ terraform {
 		// Verify backend.tf was regenerated with correct metadata
 		backendContent, readErr := filesystem.ReadFile(filepath.Join(appPath, "backend.tf"))
 		assert.NoError(t, readErr)
-		assert.Contains(t, string(backendContent), `bucket              = "my-bucket"`)
+		assert.Contains(t, string(backendContent), `bucket = "my-bucket"`)
 
 		// Verify metadata is now valid
 		metadata, metaErr := extractMetadata(string(backendContent), "metadata")
@@ -1534,11 +1907,54 @@ func TestGenerator_determineOutputPath_GitHubWorkflows(t *testing.T) {
 			expectedPath: ".gitignore",
 			expectedOK:   true,
 		},
+		{
+			name:         "tf template keeps its subdirectory",
+			tmplPath:     "tf/modules/network.tf.tmpl",
+			appPath:      "envs/dev/eu-central-1/myapp",
+			data:         &templates.Data{AppDir: "myapp"},
+			expectedPath: "envs/dev/eu-central-1/myapp/modules/network.tf",
+			expectedOK:   true,
+		},
+		{
+			name:         "root template keeps its subdirectory",
+			tmplPath:     "root/docs/README.md.tmpl",
+			appPath:      "envs/dev/eu-central-1/myapp",
+			data:         &templates.Data{AppDir: "myapp"},
+			expectedPath: "docs/README.md",
+			expectedOK:   true,
+		},
+		{
+			name:         "user-defined category goes to its mapped output",
+			tmplPath:     "docs/runbooks/oncall.md.tmpl",
+			appPath:      "envs/dev/eu-central-1/myapp",
+			data:         &templates.Data{AppDir: "myapp", Env: "dev"},
+			expectedPath: "docs/myapp/dev/runbooks/oncall.md",
+			expectedOK:   true,
+		},
+		{
+			name:       "user-defined category without mapping is skipped",
+			tmplPath:   "unmapped/file.md.tmpl",
+			appPath:    "envs/dev/eu-central-1/myapp",
+			data:       &templates.Data{AppDir: "myapp"},
+			expectedOK: false,
+		},
+		{
+			name:       "user-defined category rendering outside the project is skipped",
+			tmplPath:   "escape/file.md.tmpl",
+			appPath:    "envs/dev/eu-central-1/myapp",
+			data:       &templates.Data{AppDir: "../.."},
+			expectedOK: false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cfg := &config.Config{}
+			cfg := &config.Config{
+				TemplateCategories: map[string]string{
+					"docs":   "docs/{{.AppDir}}/{{.Env}}",
+					"escape": "{{.AppDir}}",
+				},
+			}
 			filesystem := fs.NewMemoryFileSystem()
 			log := logger.New(false)
 			gen := NewGenerator(cfg, filesystem, log)
@@ -1611,6 +2027,28 @@ func TestGenerator_GitHubWorkflows_Integration(t *testing.T) {
 		terraformContent, err := filesystem.ReadFile(expectedTerraformWorkflow)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, terraformContent, "terraform workflow should have content")
+		assert.Contains(t, string(terraformContent), "'envs/dev/eu-central-1/testapp/**'")
+	})
+
+	t.Run("workflow paths follow layout.app_path", func(t *testing.T) {
+		cfg := &config.Config{
+			Provider: &config.Provider{
+				AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+			},
+			Generate: &config.Generate{GithubWorkflows: &config.GithubWorkflows{Create: true}},
+			Layout:   &config.Layout{AppPath: "accounts/{{.Env}}/{{.Region}}/{{.AppDir}}"},
+		}
+
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "eu-central-1", "testapp"))
+		assert.True(t, filesystem.FileExists(filepath.Join("accounts", "dev", "eu-central-1", "testapp", "backend.tf")))
+
+		for _, workflow := range []string{"testapp-dev-euc1-lint.yaml", "testapp-dev-euc1-terraform.yaml"} {
+			content, err := filesystem.ReadFile(filepath.Join(".github", "workflows", workflow))
+			require.NoError(t, err)
+			assert.Contains(t, string(content), "'accounts/dev/eu-central-1/testapp/**'")
+			assert.NotContains(t, string(content), "envs/")
+		}
 	})
 
 	t.Run("skips github workflows when flag is disabled", func(t *testing.T) {
@@ -1833,7 +2271,7 @@ func TestGenerator_GitHubWorkflows_Integration(t *testing.T) {
 	})
 }
 
-func TestGenerator_buildAWSRoleArn(t *testing.T) {
+func TestBuildAWSRoleArn(t *testing.T) {
 	tests := []struct {
 		name        string
 		config      *config.Config
@@ -1952,11 +2390,7 @@ func TestGenerator_buildAWSRoleArn(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filesystem := fs.NewMemoryFileSystem()
-			log := logger.New(false)
-			gen := NewGenerator(tt.config, filesystem, log)
-
-			result := gen.buildAWSRoleArn(tt.env)
+			result := buildAWSRoleArn(tt.config, tt.env)
 			assert.Equal(t, tt.expectedArn, result)
 		})
 	}
@@ -2041,3 +2475,34 @@ func TestSanitizeWorkflowFileName(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerator_RegionCodes(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+		},
+		Backend:  &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Generate: &config.Generate{GithubWorkflows: &config.GithubWorkflows{Create: true}},
+	}
+
+	t.Run("catalog codes keep workflow names apart", func(t *testing.T) {
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "ap-south-1", "payments"))
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "ap-southeast-1", "payments"))
+
+		assert.True(t, filesystem.FileExists(filepath.Join(".github", "workflows", "payments-dev-aps1-terraform.yaml")))
+		assert.True(t, filesystem.FileExists(filepath.Join(".github", "workflows", "payments-dev-apse1-terraform.yaml")))
+	})
+
+	t.Run("colliding aliases are rejected at generate time", func(t *testing.T) {
+		aliased := *cfg
+		aliased.RegionAliases = map[string]string{"ap-southeast-1": "aps1"}
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(&aliased, filesystem, logger.New(false)).Run("dev", "ap-south-1", "payments"))
+
+		err := NewGenerator(&aliased, filesystem, logger.New(false)).Run("dev", "ap-southeast-1", "orders")
+		assert.ErrorIs(t, err, config.ErrRegionCodeCollision)
+		assert.False(t, filesystem.DirExists(filepath.Join("envs", "dev", "ap-southeast-1")))
+	})
+}
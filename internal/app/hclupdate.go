@@ -0,0 +1,405 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+// errNotUpdatable indicates a managed file cannot be updated in place and has to be re-rendered
+var errNotUpdatable = errors.New("file cannot be updated in place")
+
+// defaultIndent is the indentation used for new attributes when a body has none to copy from
+const defaultIndent = 2
+
+// updateBackendContent updates only the attributes tfskel owns in an existing backend.tf
+// The owned attributes are the ones tracked in the backend metadata; everything else,
+// including formatting and comments, is kept byte-for-byte
+func updateBackendContent(current []byte, rendered string, data *templates.Data) ([]byte, error) {
+	file, err := parseManagedFile(current, "backend.tf")
+	if err != nil {
+		return nil, err
+	}
+
+	terraform := file.Body().FirstMatchingBlock("terraform", nil)
+	if terraform == nil {
+		return nil, fmt.Errorf("%w: no terraform block", errNotUpdatable)
+	}
+
+	backendType := valueOrDefault(data.BackendType, config.BackendS3)
+	var block *hclwrite.Block
+	if backendType == config.BackendCloud {
+		block = terraform.Body().FirstMatchingBlock("cloud", nil)
+	} else {
+		block = terraform.Body().FirstMatchingBlock("backend", []string{backendType})
+	}
+	if block == nil {
+		return nil, fmt.Errorf("%w: no %s backend block", errNotUpdatable, backendType)
+	}
+
+	spec := backendSpecs[backendType]
+	metadata := backendMetadata(data)
+	for _, key := range spec.metadataKeys {
+		path := key
+		if attribute, ok := spec.attributes[key]; ok {
+			path = attribute
+		}
+
+		body, name := block.Body(), path
+		if blockName, attribute, nested := strings.Cut(path, "."); nested {
+			nestedBlock := body.FirstMatchingBlock(blockName, nil)
+			if nestedBlock == nil {
+				return nil, fmt.Errorf("%w: no %s block", errNotUpdatable, blockName)
+			}
+			body, name = nestedBlock.Body(), attribute
+		}
+		setStringAttribute(body, name, metadata[key])
+	}
+
+	return replaceMetadataLines(file.BuildTokens(nil).Bytes(), rendered, "metadata")
+}
+
+// updateVersionsContent updates only the attributes tfskel owns in an existing versions.tf:
+// required_version, the provider version in required_providers and the default tag entries
+// Tags added by hand are kept, tags removed from config since the last run are dropped
+func updateVersionsContent(current []byte, rendered string, data *templates.Data) ([]byte, error) {
+	file, err := parseManagedFile(current, "versions.tf")
+	if err != nil {
+		return nil, err
+	}
+
+	terraform := file.Body().FirstMatchingBlock("terraform", nil)
+	if terraform == nil {
+		return nil, fmt.Errorf("%w: no terraform block", errNotUpdatable)
+	}
+	setStringAttribute(terraform.Body(), "required_version", data.TerraformVersion)
+
+	provider, providerVersion := versionsProvider(data)
+	if requiredProviders := terraform.Body().FirstMatchingBlock("required_providers", nil); requiredProviders != nil {
+		if requiredProviders.Body().GetAttribute(provider) != nil {
+			if err := updateObjectAttribute(requiredProviders.Body(), provider, []objectEntry{{"version", providerVersion}}, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if body, name := findTagsAttribute(file.Body(), data); body != nil {
+		// Tags tracked by the previous run that are no longer configured are removed
+		previousTags, _ := extractMetadata(string(current), "tags") //nolint:errcheck // files without tags metadata have nothing to remove
+		removed := make(map[string]bool)
+		for key := range previousTags {
+			if _, ok := data.DefaultTags[key]; !ok {
+				removed[key] = true
+			}
+		}
+		if err := updateObjectAttribute(body, name, desiredTags(data), removed); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := replaceMetadataLines(file.BuildTokens(nil).Bytes(), rendered, "metadata")
+	if err != nil {
+		return nil, err
+	}
+	return replaceMetadataLines(content, rendered, "tags")
+}
+
+// parseManagedFile parses a managed file for in-place updates
+func parseManagedFile(content []byte, filename string) (*hclwrite.File, error) {
+	file, diags := hclwrite.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("%w: %s", errNotUpdatable, diags.Error())
+	}
+	return file, nil
+}
+
+// versionsProvider returns the required_providers entry and version owned by tfskel for the selected cloud
+func versionsProvider(data *templates.Data) (string, string) {
+	switch data.Cloud {
+	case config.CloudAzureRM:
+		return config.CloudAzureRM, data.AzureRMProviderVersion
+	case config.CloudGoogle:
+		return config.CloudGoogle, data.GoogleProviderVersion
+	default:
+		return config.CloudAWS, data.AWSProviderVersion
+	}
+}
+
+// findTagsAttribute returns the body and attribute name holding the default tags for the selected cloud
+func findTagsAttribute(root *hclwrite.Body, data *templates.Data) (*hclwrite.Body, string) {
+	switch data.Cloud {
+	case config.CloudAzureRM:
+		if locals := root.FirstMatchingBlock("locals", nil); locals != nil && locals.Body().GetAttribute("default_tags") != nil {
+			return locals.Body(), "default_tags"
+		}
+	case config.CloudGoogle:
+		if provider := root.FirstMatchingBlock("provider", []string{config.CloudGoogle}); provider != nil && provider.Body().GetAttribute("default_labels") != nil {
+			return provider.Body(), "default_labels"
+		}
+	default:
+		if provider := root.FirstMatchingBlock("provider", []string{config.CloudAWS}); provider != nil {
+			if defaultTags := provider.Body().FirstMatchingBlock("default_tags", nil); defaultTags != nil && defaultTags.Body().GetAttribute("tags") != nil {
+				return defaultTags.Body(), "tags"
+			}
+		}
+	}
+	return nil, ""
+}
+
+// desiredTags returns the tag entries tfskel renders, in template order
+func desiredTags(data *templates.Data) []objectEntry {
+	keys := make([]string, 0, len(data.DefaultTags))
+	for key := range data.DefaultTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]objectEntry, 0, len(keys)+2)
+	for _, key := range keys {
+		entries = append(entries, objectEntry{key, data.DefaultTags[key]})
+	}
+	return append(entries, objectEntry{"env", data.Env}, objectEntry{"app", data.AppDir})
+}
+
+// setStringAttribute sets a string attribute, replacing only the value tokens of an existing one
+// An empty value removes the attribute, which is how optional settings are cleared
+func setStringAttribute(body *hclwrite.Body, name, value string) {
+	attr := body.GetAttribute(name)
+	if value == "" {
+		if attr != nil {
+			body.RemoveAttribute(name)
+		}
+		return
+	}
+
+	tokens := hclwrite.TokensForValue(cty.StringVal(value))
+	if attr != nil {
+		tokens[0].SpacesBefore = attr.Expr().BuildTokens(nil)[0].SpacesBefore
+		body.SetAttributeRaw(name, tokens)
+		return
+	}
+
+	indent := bodyIndent(body)
+	body.SetAttributeRaw(name, tokens)
+	added := body.GetAttribute(name).BuildTokens(nil)
+	added[0].SpacesBefore = indent
+	added[1].SpacesBefore = 1
+	added[2].SpacesBefore = 1
+}
+
+// bodyIndent returns the indentation of the existing attributes in body
+func bodyIndent(body *hclwrite.Body) int {
+	for _, attr := range body.Attributes() {
+		return attr.BuildTokens(nil)[0].SpacesBefore
+	}
+	for _, block := range body.Blocks() {
+		return block.BuildTokens(nil)[0].SpacesBefore
+	}
+	return defaultIndent
+}
+
+// objectEntry is a string valued entry of an object constructor expression
+type objectEntry struct {
+	key   string
+	value string
+}
+
+// objectItem is one line of an object constructor expression
+// Items without a key are blank lines or comments and are passed through unchanged
+type objectItem struct {
+	key    string
+	tokens hclwrite.Tokens
+	value  [2]int // start and end index of the value tokens
+}
+
+// updateObjectAttribute sets the given entries of a multi-line object attribute such as
+// tags = { ... } and drops the removed keys, leaving all other entries untouched
+func updateObjectAttribute(body *hclwrite.Body, name string, entries []objectEntry, removed map[string]bool) error {
+	tokens := body.GetAttribute(name).Expr().BuildTokens(nil)
+	if len(tokens) < 2 || tokens[0].Type != hclsyntax.TokenOBrace || tokens[len(tokens)-1].Type != hclsyntax.TokenCBrace {
+		return fmt.Errorf("%w: %s is not an object", errNotUpdatable, name)
+	}
+	openBrace, closeBrace := tokens[0], tokens[len(tokens)-1]
+
+	items := splitObjectItems(tokens[1 : len(tokens)-1])
+	if len(items) == 0 || !endsLine(items[0].tokens) {
+		return fmt.Errorf("%w: %s is not a multi-line object", errNotUpdatable, name)
+	}
+
+	indent := -1
+	positions := make(map[string]int)
+	for i, item := range items {
+		if item.key == "" {
+			continue
+		}
+		if indent == -1 {
+			indent = item.tokens[0].SpacesBefore
+		}
+		positions[item.key] = i
+	}
+	if indent == -1 {
+		indent = closeBrace.SpacesBefore + defaultIndent
+	}
+
+	// Replace values in place and collect entries that are missing
+	var missing []objectEntry
+	for _, entry := range entries {
+		i, ok := positions[entry.key]
+		if !ok {
+			missing = append(missing, entry)
+			continue
+		}
+		item := &items[i]
+		value := hclwrite.TokensForValue(cty.StringVal(entry.value))
+		value[0].SpacesBefore = item.tokens[item.value[0]].SpacesBefore
+		replaced := append(hclwrite.Tokens{}, item.tokens[:item.value[0]]...)
+		replaced = append(replaced, value...)
+		item.tokens = append(replaced, item.tokens[item.value[1]:]...)
+	}
+
+	// New entries are inserted before the first entry rendered after them by the template
+	// (entries are sorted, with env and app last) so regenerated and updated files match
+	result := hclwrite.Tokens{openBrace}
+	for _, item := range items {
+		if item.key != "" && removed[item.key] {
+			continue
+		}
+		for len(missing) > 0 && item.key != "" && insertBefore(missing[0].key, item.key, entries) {
+			result = append(result, objectEntryTokens(missing[0], indent)...)
+			missing = missing[1:]
+		}
+		result = append(result, item.tokens...)
+	}
+	for _, entry := range missing {
+		result = append(result, objectEntryTokens(entry, indent)...)
+	}
+	result = append(result, closeBrace)
+
+	body.SetAttributeRaw(name, result)
+	return nil
+}
+
+// insertBefore reports whether a missing key belongs before an existing key,
+// based on the order of the entries tfskel renders
+func insertBefore(missingKey, existingKey string, entries []objectEntry) bool {
+	missingPos, existingPos := -1, -1
+	for i, entry := range entries {
+		switch entry.key {
+		case missingKey:
+			missingPos = i
+		case existingKey:
+			existingPos = i
+		}
+	}
+	return existingPos != -1 && missingPos < existingPos
+}
+
+// splitObjectItems splits the tokens between the braces of an object into lines
+func splitObjectItems(tokens hclwrite.Tokens) []objectItem {
+	var items []objectItem
+	var current hclwrite.Tokens
+	depth := 0
+
+	for _, token := range tokens {
+		current = append(current, token)
+		switch token.Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOBrack, hclsyntax.TokenOParen, hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen, hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		}
+		if depth == 0 && endsLine(hclwrite.Tokens{token}) {
+			items = append(items, newObjectItem(current))
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		items = append(items, newObjectItem(current))
+	}
+	return items
+}
+
+// newObjectItem identifies the key and value of an object line
+func newObjectItem(tokens hclwrite.Tokens) objectItem {
+	item := objectItem{tokens: tokens}
+
+	keyEnd := 0
+	switch {
+	case len(tokens) > 0 && tokens[0].Type == hclsyntax.TokenIdent:
+		item.key, keyEnd = string(tokens[0].Bytes), 1
+	case len(tokens) > 2 && tokens[0].Type == hclsyntax.TokenOQuote && tokens[1].Type == hclsyntax.TokenQuotedLit && tokens[2].Type == hclsyntax.TokenCQuote:
+		item.key, keyEnd = string(tokens[1].Bytes), 3
+	default:
+		return item
+	}
+	if len(tokens) <= keyEnd+1 || (tokens[keyEnd].Type != hclsyntax.TokenEqual && tokens[keyEnd].Type != hclsyntax.TokenColon) {
+		return objectItem{tokens: tokens}
+	}
+
+	end := len(tokens)
+	for end > keyEnd+1 && endsLine(tokens[end-1:end]) {
+		end--
+	}
+	if end == keyEnd+1 {
+		return objectItem{tokens: tokens}
+	}
+	item.value = [2]int{keyEnd + 1, end}
+	return item
+}
+
+// endsLine reports whether the last token terminates an object line
+func endsLine(tokens hclwrite.Tokens) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1]
+	switch last.Type {
+	case hclsyntax.TokenNewline, hclsyntax.TokenComma:
+		return true
+	case hclsyntax.TokenComment:
+		return strings.HasSuffix(string(last.Bytes), "\n")
+	default:
+		return false
+	}
+}
+
+// objectEntryTokens builds the tokens of a new "key = value" object line
+func objectEntryTokens(entry objectEntry, indent int) hclwrite.Tokens {
+	var tokens hclwrite.Tokens
+	if hclsyntax.ValidIdentifier(entry.key) {
+		tokens = hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte(entry.key)}}
+	} else {
+		tokens = hclwrite.TokensForValue(cty.StringVal(entry.key))
+	}
+	tokens[0].SpacesBefore = indent
+
+	value := hclwrite.TokensForValue(cty.StringVal(entry.value))
+	value[0].SpacesBefore = 1
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte("="), SpacesBefore: 1})
+	tokens = append(tokens, value...)
+	return append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+}
+
+// replaceMetadataLines replaces the "## tfskel-<key>: ..." line of content with the one from rendered
+func replaceMetadataLines(content []byte, rendered, metadataKey string) ([]byte, error) {
+	re := regexp.MustCompile(fmt.Sprintf(`(?m)^##\s*tfskel-%s:.*$`, regexp.QuoteMeta(metadataKey)))
+
+	line := re.FindString(rendered)
+	if line == "" {
+		return content, nil
+	}
+	if !re.Match(content) {
+		return nil, fmt.Errorf("%w: no tfskel-%s comment", errNotUpdatable, metadataKey)
+	}
+	return re.ReplaceAllLiteral(content, []byte(line)), nil
+}
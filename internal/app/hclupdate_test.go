@@ -0,0 +1,342 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+func TestUpdateBackendContent(t *testing.T) {
+	t.Run("replaces only the bucket and keeps hand edits", func(t *testing.T) {
+		current := `## tfskel-metadata: {"bucket": "old-bucket"}
+
+terraform {
+  backend "s3" {
+    bucket              = "old-bucket" # shared state bucket
+    key                 = "custom/terraform.tfstate"
+    region              = "eu-central-1"
+    dynamodb_table = "locks"
+  }
+}
+`
+		rendered := `## tfskel-metadata: {"bucket": "new-bucket"}`
+		expected := `## tfskel-metadata: {"bucket": "new-bucket"}
+
+terraform {
+  backend "s3" {
+    bucket              = "new-bucket" # shared state bucket
+    key                 = "custom/terraform.tfstate"
+    region              = "eu-central-1"
+    dynamodb_table = "locks"
+  }
+}
+`
+		updated, err := updateBackendContent([]byte(current), rendered, &templates.Data{S3BucketName: "new-bucket"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(updated))
+	})
+
+	t.Run("adds and removes optional http settings", func(t *testing.T) {
+		current := `## tfskel-metadata: {"address": "https://a", "lock_address": "https://a/lock", "unlock_address": ""}
+terraform {
+  backend "http" {
+    address      = "https://a"
+    lock_address = "https://a/lock"
+    retry_max    = 5
+  }
+}
+`
+		data := &templates.Data{
+			BackendType: config.BackendHTTP,
+			Backend:     map[string]string{"address": "https://b", "unlock_address": "https://b/lock"},
+		}
+		expected := `## tfskel-metadata: {"address": "https://b"}
+terraform {
+  backend "http" {
+    address      = "https://b"
+    retry_max    = 5
+    unlock_address = "https://b/lock"
+  }
+}
+`
+		updated, err := updateBackendContent([]byte(current), `## tfskel-metadata: {"address": "https://b"}`, data)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(updated))
+	})
+
+	t.Run("updates nested cloud workspace name", func(t *testing.T) {
+		current := `## tfskel-metadata: {"organization": "acme", "hostname": "", "project": "", "workspace": "old"}
+terraform {
+  cloud {
+    organization = "acme"
+
+    workspaces {
+      name    = "old"
+      tags    = ["team:platform"]
+    }
+  }
+}
+`
+		data := &templates.Data{
+			BackendType: config.BackendCloud,
+			Backend:     map[string]string{"organization": "acme", "workspace": "new"},
+		}
+		updated, err := updateBackendContent([]byte(current), `## tfskel-metadata: {"workspace": "new"}`, data)
+		require.NoError(t, err)
+		assert.Contains(t, string(updated), `      name    = "new"`)
+		assert.Contains(t, string(updated), `      tags    = ["team:platform"]`)
+	})
+
+	errorCases := []struct {
+		name    string
+		current string
+		data    *templates.Data
+	}{
+		{
+			name:    "invalid HCL",
+			current: "## tfskel-metadata: {}\nterraform {\n",
+			data:    &templates.Data{S3BucketName: "b"},
+		},
+		{
+			name:    "backend type changed",
+			current: "## tfskel-metadata: {}\nterraform {\n  backend \"s3\" {\n    bucket = \"b\"\n  }\n}\n",
+			data:    &templates.Data{BackendType: config.BackendLocal, Backend: map[string]string{"path": "p"}},
+		},
+		{
+			name:    "metadata comment missing",
+			current: "terraform {\n  backend \"s3\" {\n    bucket = \"b\"\n  }\n}\n",
+			data:    &templates.Data{S3BucketName: "b"},
+		},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := updateBackendContent([]byte(tt.current), `## tfskel-metadata: {"bucket": "b"}`, tt.data)
+			assert.ErrorIs(t, err, errNotUpdatable)
+		})
+	}
+}
+
+func TestUpdateVersionsContent(t *testing.T) {
+	t.Run("keeps extra providers and hand added tags", func(t *testing.T) {
+		current := `## tfskel-metadata: {"tf_ver": "~> 1.13", "aws_provider_ver": "~> 6.0"}
+## tfskel-tags: {"team": "platform", "old": "x", }
+
+terraform {
+  required_version = "~> 1.13"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 6.0"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "~> 3.0"
+    }
+  }
+}
+
+provider "aws" {
+  region = basename(dirname(path.cwd))
+
+  default_tags {
+    tags = {
+      old  = "x"
+      team = "platform"
+      cost_center = "1234" # added by hand
+      env  = "dev"
+      app  = "myapp"
+    }
+  }
+}
+
+provider "aws" {
+  alias  = "us"
+  region = "us-east-1"
+}
+`
+		data := &templates.Data{
+			Env:                "dev",
+			AppDir:             "myapp",
+			TerraformVersion:   "~> 1.14",
+			AWSProviderVersion: "~> 6.2",
+			DefaultTags:        map[string]string{"team": "sre", "owner": "ops"},
+		}
+		rendered := `## tfskel-metadata: {"tf_ver": "~> 1.14", "aws_provider_ver": "~> 6.2"}
+## tfskel-tags: {"owner": "ops", "team": "sre", }`
+		expected := `## tfskel-metadata: {"tf_ver": "~> 1.14", "aws_provider_ver": "~> 6.2"}
+## tfskel-tags: {"owner": "ops", "team": "sre", }
+
+terraform {
+  required_version = "~> 1.14"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 6.2"
+    }
+    random = {
+      source  = "hashicorp/random"
+      version = "~> 3.0"
+    }
+  }
+}
+
+provider "aws" {
+  region = basename(dirname(path.cwd))
+
+  default_tags {
+    tags = {
+      owner = "ops"
+      team = "sre"
+      cost_center = "1234" # added by hand
+      env  = "dev"
+      app  = "myapp"
+    }
+  }
+}
+
+provider "aws" {
+  alias  = "us"
+  region = "us-east-1"
+}
+`
+		updated, err := updateVersionsContent([]byte(current), rendered, data)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(updated))
+	})
+
+	t.Run("single line tags object is not updatable", func(t *testing.T) {
+		current := `## tfskel-metadata: {}
+provider "aws" {
+  default_tags {
+    tags = { env = "dev" }
+  }
+}
+`
+		_, err := updateVersionsContent([]byte(current), "", &templates.Data{Env: "stg"})
+		assert.ErrorIs(t, err, errNotUpdatable)
+	})
+}
+
+// TestUpdateContent_MatchesRender checks that updating a generated file gives the same
+// result as rendering it from scratch, for every supported cloud and backend
+func TestUpdateContent_MatchesRender(t *testing.T) {
+	renderer, err := templates.NewRenderer()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		template string
+		update   func([]byte, string, *templates.Data) ([]byte, error)
+		before   templates.Data
+		after    templates.Data
+	}{
+		{
+			name:     "aws versions with tag changes",
+			template: "tf/versions.tf.tmpl",
+			update:   updateVersionsContent,
+			before: templates.Data{Env: "dev", AppDir: "app", TerraformVersion: "~> 1.13", AWSProviderVersion: "~> 6.0",
+				DefaultTags: map[string]string{"b": "1", "d": "2"}},
+			after: templates.Data{Env: "dev", AppDir: "app", TerraformVersion: "~> 1.14", AWSProviderVersion: "~> 6.1",
+				DefaultTags: map[string]string{"a": "0", "c": "3", "d": "4"}},
+		},
+		{
+			name:     "google versions",
+			template: "tf/versions.tf.tmpl",
+			update:   updateVersionsContent,
+			before: templates.Data{Cloud: config.CloudGoogle, Env: "dev", AppDir: "app", TerraformVersion: "~> 1.13", GoogleProviderVersion: "~> 7.0",
+				ProjectID: "p", DefaultTags: map[string]string{"team": "a"}},
+			after: templates.Data{Cloud: config.CloudGoogle, Env: "dev", AppDir: "app", TerraformVersion: "~> 1.13", GoogleProviderVersion: "~> 7.1",
+				ProjectID: "p", DefaultTags: map[string]string{"team": "b"}},
+		},
+		{
+			name:     "azurerm versions",
+			template: "tf/versions.tf.tmpl",
+			update:   updateVersionsContent,
+			before: templates.Data{Cloud: config.CloudAzureRM, Env: "dev", AppDir: "app", TerraformVersion: "~> 1.13", AzureRMProviderVersion: "~> 4.0",
+				SubscriptionID: "s"},
+			after: templates.Data{Cloud: config.CloudAzureRM, Env: "dev", AppDir: "app", TerraformVersion: "~> 1.13", AzureRMProviderVersion: "~> 4.1",
+				SubscriptionID: "s", DefaultTags: map[string]string{"team": "b"}},
+		},
+		{
+			name:     "s3 backend",
+			template: "tf/backend.tf.tmpl",
+			update:   updateBackendContent,
+			before:   templates.Data{Env: "dev", AppDir: "app", Region: "eu-central-1", S3BucketName: "old", AccountID: "1"},
+			after:    templates.Data{Env: "dev", AppDir: "app", Region: "eu-central-1", S3BucketName: "new", AccountID: "1"},
+		},
+		{
+			name:     "azurerm backend",
+			template: "tf/backend.tf.tmpl",
+			update:   updateBackendContent,
+			before: templates.Data{BackendType: config.BackendAzureRM, Backend: map[string]string{
+				"resource_group_name": "rg", "storage_account_name": "sa", "container_name": "old", "key": "k", "subscription_id": "s"}},
+			after: templates.Data{BackendType: config.BackendAzureRM, Backend: map[string]string{
+				"resource_group_name": "rg", "storage_account_name": "sa", "container_name": "new", "key": "k", "subscription_id": "s"}},
+		},
+		{
+			name:     "gcs backend",
+			template: "tf/backend.tf.tmpl",
+			update:   updateBackendContent,
+			before:   templates.Data{BackendType: config.BackendGCS, Backend: map[string]string{"bucket": "old", "prefix": "p"}},
+			after:    templates.Data{BackendType: config.BackendGCS, Backend: map[string]string{"bucket": "new", "prefix": "q"}},
+		},
+		{
+			name:     "cloud backend",
+			template: "tf/backend.tf.tmpl",
+			update:   updateBackendContent,
+			before:   templates.Data{BackendType: config.BackendCloud, Backend: map[string]string{"organization": "acme", "project": "p", "workspace": "old"}},
+			after:    templates.Data{BackendType: config.BackendCloud, Backend: map[string]string{"organization": "acme", "project": "q", "workspace": "new"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, err := renderer.Render(tt.template, &tt.before)
+			require.NoError(t, err)
+			expected, err := renderer.Render(tt.template, &tt.after)
+			require.NoError(t, err)
+
+			updated, err := tt.update([]byte(current), expected, &tt.after)
+			require.NoError(t, err)
+			assert.Equal(t, expected, string(updated))
+		})
+	}
+}
+
+func TestGenerator_PreservesHandEdits(t *testing.T) {
+	newConfig := func(tfVersion string) *config.Config {
+		return &config.Config{
+			TerraformVersion: tfVersion,
+			Provider: &config.Provider{
+				AWS: &config.AWSProvider{
+					Version:        "~> 6.0",
+					AccountMapping: map[string]string{"dev": "123456789012"},
+				},
+			},
+			Backend: &config.Backend{S3: &config.S3Backend{BucketName: "bucket"}},
+		}
+	}
+	versionsPath := filepath.Join("envs", "dev", "us-east-1", "myapp", "versions.tf")
+	filesystem := fs.NewMemoryFileSystem()
+
+	require.NoError(t, NewGenerator(newConfig("~> 1.13"), filesystem, logger.New(false)).Run("dev", "us-east-1", "myapp"))
+	content, err := filesystem.ReadFile(versionsPath)
+	require.NoError(t, err)
+	extraProvider := "\nprovider \"aws\" {\n  alias  = \"us\"\n  region = \"us-east-1\"\n}\n"
+	require.NoError(t, filesystem.WriteFile(versionsPath, append(content, extraProvider...), 0644))
+
+	require.NoError(t, NewGenerator(newConfig("~> 1.14"), filesystem, logger.New(false)).Run("dev", "us-east-1", "myapp"))
+	content, err = filesystem.ReadFile(versionsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `required_version = "~> 1.14"`)
+	assert.Contains(t, string(content), extraProvider)
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+// ErrHookFailed indicates a hook with the fail policy exited with an error
+var ErrHookFailed = errors.New("hook failed")
+
+// CommandRunner runs a command in dir and returns its combined output
+type CommandRunner func(dir, name string, args ...string) ([]byte, error)
+
+// ExecCommand runs a command with os/exec, without a shell
+func ExecCommand(dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...) //nolint:gosec // hooks are user configured commands
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// HookRunner runs configured hooks and reports their output through the logger
+type HookRunner struct {
+	run CommandRunner
+	log *logger.Logger
+}
+
+// NewHookRunner creates a HookRunner that executes commands with run
+func NewHookRunner(run CommandRunner, log *logger.Logger) *HookRunner {
+	return &HookRunner{run: run, log: log}
+}
+
+// Run executes hooks in order in dir, rendering their arguments against data
+// A failing hook with the warn policy is logged and the remaining hooks still run;
+// with the fail policy (the default) Run stops and returns ErrHookFailed
+func (h *HookRunner) Run(stage string, hooks []config.Hook, dir string, data *templates.Data) error {
+	for i, hook := range hooks {
+		name := hook.Name
+		if name == "" {
+			name = fmt.Sprintf("%s[%d]", stage, i)
+		}
+
+		if len(hook.Command) == 0 {
+			return fmt.Errorf("%s: %w", name, config.ErrHookCommandRequired)
+		}
+		command, err := renderHookCommand(name, hook.Command, data)
+		if err != nil {
+			return err
+		}
+
+		h.log.Infof("Running hook %s: %s", name, strings.Join(command, " "))
+		output, err := h.run(dir, command[0], command[1:]...)
+		if err == nil {
+			h.logOutput(name, output, h.log.Debugf)
+			h.log.Successf("Hook %s completed", name)
+			continue
+		}
+
+		if hook.OnFailure == config.HookWarn {
+			h.logOutput(name, output, h.log.Warnf)
+			h.log.Warnf("Hook %s failed, continuing: %v", name, err)
+			continue
+		}
+		h.logOutput(name, output, h.log.Errorf)
+		return fmt.Errorf("%w: %s in %s: %v", ErrHookFailed, name, dir, err)
+	}
+	return nil
+}
+
+// logOutput logs every line of a hook's output prefixed with the hook name
+func (h *HookRunner) logOutput(name string, output []byte, logf func(format string, args ...any)) {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.TrimSpace(line) != "" {
+			logf("  [%s] %s", name, line)
+		}
+	}
+}
+
+// renderHookCommand renders every element of a hook command as a template
+func renderHookCommand(name string, command []string, data *templates.Data) ([]string, error) {
+	rendered := make([]string, len(command))
+	for i, arg := range command {
+		value, err := renderValue(name, arg, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render hook %s: %w", name, err)
+		}
+		rendered[i] = value
+	}
+	return rendered, nil
+}
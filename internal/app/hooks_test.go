@@ -0,0 +1,135 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+// fakeRunner records the commands it was asked to run and fails the ones named in failures
+type fakeRunner struct {
+	calls    [][]string
+	dirs     []string
+	failures map[string]bool
+}
+
+func (f *fakeRunner) run(dir, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, append([]string{name}, args...))
+	f.dirs = append(f.dirs, dir)
+	if f.failures[name] {
+		return []byte("boom\n"), errors.New("exit status 1")
+	}
+	return []byte("ok\n"), nil
+}
+
+func TestHookRunner_Run(t *testing.T) {
+	data := &templates.Data{Env: "dev", Region: "us-east-1", AppDir: "payments", TerraformVersion: "1.13.1"}
+
+	tests := []struct {
+		name          string
+		hooks         []config.Hook
+		failures      map[string]bool
+		expectedCalls [][]string
+		expectedLog   string
+		wantErr       error
+	}{
+		{
+			name: "renders arguments and runs every hook",
+			hooks: []config.Hook{
+				{Name: "fmt", Command: []string{"terraform", "fmt"}},
+				{Command: []string{"echo", "{{.Env}}/{{.Region}}/{{.AppDir}}"}},
+			},
+			expectedCalls: [][]string{{"terraform", "fmt"}, {"echo", "dev/us-east-1/payments"}},
+			expectedLog:   "Hook post_generate[1] completed",
+		},
+		{
+			name: "warn policy continues after a failure",
+			hooks: []config.Hook{
+				{Name: "init", Command: []string{"terraform", "init"}, OnFailure: config.HookWarn},
+				{Name: "lint", Command: []string{"tflint"}},
+			},
+			failures:      map[string]bool{"terraform": true},
+			expectedCalls: [][]string{{"terraform", "init"}, {"tflint"}},
+			expectedLog:   "[init] boom",
+		},
+		{
+			name: "fail policy stops at the first failure",
+			hooks: []config.Hook{
+				{Name: "init", Command: []string{"terraform", "init"}},
+				{Name: "lint", Command: []string{"tflint"}},
+			},
+			failures:      map[string]bool{"terraform": true},
+			expectedCalls: [][]string{{"terraform", "init"}},
+			expectedLog:   "[init] boom",
+			wantErr:       ErrHookFailed,
+		},
+		{
+			name:    "hook without command",
+			hooks:   []config.Hook{{Name: "empty"}},
+			wantErr: config.ErrHookCommandRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{failures: tt.failures}
+			var out bytes.Buffer
+			hooks := NewHookRunner(runner.run, logger.NewWithWriters(false, &out, &out))
+
+			err := hooks.Run("post_generate", tt.hooks, "envs/dev/us-east-1/payments", data)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedCalls, runner.calls)
+			assert.Contains(t, out.String(), tt.expectedLog)
+		})
+	}
+}
+
+func TestGenerator_PostGenerateHooks(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+		},
+		Backend: &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Hooks:   &config.Hooks{PostGenerate: []config.Hook{{Command: []string{"terraform", "fmt"}}}},
+	}
+	appPath := filepath.Join("envs", "dev", "us-east-1", "payments")
+
+	t.Run("runs in the app directory", func(t *testing.T) {
+		runner := &fakeRunner{}
+		gen := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false))
+		gen.SetHookRunner(NewHookRunner(runner.run, logger.New(false)))
+
+		require.NoError(t, gen.Run("dev", "us-east-1", "payments"))
+		assert.Equal(t, [][]string{{"terraform", "fmt"}}, runner.calls)
+		assert.Equal(t, []string{appPath}, runner.dirs)
+	})
+
+	t.Run("skipped in dry-run mode", func(t *testing.T) {
+		runner := &fakeRunner{}
+		gen := NewGenerator(cfg, fs.NewDryRunFileSystem(fs.NewMemoryFileSystem()), logger.New(false))
+		gen.SetHookRunner(NewHookRunner(runner.run, logger.New(false)))
+
+		require.NoError(t, gen.Run("dev", "us-east-1", "payments"))
+		assert.Empty(t, runner.calls)
+	})
+
+	t.Run("disabled with a nil runner", func(t *testing.T) {
+		gen := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false))
+		gen.SetHookRunner(nil)
+		require.NoError(t, gen.Run("dev", "us-east-1", "payments"))
+	})
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"path/filepath"
+
+	"github.com/ishuar/tfskel/internal/drift"
+)
+
+// InventoryEntry describes one app directory of the layout, envs/<env>/<region>/<app> by default
+type InventoryEntry struct {
+	App              string `json:"app"`
+	Env              string `json:"env"`
+	Region           string `json:"region"`
+	ShortRegion      string `json:"shortRegion"`
+	Path             string `json:"path"`
+	Backend          string `json:"backend"` // Backend type from backend.tf, empty when it cannot be read
+	Bucket           string `json:"bucket"`  // Bucket, azurerm container or HCP Terraform organization, see StateLocation.Container
+	Key              string `json:"key"`     // Key, gcs prefix or HCP Terraform workspace
+	TerraformVersion string `json:"terraformVersion"`
+	AWSProvider      string `json:"awsProvider"`
+	Workflows        bool   `json:"workflows"` // At least one app workflow exists in .github/workflows
+	Managed          bool   `json:"managed"`   // backend.tf or versions.tf carries tfskel metadata
+}
+
+// Inventory walks the app directories of layout.app_path and describes every one of them
+// Version constraints are read with drift.Detector, so the directories are scanned on disk
+func (g *Generator) Inventory() ([]InventoryEntry, error) {
+	if err := g.loadRenderer(); err != nil {
+		return nil, err
+	}
+
+	apps, err := g.config.FindApps(visibleDirLister(g.fs))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []InventoryEntry
+	for _, found := range apps {
+		entries = append(entries, g.inventoryEntry(found.Env, found.Region, found.App))
+	}
+	return entries, nil
+}
+
+// inventoryEntry describes a single app directory
+func (g *Generator) inventoryEntry(env, region, appDir string) InventoryEntry {
+	appPath := g.config.AppPath(env, region, appDir)
+	entry := InventoryEntry{
+		App:         appDir,
+		Env:         env,
+		Region:      region,
+		ShortRegion: g.config.ShortRegion(region),
+		Path:        filepath.ToSlash(appPath),
+	}
+
+	if state := g.readState(appPath); state != nil {
+		entry.Backend = state.Type
+		entry.Bucket, entry.Key = state.Container()
+	}
+
+	if infos, err := drift.NewDetector(appPath).ScanDirectory(); err == nil {
+		for _, info := range infos {
+			if info.TerraformVersion == "" && len(info.Providers) == 0 {
+				continue
+			}
+			entry.TerraformVersion = info.TerraformVersion
+			entry.AWSProvider = info.Providers["aws"].Version
+			break
+		}
+	} else {
+		g.log.Debugf("Could not scan %s for versions: %v", appPath, err)
+	}
+
+	if data, err := g.prepareTemplateData(env, region, appDir); err == nil {
+		for _, workflow := range g.workflowPaths(appPath, data) {
+			if g.fs.FileExists(workflow) {
+				entry.Workflows = true
+				break
+			}
+		}
+	}
+
+	for _, name := range []string{"backend.tf", "versions.tf"} {
+		content, err := g.fs.ReadFile(filepath.Join(appPath, name))
+		if err != nil {
+			continue
+		}
+		if _, err := extractMetadata(string(content), "metadata"); err == nil {
+			entry.Managed = true
+			break
+		}
+	}
+	return entry
+}
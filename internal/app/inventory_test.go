@@ -0,0 +1,139 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+func TestGenerator_Inventory(t *testing.T) {
+	// drift.Detector reads the app directories from disk
+	t.Chdir(t.TempDir())
+
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{Version: "~> 6.2", AccountMapping: map[string]string{"dev": "123456789012"}},
+		},
+		Backend:  &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Generate: &config.Generate{GithubWorkflows: &config.GithubWorkflows{Create: true}},
+	}
+	filesystem := fs.NewOSFileSystem()
+	require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+
+	legacy := filepath.Join("envs", "prd", "eu-central-1", "legacy")
+	require.NoError(t, os.MkdirAll(filepath.Join(legacy, ".terraform"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacy, "main.tf"), []byte("terraform {\n  required_version = \">= 1.5\"\n}\n"), 0644))
+
+	entries, err := NewGenerator(cfg, filesystem, logger.New(false)).Inventory()
+	require.NoError(t, err)
+
+	expected := []InventoryEntry{
+		{
+			App: "payments", Env: "dev", Region: "us-east-1", ShortRegion: "use1", Path: "envs/dev/us-east-1/payments",
+			Backend: "s3", Bucket: "tfstate", Key: "payments-dev-us-east-1/terraform.tfstate",
+			TerraformVersion: "~> 1.13", AWSProvider: "~> 6.2", Workflows: true, Managed: true,
+		},
+		{
+			App: "legacy", Env: "prd", Region: "eu-central-1", ShortRegion: "euc1", Path: "envs/prd/eu-central-1/legacy",
+			TerraformVersion: ">= 1.5",
+		},
+	}
+	assert.Equal(t, expected, entries)
+}
+
+func TestGenerator_InventoryLayout(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cfg := &config.Config{
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+		},
+		Layout: &config.Layout{AppPath: "{{.AppDir}}/{{.Env}}-{{.Region}}"},
+	}
+	filesystem := fs.NewOSFileSystem()
+	require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+	assert.FileExists(t, filepath.Join("payments", "dev-us-east-1", "backend.tf"))
+
+	entries, err := NewGenerator(cfg, filesystem, logger.New(false)).Inventory()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "payments", entries[0].App)
+	assert.Equal(t, "dev", entries[0].Env)
+	assert.Equal(t, "us-east-1", entries[0].Region)
+	assert.Equal(t, "payments/dev-us-east-1", entries[0].Path)
+	assert.True(t, entries[0].Managed)
+}
+
+func TestGenerator_InventoryBackends(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend *config.Backend
+		bucket  string
+		key     string
+	}{
+		{
+			name:    "s3",
+			backend: &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+			bucket:  "tfstate",
+			key:     "payments-dev-us-east-1/terraform.tfstate",
+		},
+		{
+			name:    "gcs",
+			backend: &config.Backend{Type: config.BackendGCS, GCS: &config.GCSBackend{Bucket: "tfstate"}},
+			bucket:  "tfstate",
+			key:     "payments-dev-us-east-1",
+		},
+		{
+			name: "azurerm",
+			backend: &config.Backend{Type: config.BackendAzureRM, AzureRM: &config.AzureRMBackend{
+				ResourceGroupName: "rg-tfstate", StorageAccountName: "sttfstate", ContainerName: "tfstate",
+			}},
+			bucket: "tfstate",
+			key:    "payments-dev-us-east-1/terraform.tfstate",
+		},
+		{
+			name:    "cloud",
+			backend: &config.Backend{Type: config.BackendCloud, Cloud: &config.CloudBackend{Organization: "acme"}},
+			bucket:  "acme",
+			key:     "payments-dev-us-east-1",
+		},
+		{
+			name:    "http",
+			backend: &config.Backend{Type: config.BackendHTTP, HTTP: &config.HTTPBackend{Address: "https://state.example.com/payments"}},
+		},
+		{
+			name:    "local",
+			backend: &config.Backend{Type: config.BackendLocal, Local: &config.LocalBackend{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+
+			cfg := &config.Config{
+				Provider: &config.Provider{
+					AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Backend: tt.backend,
+			}
+			filesystem := fs.NewOSFileSystem()
+			require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+
+			entries, err := NewGenerator(cfg, filesystem, logger.New(false)).Inventory()
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.name, entries[0].Backend)
+			assert.Equal(t, tt.bucket, entries[0].Bucket)
+			assert.Equal(t, tt.key, entries[0].Key)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+// ManifestFile is the name of the generated-file manifest at the project root
+const ManifestFile = ".tfskel.lock"
+
+// manifestVersion is the format version written to new manifests
+const manifestVersion = 1
+
+// ErrInvalidManifest indicates the manifest file could not be parsed
+var ErrInvalidManifest = errors.New("invalid " + ManifestFile)
+
+// ManifestEntry records how a generated file was produced
+// Env, Region and App are empty for the project files written by tfskel init
+type ManifestEntry struct {
+	Template string `json:"template"` // Template key, e.g. tf/backend.tf.tmpl
+	Source   string `json:"source"`   // Where the template was loaded from
+	Env      string `json:"env"`
+	Region   string `json:"region"`
+	App      string `json:"app"`
+	Rendered string `json:"rendered"` // Hash of the rendered template
+	Hash     string `json:"hash"`     // Hash of the file content after generation
+}
+
+// Manifest lists every file generated by tfskel, keyed by slash separated path
+type Manifest struct {
+	Version int                      `json:"version"`
+	Files   map[string]ManifestEntry `json:"files"`
+}
+
+// LoadManifest reads the manifest at path, returning an empty manifest when it does not exist
+func LoadManifest(filesystem fs.FileSystem, path string) (*Manifest, error) {
+	manifest := &Manifest{Version: manifestVersion, Files: make(map[string]ManifestEntry)}
+	if !filesystem.FileExists(path) {
+		return manifest, nil
+	}
+
+	content, err := filesystem.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]ManifestEntry)
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest to path
+func (m *Manifest) Save(filesystem fs.FileSystem, path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", ManifestFile, err)
+	}
+	if err := filesystem.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// TrackFile records a file written outside of a generator run, such as the project files of tfskel init
+// path is relative to the project root
+func (m *Manifest) TrackFile(path, tmplPath, source, content string) {
+	m.Files[filepath.ToSlash(filepath.Clean(path))] = ManifestEntry{
+		Template: tmplPath,
+		Source:   source,
+		Rendered: contentHash(content),
+		Hash:     contentHash(content),
+	}
+}
+
+// contentHash returns the sha256 hash of content in the form sha256:<hex>
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// manifestPath is the path of the manifest of the project
+var manifestPath = filepath.Join(projectRoot, ManifestFile)
+
+// loadManifest loads the manifest of the project once per generator
+func (g *Generator) loadManifest() error {
+	if g.manifest != nil {
+		return nil
+	}
+	manifest, err := LoadManifest(g.fs, manifestPath)
+	if err != nil {
+		return err
+	}
+	g.manifest = manifest
+	return nil
+}
+
+// trackFile records a generated file in the manifest
+func (g *Generator) trackFile(path, tmplPath string, data *templates.Data, rendered, written string) {
+	if g.manifest == nil {
+		return
+	}
+	key := filepath.ToSlash(filepath.Clean(path))
+	g.manifest.Files[key] = ManifestEntry{
+		Template: tmplPath,
+		Source:   g.renderer.GetTemplateSource(tmplPath),
+		Env:      data.Env,
+		Region:   data.Region,
+		App:      data.AppDir,
+		Rendered: contentHash(rendered),
+		Hash:     contentHash(written),
+	}
+	g.tracked = append(g.tracked, key)
+}
+
+// saveManifest refreshes the hashes of the files written in this run and writes the manifest
+// Hashes are read back from disk so changes made by post_generate hooks (e.g. terraform fmt) count as generated
+// The manifest is not written in dry-run mode
+func (g *Generator) saveManifest() error {
+	if g.manifest == nil || len(g.tracked) == 0 {
+		return nil
+	}
+
+	for _, key := range g.tracked {
+		entry := g.manifest.Files[key]
+		if content, err := g.fs.ReadFile(filepath.FromSlash(key)); err == nil {
+			entry.Hash = contentHash(string(content))
+			g.manifest.Files[key] = entry
+		}
+	}
+	g.tracked = nil
+
+	return g.saveManifestFile()
+}
+
+// saveManifestFile writes the manifest unless running against a recording (dry-run) filesystem
+func (g *Generator) saveManifestFile() error {
+	if _, ok := g.fs.(fs.Recorder); ok {
+		return nil
+	}
+	g.manifest.Version = manifestVersion
+	return g.manifest.Save(g.fs, manifestPath)
+}
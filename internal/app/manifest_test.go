@@ -0,0 +1,83 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+func TestLoadManifest(t *testing.T) {
+	t.Run("missing manifest is empty", func(t *testing.T) {
+		manifest, err := LoadManifest(fs.NewMemoryFileSystem(), ManifestFile)
+		require.NoError(t, err)
+		assert.Equal(t, manifestVersion, manifest.Version)
+		assert.Empty(t, manifest.Files)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		filesystem := fs.NewMemoryFileSystem()
+		manifest := &Manifest{Version: manifestVersion, Files: map[string]ManifestEntry{
+			"envs/dev/us-east-1/app/backend.tf": {Template: "tf/backend.tf.tmpl", Env: "dev", Hash: contentHash("x")},
+		}}
+		require.NoError(t, manifest.Save(filesystem, ManifestFile))
+
+		loaded, err := LoadManifest(filesystem, ManifestFile)
+		require.NoError(t, err)
+		assert.Equal(t, manifest, loaded)
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, filesystem.WriteFile(ManifestFile, []byte("{"), 0644))
+
+		_, err := LoadManifest(filesystem, ManifestFile)
+		assert.ErrorIs(t, err, ErrInvalidManifest)
+	})
+}
+
+func TestGenerator_Manifest(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+		},
+		Backend: &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Hooks:   &config.Hooks{PostGenerate: []config.Hook{{Command: []string{"terraform", "fmt"}}}},
+	}
+	appPath := filepath.Join("envs", "dev", "us-east-1", "payments")
+	backendPath := filepath.Join(appPath, "backend.tf")
+
+	t.Run("records generated files with the content after hooks", func(t *testing.T) {
+		filesystem := fs.NewMemoryFileSystem()
+		gen := NewGenerator(cfg, filesystem, logger.New(false))
+		// A formatting hook rewrites backend.tf
+		gen.SetHookRunner(NewHookRunner(func(dir, _ string, _ ...string) ([]byte, error) {
+			return nil, filesystem.WriteFile(filepath.Join(dir, "backend.tf"), []byte("formatted\n"), 0644)
+		}, logger.New(false)))
+		require.NoError(t, gen.Run("dev", "us-east-1", "payments"))
+
+		manifest, err := LoadManifest(filesystem, ManifestFile)
+		require.NoError(t, err)
+		entry, ok := manifest.Files["envs/dev/us-east-1/payments/backend.tf"]
+		require.True(t, ok)
+		assert.Equal(t, "tf/backend.tf.tmpl", entry.Template)
+		assert.Equal(t, "embedded:tf/backend.tf.tmpl", entry.Source)
+		assert.Equal(t, []string{"dev", "us-east-1", "payments"}, []string{entry.Env, entry.Region, entry.App})
+		assert.Equal(t, contentHash("formatted\n"), entry.Hash)
+		assert.NotEqual(t, entry.Hash, entry.Rendered)
+		assert.Contains(t, manifest.Files, "envs/dev/us-east-1/payments/versions.tf")
+	})
+
+	t.Run("not written in dry-run mode", func(t *testing.T) {
+		base := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(cfg, fs.NewDryRunFileSystem(base), logger.New(false)).Run("dev", "us-east-1", "payments"))
+		assert.False(t, base.FileExists(ManifestFile))
+		assert.False(t, base.FileExists(backendPath))
+	})
+}
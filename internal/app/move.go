@@ -0,0 +1,314 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+var (
+	// ErrMoveTargetExists indicates the destination app directory already exists
+	ErrMoveTargetExists = errors.New("destination app directory already exists")
+	// ErrMoveSameTarget indicates the destination of a move is the source itself
+	ErrMoveSameTarget = errors.New("destination is the same as the source")
+)
+
+// MoveResult describes what Move changed
+type MoveResult struct {
+	From      string
+	To        string
+	Workflows map[string]string // Old workflow path to new workflow path
+	Modified  []string          // Edited files that were moved as-is and may still reference the old location
+	OldState  *StateLocation    // State location before the move, nil when unknown
+	NewState  *StateLocation    // State location after the move, nil when unknown
+}
+
+// Move renames an app and/or moves it to another region
+// The directory is moved, files that are unchanged since generation are re-rendered for the
+// new target, the state attributes of an edited backend.tf are rewritten and the GitHub
+// workflows are renamed with their path filters updated. The remote state itself is not moved
+func (g *Generator) Move(env, region, appDir, toRegion, toAppDir string) (*MoveResult, error) {
+	if err := g.loadRenderer(); err != nil {
+		return nil, err
+	}
+
+	from := g.config.AppPath(env, region, appDir)
+	to := g.config.AppPath(env, toRegion, toAppDir)
+	switch {
+	case from == to:
+		return nil, fmt.Errorf("%w: %s", ErrMoveSameTarget, from)
+	case !g.fs.DirExists(from):
+		return nil, fmt.Errorf("%w: %s", ErrAppNotFound, from)
+	case g.fs.DirExists(to):
+		return nil, fmt.Errorf("%w: %s", ErrMoveTargetExists, to)
+	}
+	if err := g.loadManifest(); err != nil {
+		return nil, err
+	}
+
+	oldData, err := g.prepareTemplateData(env, region, appDir)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := g.prepareTemplateData(env, toRegion, toAppDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MoveResult{From: from, To: to, Workflows: make(map[string]string)}
+	result.OldState = g.readState(from)
+
+	if err := g.fs.Rename(from, to); err != nil {
+		return nil, fmt.Errorf("failed to move %s to %s: %w", from, to, err)
+	}
+	g.log.Successf("Moved %s to %s", from, to)
+
+	backendRendered, err := g.moveAppFiles(result, newData)
+	if err != nil {
+		return nil, err
+	}
+	if !backendRendered {
+		if err := g.moveBackendState(to, oldData, newData); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.moveWorkflows(result, oldData, newData); err != nil {
+		return nil, err
+	}
+
+	result.NewState = g.readState(to)
+	g.tracked = nil
+	if err := g.saveManifestFile(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// moveAppFiles re-renders the moved files that are unchanged since generation and moves their
+// manifest entries; it reports whether backend.tf was re-rendered
+func (g *Generator) moveAppFiles(result *MoveResult, newData *templates.Data) (bool, error) {
+	prefix := filepath.ToSlash(result.From) + "/"
+	backendRendered := false
+
+	for _, key := range slices.Sorted(maps.Keys(g.manifest.Files)) {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		entry := g.manifest.Files[key]
+		delete(g.manifest.Files, key)
+		newPath := filepath.Join(result.To, filepath.FromSlash(rest))
+
+		rewritten, err := g.rerenderIfClean(newPath, entry, newData)
+		if err != nil {
+			return false, err
+		}
+		if !rewritten {
+			result.Modified = append(result.Modified, newPath)
+			g.retrackFile(newPath, entry, newData)
+			continue
+		}
+		if rest == "backend.tf" {
+			backendRendered = true
+		}
+	}
+	return backendRendered, nil
+}
+
+// rerenderIfClean writes the template of a manifest entry rendered for the new target to path,
+// as long as the file was not edited since generation
+func (g *Generator) rerenderIfClean(path string, entry ManifestEntry, data *templates.Data) (bool, error) {
+	content, err := g.fs.ReadFile(path)
+	if err != nil || contentHash(string(content)) != entry.Hash {
+		return false, nil //nolint:nilerr // missing and edited files are moved as-is
+	}
+
+	templateData := g.templateData(entry.Template, templateLayout(entry.Template, g.renderer.GetFrontMatter(entry.Template)), data)
+	rendered, err := g.renderer.Render(entry.Template, &templateData)
+	if err != nil {
+		return false, nil //nolint:nilerr // templates that no longer render are moved as-is
+	}
+
+	if err := g.fs.WriteFile(path, []byte(rendered), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	g.explain(path, "rendered for the new location")
+	g.trackFile(path, entry.Template, data, rendered, rendered)
+	return true, nil
+}
+
+// retrackFile records an edited file that was moved as-is under its new path and target
+// The content hash is kept so the file is still reported as modified
+func (g *Generator) retrackFile(path string, entry ManifestEntry, data *templates.Data) {
+	entry.Env, entry.Region, entry.App = data.Env, data.Region, data.AppDir
+	templateData := g.templateData(entry.Template, templateLayout(entry.Template, g.renderer.GetFrontMatter(entry.Template)), data)
+	if rendered, err := g.renderer.Render(entry.Template, &templateData); err == nil {
+		entry.Rendered = contentHash(rendered)
+	}
+	g.manifest.Files[filepath.ToSlash(filepath.Clean(path))] = entry
+}
+
+// moveBackendState rewrites the state attributes of a backend.tf that could not be re-rendered
+func (g *Generator) moveBackendState(appPath string, oldData, newData *templates.Data) error {
+	backendPath := filepath.Join(appPath, "backend.tf")
+	content, err := g.fs.ReadFile(backendPath)
+	if err != nil {
+		return nil //nolint:nilerr // apps without backend.tf have no state to point elsewhere
+	}
+
+	oldState, err := g.renderedState(oldData)
+	if err != nil {
+		return err
+	}
+	newState, err := g.renderedState(newData)
+	if err != nil {
+		return err
+	}
+
+	updated, err := moveStateAttributes(content, oldState, newState)
+	if err != nil {
+		g.log.Warnf("Could not update backend.tf, point it at the new state location by hand: %v", err)
+		return nil
+	}
+	if string(updated) == string(content) {
+		return nil
+	}
+	if err := g.fs.WriteFile(backendPath, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write backend.tf: %w", err)
+	}
+	g.explain(backendPath, "state location updated")
+	g.log.Successf("Updated the state location in %s", backendPath)
+	return nil
+}
+
+// renderedState returns the state location the backend template renders for a target
+func (g *Generator) renderedState(data *templates.Data) (*StateLocation, error) {
+	rendered, err := g.renderer.Render("tf/backend.tf.tmpl", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render backend template: %w", err)
+	}
+	return readStateLocation([]byte(rendered))
+}
+
+// moveWorkflows renames the workflows of the app; unchanged workflows are re-rendered,
+// edited ones get their path filters and self references rewritten
+func (g *Generator) moveWorkflows(result *MoveResult, oldData, newData *templates.Data) error {
+	oldWorkflows := g.workflowPaths(result.From, oldData)
+	newWorkflows := g.workflowPaths(result.To, newData)
+
+	for _, tmplPath := range slices.Sorted(maps.Keys(oldWorkflows)) {
+		oldPath, newPath := oldWorkflows[tmplPath], newWorkflows[tmplPath]
+		if newPath == "" || !g.fs.FileExists(oldPath) {
+			continue
+		}
+
+		content, err := g.fs.ReadFile(oldPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", oldPath, err)
+		}
+		if err := g.fs.WriteFile(newPath, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", newPath, err)
+		}
+		if err := g.fs.Remove(oldPath); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", oldPath, err)
+		}
+
+		oldKey := filepath.ToSlash(oldPath)
+		entry, tracked := g.manifest.Files[oldKey]
+		delete(g.manifest.Files, oldKey)
+		rewritten := false
+		if tracked {
+			if rewritten, err = g.rerenderIfClean(newPath, entry, newData); err != nil {
+				return err
+			}
+		}
+		if !rewritten {
+			updated := strings.ReplaceAll(string(content), filepath.ToSlash(result.From), filepath.ToSlash(result.To))
+			updated = strings.ReplaceAll(updated, filepath.Base(oldPath), filepath.Base(newPath))
+			if err := g.fs.WriteFile(newPath, []byte(updated), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", newPath, err)
+			}
+			if tracked {
+				result.Modified = append(result.Modified, newPath)
+				g.retrackFile(newPath, entry, newData)
+			}
+		}
+
+		g.log.Successf("Renamed %s to %s", oldPath, newPath)
+		result.Workflows[oldPath] = newPath
+	}
+	return nil
+}
+
+// readState reads the state location from the backend.tf of an app, nil when it cannot be read
+func (g *Generator) readState(appPath string) *StateLocation {
+	content, err := g.fs.ReadFile(filepath.Join(appPath, "backend.tf"))
+	if err != nil {
+		return nil
+	}
+	state, err := readStateLocation(content)
+	if err != nil {
+		g.log.Debugf("Could not read the state location from %s: %v", appPath, err)
+		return nil
+	}
+	return state
+}
+
+// MigrationSteps returns the commands that move the remote state of a moved app to
+// the location its backend.tf now points at; lines starting with # are explanations
+func MigrationSteps(result *MoveResult) []string {
+	oldState, newState := result.OldState, result.NewState
+	switch {
+	case oldState == nil || newState == nil:
+		return []string{"# The backend configuration could not be read, move the state to the new location by hand"}
+	case oldState.String() == newState.String():
+		return []string{"# The state location did not change, no migration is needed"}
+	case oldState.Type != newState.Type:
+		return []string{fmt.Sprintf("# The backend changed from %s to %s, migrate the state by hand", oldState.Type, newState.Type)}
+	case newState.Type == config.BackendCloud:
+		return []string{
+			fmt.Sprintf("# Rename the HCP Terraform workspace %s to %s in its settings, then:", oldState.Settings["workspaces.name"], newState.Settings["workspaces.name"]),
+			"cd " + filepath.ToSlash(result.To),
+			"terraform init",
+			"terraform plan  # expect no changes",
+		}
+	}
+
+	var backendConfig []string
+	for _, name := range slices.Sorted(maps.Keys(newState.Settings)) {
+		if oldValue, ok := oldState.Settings[name]; ok && oldValue != newState.Settings[name] {
+			backendConfig = append(backendConfig, fmt.Sprintf("-backend-config=%q", name+"="+oldValue))
+		}
+	}
+
+	steps := []string{
+		fmt.Sprintf("# Copy the state from %s to %s with terraform init -migrate-state:", oldState, newState),
+		"cd " + filepath.ToSlash(result.To),
+		"terraform init -reconfigure " + strings.Join(backendConfig, " ") + "  # connect to the old state",
+		"terraform init -migrate-state  # answer yes to copy the state to the location in backend.tf",
+		"terraform plan  # expect no changes",
+	}
+
+	if newState.Type == config.BackendS3 {
+		copyCommand := fmt.Sprintf("aws s3 cp s3://%s/%s s3://%s/%s", oldState.Settings["bucket"], oldState.Settings["key"], newState.Settings["bucket"], newState.Settings["key"])
+		if oldState.Settings["region"] != newState.Settings["region"] {
+			copyCommand += fmt.Sprintf(" --source-region %s --region %s", oldState.Settings["region"], newState.Settings["region"])
+		}
+		steps = append(steps,
+			"",
+			"# Or copy the state object with the AWS CLI:",
+			copyCommand,
+			"cd "+filepath.ToSlash(result.To),
+			"terraform init -reconfigure",
+			"terraform plan  # expect no changes",
+		)
+	}
+
+	return append(steps, "", fmt.Sprintf("# Once the plan shows no changes, delete the old state at %s", oldState))
+}
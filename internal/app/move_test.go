@@ -0,0 +1,150 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+func TestGenerator_Move(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+		},
+		Backend:  &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Generate: &config.Generate{GithubWorkflows: &config.GithubWorkflows{Create: true}},
+	}
+	from := filepath.Join("envs", "dev", "us-east-1", "payments")
+	to := filepath.Join("envs", "dev", "eu-west-1", "billing")
+	oldWorkflow := filepath.Join(".github", "workflows", "payments-dev-use1-terraform.yaml")
+	newWorkflow := filepath.Join(".github", "workflows", "billing-dev-euw1-terraform.yaml")
+
+	setup := func(t *testing.T) *fs.MemoryFileSystem {
+		t.Helper()
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+		return filesystem
+	}
+
+	t.Run("re-renders unchanged files for the new target", func(t *testing.T) {
+		filesystem := setup(t)
+		require.NoError(t, filesystem.WriteFile(filepath.Join(from, ".terraform", "terraform.tfstate"), []byte("{}"), 0644))
+
+		result, err := NewGenerator(cfg, filesystem, logger.New(false)).Move("dev", "us-east-1", "payments", "eu-west-1", "billing")
+		require.NoError(t, err)
+
+		assert.False(t, filesystem.DirExists(from))
+		assert.True(t, filesystem.FileExists(filepath.Join(to, ".terraform", "terraform.tfstate")))
+		backend, err := filesystem.ReadFile(filepath.Join(to, "backend.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(backend), `"billing-dev-eu-west-1/terraform.tfstate"`)
+
+		assert.False(t, filesystem.FileExists(oldWorkflow))
+		workflow, err := filesystem.ReadFile(newWorkflow)
+		require.NoError(t, err)
+		assert.Contains(t, string(workflow), "'envs/dev/eu-west-1/billing/**'")
+		assert.Equal(t, newWorkflow, result.Workflows[oldWorkflow])
+		assert.Empty(t, result.Modified)
+
+		assert.Equal(t, "s3://tfstate/payments-dev-us-east-1/terraform.tfstate", result.OldState.String())
+		assert.Equal(t, "s3://tfstate/billing-dev-eu-west-1/terraform.tfstate", result.NewState.String())
+
+		manifest, err := LoadManifest(filesystem, ManifestFile)
+		require.NoError(t, err)
+		assert.NotContains(t, manifest.Files, "envs/dev/us-east-1/payments/backend.tf")
+		assert.Equal(t, "billing", manifest.Files["envs/dev/eu-west-1/billing/backend.tf"].App)
+		assert.Contains(t, manifest.Files, filepath.ToSlash(newWorkflow))
+	})
+
+	t.Run("keeps edits and rewrites references", func(t *testing.T) {
+		filesystem := setup(t)
+		backendPath := filepath.Join(from, "backend.tf")
+		backend, err := filesystem.ReadFile(backendPath)
+		require.NoError(t, err)
+		require.NoError(t, filesystem.WriteFile(backendPath, append(backend, []byte("# reviewed\n")...), 0644))
+		workflow, err := filesystem.ReadFile(oldWorkflow)
+		require.NoError(t, err)
+		require.NoError(t, filesystem.WriteFile(oldWorkflow, append(workflow, []byte("# reviewed\n")...), 0644))
+
+		result, err := NewGenerator(cfg, filesystem, logger.New(false)).Move("dev", "us-east-1", "payments", "eu-west-1", "billing")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{filepath.Join(to, "backend.tf"), newWorkflow}, result.Modified)
+
+		backend, err = filesystem.ReadFile(filepath.Join(to, "backend.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(backend), "# reviewed")
+		assert.Contains(t, string(backend), `"billing-dev-eu-west-1/terraform.tfstate"`)
+		assert.Contains(t, string(backend), `"eu-west-1"`)
+
+		workflow, err = filesystem.ReadFile(newWorkflow)
+		require.NoError(t, err)
+		assert.Contains(t, string(workflow), "# reviewed")
+		assert.Contains(t, string(workflow), "'envs/dev/eu-west-1/billing/**'")
+		assert.Contains(t, string(workflow), "billing-dev-euw1-terraform.yaml")
+		assert.NotContains(t, string(workflow), "payments")
+
+		statuses, err := NewGenerator(cfg, filesystem, logger.New(false)).Status()
+		require.NoError(t, err)
+		for _, status := range statuses {
+			if status.Path == filepath.ToSlash(newWorkflow) {
+				assert.Equal(t, StateModified, status.State)
+				assert.Equal(t, "content differs from the generated file", status.Reason)
+			}
+		}
+	})
+
+	t.Run("rejects missing sources and existing targets", func(t *testing.T) {
+		filesystem := setup(t)
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "orders"))
+		generator := NewGenerator(cfg, filesystem, logger.New(false))
+
+		_, err := generator.Move("dev", "us-east-1", "missing", "us-east-1", "other")
+		assert.ErrorIs(t, err, ErrAppNotFound)
+		_, err = generator.Move("dev", "us-east-1", "payments", "us-east-1", "orders")
+		assert.ErrorIs(t, err, ErrMoveTargetExists)
+		_, err = generator.Move("dev", "us-east-1", "payments", "us-east-1", "payments")
+		assert.ErrorIs(t, err, ErrMoveSameTarget)
+	})
+
+	t.Run("dry run keeps every file", func(t *testing.T) {
+		filesystem := setup(t)
+		dryRun := fs.NewDryRunFileSystem(filesystem)
+
+		_, err := NewGenerator(cfg, dryRun, logger.New(false)).Move("dev", "us-east-1", "payments", "eu-west-1", "billing")
+		require.NoError(t, err)
+		assert.True(t, filesystem.FileExists(filepath.Join(from, "backend.tf")))
+		assert.True(t, filesystem.FileExists(oldWorkflow))
+		assert.False(t, filesystem.DirExists(to))
+		assert.Contains(t, dryRun.Changes(), fs.Change{Action: fs.ActionDelete, Path: from, IsDir: true})
+	})
+}
+
+func TestMigrationSteps(t *testing.T) {
+	s3State := func(key, region string) *StateLocation {
+		return &StateLocation{Type: config.BackendS3, Settings: map[string]string{"bucket": "tfstate", "key": key, "region": region}}
+	}
+
+	t.Run("s3 state moved to another key and region", func(t *testing.T) {
+		steps := MigrationSteps(&MoveResult{
+			To:       filepath.Join("envs", "dev", "eu-west-1", "billing"),
+			OldState: s3State("payments-dev-us-east-1/terraform.tfstate", "us-east-1"),
+			NewState: s3State("billing-dev-eu-west-1/terraform.tfstate", "eu-west-1"),
+		})
+		assert.Contains(t, steps, "cd envs/dev/eu-west-1/billing")
+		assert.Contains(t, steps, `terraform init -reconfigure -backend-config="key=payments-dev-us-east-1/terraform.tfstate" -backend-config="region=us-east-1"  # connect to the old state`)
+		assert.Contains(t, steps, "aws s3 cp s3://tfstate/payments-dev-us-east-1/terraform.tfstate s3://tfstate/billing-dev-eu-west-1/terraform.tfstate --source-region us-east-1 --region eu-west-1")
+	})
+
+	t.Run("unchanged state location", func(t *testing.T) {
+		state := s3State("custom/terraform.tfstate", "us-east-1")
+		steps := MigrationSteps(&MoveResult{OldState: state, NewState: state})
+		assert.Equal(t, []string{"# The state location did not change, no migration is needed"}, steps)
+	})
+}
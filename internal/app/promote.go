@@ -0,0 +1,221 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+// ErrInvalidPattern indicates an include or exclude glob of a promotion cannot be parsed
+var ErrInvalidPattern = errors.New("invalid glob pattern")
+
+// defaultPromoteInclude selects the files promoted when no include pattern is given
+var defaultPromoteInclude = []string{"*.tf"}
+
+// updatedFiles are updated for the target environment by generate whatever their write mode
+var updatedFiles = []string{"backend.tf", "versions.tf"}
+
+// PromoteOptions selects the files copied by Promote
+// Patterns without a slash match the file name at any depth, others match the path relative to the app directory
+type PromoteOptions struct {
+	Include []string // Defaults to *.tf
+	Exclude []string
+}
+
+// PromoteDifference is a file whose content differs between the source and the target app after a promotion
+type PromoteDifference struct {
+	Path   string // Path relative to the app directory
+	Reason string
+	Diff   string // Unified diff from the source to the target file, empty when the file exists on one side only
+}
+
+// PromoteResult describes what Promote copied and what still differs
+type PromoteResult struct {
+	From        string
+	To          string
+	Copied      []string // Target files copied from the source
+	Skipped     []string // Target files that already existed and were kept
+	Differences []PromoteDifference
+}
+
+// Promote copies the files of an app from one environment to another in the same region
+// The selected files are copied without overwriting existing target files, then the target is
+// generated like generate does: create-only templates keep the promoted files, backend.tf, versions.tf,
+// the workflows and the files of managed and always templates are rendered for the target environment
+func (g *Generator) Promote(appDir, fromEnv, toEnv, region string, opts PromoteOptions) (*PromoteResult, error) {
+	include := opts.Include
+	if len(include) == 0 {
+		include = defaultPromoteInclude
+	}
+	for _, pattern := range slices.Concat(include, opts.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPattern, pattern)
+		}
+	}
+
+	from := g.config.AppPath(fromEnv, region, appDir)
+	to := g.config.AppPath(toEnv, region, appDir)
+	if !g.fs.DirExists(from) {
+		return nil, fmt.Errorf("%w: %s", ErrAppNotFound, from)
+	}
+
+	regenerated, err := g.regeneratedFiles(toEnv, region, appDir)
+	if err != nil {
+		return nil, err
+	}
+	selected := func(rel string) bool {
+		return !isHiddenPath(rel) && !slices.Contains(regenerated, rel) &&
+			matchesAny(include, rel) && !matchesAny(opts.Exclude, rel)
+	}
+
+	sourceFiles, err := g.relativeFiles(from, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PromoteResult{From: from, To: to}
+	for _, rel := range sourceFiles {
+		source, target := filepath.Join(from, rel), filepath.Join(to, rel)
+		if g.fs.FileExists(target) {
+			g.record(fs.Change{Action: fs.ActionSkip, Path: target, Reason: "already exists in " + toEnv})
+			result.Skipped = append(result.Skipped, target)
+			continue
+		}
+
+		content, err := g.fs.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		if err := g.fs.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", target, err)
+		}
+		if err := g.fs.WriteFile(target, content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		g.explain(target, "promoted from "+fromEnv)
+		g.log.Successf("Copied %s to %s", source, target)
+		result.Copied = append(result.Copied, target)
+	}
+
+	if err := g.Run(toEnv, region, appDir); err != nil {
+		return nil, err
+	}
+
+	targetFiles, err := g.relativeFiles(to, selected)
+	if err != nil {
+		return nil, err
+	}
+	if result.Differences, err = g.compareFiles(result, sourceFiles, targetFiles); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// regeneratedFiles lists the files of the target app directory that generate rewrites, relative to it:
+// backend.tf, versions.tf and the app directory output of managed and always templates
+func (g *Generator) regeneratedFiles(env, region, appDir string) ([]string, error) {
+	if err := g.loadRenderer(); err != nil {
+		return nil, err
+	}
+	data, err := g.prepareTemplateData(env, region, appDir)
+	if err != nil {
+		return nil, err
+	}
+
+	appPath := g.config.AppPath(env, region, appDir)
+	regenerated := slices.Clone(updatedFiles)
+	for _, tmplPath := range g.renderer.GetTemplateNames() {
+		if g.renderer.GetFrontMatter(tmplPath).WriteMode() == templates.ModeCreateOnly {
+			continue
+		}
+		outputPath, valid := g.outputPath(tmplPath, appPath, data)
+		if !valid {
+			continue
+		}
+		if rel, err := filepath.Rel(appPath, outputPath); err == nil && filepath.IsLocal(rel) {
+			regenerated = append(regenerated, filepath.ToSlash(rel))
+		}
+	}
+	return regenerated, nil
+}
+
+// relativeFiles lists the files under dir, relative to it, that pass the filter
+func (g *Generator) relativeFiles(dir string, filter func(rel string) bool) ([]string, error) {
+	if !g.fs.DirExists(dir) {
+		return nil, nil
+	}
+	files, err := g.fs.ListFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	var selected []string
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			continue
+		}
+		if filter(filepath.ToSlash(rel)) {
+			selected = append(selected, rel)
+		}
+	}
+	return selected, nil
+}
+
+// compareFiles reports the promoted files that differ between the source and the target app
+func (g *Generator) compareFiles(result *PromoteResult, sourceFiles, targetFiles []string) ([]PromoteDifference, error) {
+	all := slices.Concat(sourceFiles, targetFiles)
+	slices.Sort(all)
+
+	var differences []PromoteDifference
+	for _, rel := range slices.Compact(all) {
+		inSource, inTarget := slices.Contains(sourceFiles, rel), slices.Contains(targetFiles, rel)
+		switch {
+		case !inTarget:
+			differences = append(differences, PromoteDifference{Path: filepath.ToSlash(rel), Reason: "only in " + result.From})
+			continue
+		case !inSource:
+			differences = append(differences, PromoteDifference{Path: filepath.ToSlash(rel), Reason: "only in " + result.To})
+			continue
+		}
+
+		source, err := g.fs.ReadFile(filepath.Join(result.From, rel))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(result.From, rel), err)
+		}
+		target, err := g.fs.ReadFile(filepath.Join(result.To, rel))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(result.To, rel), err)
+		}
+		if string(source) == string(target) {
+			continue
+		}
+
+		diff, err := unifiedDiff(filepath.ToSlash(rel), string(source), string(target))
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", rel, err)
+		}
+		differences = append(differences, PromoteDifference{Path: filepath.ToSlash(rel), Reason: "content differs", Diff: diff})
+	}
+	return differences, nil
+}
+
+// matchesAny reports whether a slash separated relative path matches one of the glob patterns
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if ok, _ := path.Match(pattern, name); ok { //nolint:errcheck // patterns are validated by Promote
+			return true
+		}
+	}
+	return false
+}
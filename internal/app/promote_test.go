@@ -0,0 +1,142 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+func TestGenerator_Promote(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012", "stg": "210987654321"}},
+		},
+		Backend:  &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Generate: &config.Generate{GithubWorkflows: &config.GithubWorkflows{Create: true}},
+	}
+	from := filepath.Join("envs", "dev", "us-east-1", "payments")
+	to := filepath.Join("envs", "stg", "us-east-1", "payments")
+
+	setup := func(t *testing.T) *fs.MemoryFileSystem {
+		t.Helper()
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+		for name, content := range map[string]string{
+			"main.tf":                         "resource \"aws_sqs_queue\" \"payments\" {}\n",
+			"seed.tf":                         "# dev only\n",
+			"modules/queue/main.tf":           "variable \"name\" {}\n",
+			"dev.tfvars":                      "name = \"payments\"\n",
+			".terraform/modules/modules.json": "{}",
+		} {
+			require.NoError(t, filesystem.WriteFile(filepath.Join(from, filepath.FromSlash(name)), []byte(content), 0644))
+		}
+		return filesystem
+	}
+
+	t.Run("copies selected files and generates the target", func(t *testing.T) {
+		filesystem := setup(t)
+
+		result, err := NewGenerator(cfg, filesystem, logger.New(false)).Promote("payments", "dev", "stg", "us-east-1", PromoteOptions{Exclude: []string{"seed.tf"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{filepath.Join(to, "main.tf"), filepath.Join(to, "modules", "queue", "main.tf")}, result.Copied)
+		assert.False(t, filesystem.FileExists(filepath.Join(to, "seed.tf")))
+		assert.False(t, filesystem.FileExists(filepath.Join(to, "dev.tfvars")))
+		assert.False(t, filesystem.FileExists(filepath.Join(to, ".terraform", "modules", "modules.json")))
+		assert.True(t, filesystem.FileExists(filepath.Join(".github", "workflows", "payments-stg-use1-terraform.yaml")))
+
+		backend, err := filesystem.ReadFile(filepath.Join(to, "backend.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(backend), `"payments-stg-us-east-1/terraform.tfstate"`)
+
+		// Excluded files are not compared
+		assert.Empty(t, result.Differences)
+	})
+
+	t.Run("keeps existing target files and reports differences", func(t *testing.T) {
+		filesystem := setup(t)
+		require.NoError(t, filesystem.WriteFile(filepath.Join(to, "main.tf"), []byte("resource \"aws_sqs_queue\" \"stg\" {}\n"), 0644))
+
+		result, err := NewGenerator(cfg, filesystem, logger.New(false)).Promote("payments", "dev", "stg", "us-east-1", PromoteOptions{Include: []string{"main.tf"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{filepath.Join(to, "main.tf")}, result.Skipped)
+		content, err := filesystem.ReadFile(filepath.Join(to, "main.tf"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `"stg"`)
+
+		require.Len(t, result.Differences, 1)
+		assert.Equal(t, "main.tf", result.Differences[0].Path)
+		assert.Contains(t, result.Differences[0].Diff, "+resource \"aws_sqs_queue\" \"stg\" {}")
+	})
+
+	t.Run("dry run keeps every file", func(t *testing.T) {
+		filesystem := setup(t)
+		dryRun := fs.NewDryRunFileSystem(filesystem)
+
+		_, err := NewGenerator(cfg, dryRun, logger.New(false)).Promote("payments", "dev", "stg", "us-east-1", PromoteOptions{})
+		require.NoError(t, err)
+		assert.False(t, filesystem.DirExists(to))
+		assert.Contains(t, dryRun.Changes(), fs.Change{Action: fs.ActionCreate, Path: filepath.Join(to, "main.tf"), Reason: "promoted from dev"})
+	})
+
+	t.Run("copies the files of create-only templates before generating", func(t *testing.T) {
+		templatesDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "main.tf.tmpl"), []byte("# main of {{.Env}}\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "locals.tf.tmpl"), []byte("## tfskel:\n##   mode: managed\n# locals of {{.Env}}\n"), 0644))
+		custom := *cfg
+		custom.TemplatesDir = templatesDir
+		custom.ExtraTemplateExtensions = []string{"tf.tmpl"}
+		filesystem := setup(t)
+		require.NoError(t, filesystem.WriteFile(filepath.Join(from, "locals.tf"), []byte("# locals of dev\n"), 0644))
+
+		result, err := NewGenerator(&custom, filesystem, logger.New(false)).Promote("payments", "dev", "stg", "us-east-1", PromoteOptions{Include: []string{"main.tf", "locals.tf"}, Exclude: []string{"modules/*/main.tf"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{filepath.Join(to, "main.tf")}, result.Copied)
+		assert.Empty(t, result.Skipped)
+		mainContent, err := filesystem.ReadFile(filepath.Join(to, "main.tf"))
+		require.NoError(t, err)
+		assert.Equal(t, "resource \"aws_sqs_queue\" \"payments\" {}\n", string(mainContent))
+		locals, err := filesystem.ReadFile(filepath.Join(to, "locals.tf"))
+		require.NoError(t, err)
+		assert.Equal(t, "# locals of stg\n", string(locals))
+	})
+
+	t.Run("rejects missing sources and bad patterns", func(t *testing.T) {
+		filesystem := setup(t)
+		generator := NewGenerator(cfg, filesystem, logger.New(false))
+
+		_, err := generator.Promote("orders", "dev", "stg", "us-east-1", PromoteOptions{})
+		assert.ErrorIs(t, err, ErrAppNotFound)
+		_, err = generator.Promote("payments", "dev", "stg", "us-east-1", PromoteOptions{Include: []string{"[*.tf"}})
+		assert.ErrorIs(t, err, ErrInvalidPattern)
+	})
+}
+
+func TestMatchesAny(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		rel      string
+		expected bool
+	}{
+		{[]string{"*.tf"}, "main.tf", true},
+		{[]string{"*.tf"}, "modules/queue/main.tf", true},
+		{[]string{"modules/*/main.tf"}, "modules/queue/main.tf", true},
+		{[]string{"modules/*.tf"}, "modules/queue/main.tf", false},
+		{[]string{"*.tfvars"}, "main.tf", false},
+		{nil, "main.tf", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, matchesAny(tt.patterns, tt.rel), "%v %s", tt.patterns, tt.rel)
+	}
+}
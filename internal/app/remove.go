@@ -0,0 +1,165 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+var (
+	// ErrAppNotFound indicates the app directory to remove does not exist
+	ErrAppNotFound = errors.New("app directory does not exist")
+	// ErrNonGeneratedFiles indicates the app directory contains .tf files tfskel did not generate
+	ErrNonGeneratedFiles = errors.New("app directory contains .tf files that were not generated by tfskel")
+)
+
+// RemoveResult describes what Remove deleted
+type RemoveResult struct {
+	AppPath   string
+	Files     []string       // Files deleted from the app directory
+	Workflows []string       // Workflow files deleted from .github/workflows
+	State     *StateLocation // Remote state configured in the removed backend.tf, nil when unknown
+}
+
+// Remove deletes the app directory of a target and its GitHub workflows
+// Without force it refuses when the directory holds .tf files that were not generated,
+// files in hidden directories such as .terraform are not considered
+func (g *Generator) Remove(env, region, appDir string, force bool) (*RemoveResult, error) {
+	if err := g.loadRenderer(); err != nil {
+		return nil, err
+	}
+
+	appPath := g.config.AppPath(env, region, appDir)
+	if !g.fs.DirExists(appPath) {
+		return nil, fmt.Errorf("%w: %s", ErrAppNotFound, appPath)
+	}
+	if err := g.loadManifest(); err != nil {
+		return nil, err
+	}
+
+	data, err := g.prepareTemplateData(env, region, appDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := g.fs.ListFiles(appPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", appPath, err)
+	}
+	if foreign := g.nonGeneratedFiles(appPath, files, data); len(foreign) > 0 && !force {
+		return nil, fmt.Errorf("%w (use --force to remove them): %s", ErrNonGeneratedFiles, strings.Join(foreign, ", "))
+	}
+
+	result := &RemoveResult{AppPath: appPath, Files: files}
+	if content, err := g.fs.ReadFile(filepath.Join(appPath, "backend.tf")); err == nil {
+		if state, err := readStateLocation(content); err == nil {
+			result.State = state
+		} else {
+			g.log.Debugf("Could not read the state location from backend.tf: %v", err)
+		}
+	}
+
+	if err := g.fs.RemoveAll(appPath); err != nil {
+		return nil, fmt.Errorf("failed to remove %s: %w", appPath, err)
+	}
+	g.log.Successf("Removed %s", appPath)
+
+	for _, workflow := range g.workflowFiles(appPath, data) {
+		if !g.fs.FileExists(workflow) {
+			continue
+		}
+		if err := g.fs.Remove(workflow); err != nil {
+			return nil, fmt.Errorf("failed to remove %s: %w", workflow, err)
+		}
+		g.log.Successf("Removed %s", workflow)
+		result.Workflows = append(result.Workflows, workflow)
+	}
+
+	g.untrackFiles(appPath, result.Workflows)
+	if err := g.saveManifestFile(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// nonGeneratedFiles returns the .tf files under appPath that are neither recorded
+// in the manifest nor an output of a template for the app directory
+func (g *Generator) nonGeneratedFiles(appPath string, files []string, data *templates.Data) []string {
+	generated := make(map[string]bool)
+	for _, tmplPath := range g.renderer.GetTemplateNames() {
+		parts := templateLayout(tmplPath, g.renderer.GetFrontMatter(tmplPath))
+		if parts[0] != templates.CategoryTF {
+			continue
+		}
+		templateData := g.templateData(tmplPath, parts, data)
+		if outputPath, ok := g.determineOutputPath(strings.Join(parts, "/"), appPath, &templateData); ok {
+			generated[filepath.Clean(outputPath)] = true
+		}
+	}
+
+	var foreign []string
+	for _, file := range files {
+		rel, err := filepath.Rel(appPath, file)
+		if err != nil || filepath.Ext(file) != ".tf" || isHiddenPath(rel) {
+			continue
+		}
+		if _, tracked := g.manifest.Files[filepath.ToSlash(filepath.Clean(file))]; tracked || generated[filepath.Clean(file)] {
+			continue
+		}
+		foreign = append(foreign, file)
+	}
+	return foreign
+}
+
+// workflowFiles returns the paths of the GitHub workflows generated for a target
+// Reusable workflows are shared by every app and never returned
+func (g *Generator) workflowFiles(appPath string, data *templates.Data) []string {
+	workflows := slices.Collect(maps.Values(g.workflowPaths(appPath, data)))
+	slices.Sort(workflows)
+	return workflows
+}
+
+// workflowPaths maps the app specific workflow templates to their output paths for a target
+func (g *Generator) workflowPaths(appPath string, data *templates.Data) map[string]string {
+	workflows := make(map[string]string)
+	for _, tmplPath := range g.renderer.GetTemplateNames() {
+		parts := templateLayout(tmplPath, g.renderer.GetFrontMatter(tmplPath))
+		fileName := strings.TrimSuffix(parts[len(parts)-1], ".tmpl")
+		if parts[0] != templates.CategoryGithub || strings.HasPrefix(fileName, "reusable-") {
+			continue
+		}
+		templateData := g.templateData(tmplPath, parts, data)
+		if outputPath, ok := g.determineOutputPath(strings.Join(parts, "/"), appPath, &templateData); ok {
+			workflows[tmplPath] = outputPath
+		}
+	}
+	return workflows
+}
+
+// untrackFiles drops the files under appPath and the given paths from the manifest
+func (g *Generator) untrackFiles(appPath string, paths []string) {
+	prefix := filepath.ToSlash(filepath.Clean(appPath)) + "/"
+	for key := range g.manifest.Files {
+		if strings.HasPrefix(key, prefix) {
+			delete(g.manifest.Files, key)
+		}
+	}
+	for _, path := range paths {
+		delete(g.manifest.Files, filepath.ToSlash(filepath.Clean(path)))
+	}
+}
+
+// isHiddenPath reports whether a relative path is inside a hidden directory or is a hidden file
+func isHiddenPath(rel string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
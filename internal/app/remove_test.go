@@ -0,0 +1,87 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+func TestGenerator_Remove(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+		},
+		Backend:  &config.Backend{S3: &config.S3Backend{BucketName: "tfstate"}},
+		Generate: &config.Generate{GithubWorkflows: &config.GithubWorkflows{Create: true}},
+	}
+	appPath := filepath.Join("envs", "dev", "us-east-1", "payments")
+	workflow := filepath.Join(".github", "workflows", "payments-dev-use1-terraform.yaml")
+
+	setup := func(t *testing.T) *fs.MemoryFileSystem {
+		t.Helper()
+		filesystem := fs.NewMemoryFileSystem()
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+		require.NoError(t, NewGenerator(cfg, filesystem, logger.New(false)).Run("dev", "us-east-1", "orders"))
+		require.True(t, filesystem.FileExists(workflow))
+		return filesystem
+	}
+
+	t.Run("removes generated files and workflows", func(t *testing.T) {
+		filesystem := setup(t)
+		// Files in hidden directories, e.g. downloaded modules, do not block the removal
+		require.NoError(t, filesystem.WriteFile(filepath.Join(appPath, ".terraform", "modules", "vpc", "main.tf"), []byte("x"), 0644))
+
+		result, err := NewGenerator(cfg, filesystem, logger.New(false)).Remove("dev", "us-east-1", "payments", false)
+		require.NoError(t, err)
+
+		assert.False(t, filesystem.FileExists(filepath.Join(appPath, "backend.tf")))
+		assert.False(t, filesystem.FileExists(workflow))
+		assert.True(t, filesystem.FileExists(filepath.Join(".github", "workflows", "reusable-lint.yaml")))
+		assert.True(t, filesystem.FileExists(filepath.Join("envs", "dev", "us-east-1", "orders", "backend.tf")))
+		assert.Contains(t, result.Workflows, workflow)
+		require.NotNil(t, result.State)
+		assert.Equal(t, "s3://tfstate/payments-dev-us-east-1/terraform.tfstate", result.State.String())
+
+		manifest, err := LoadManifest(filesystem, ManifestFile)
+		require.NoError(t, err)
+		assert.NotContains(t, manifest.Files, "envs/dev/us-east-1/payments/backend.tf")
+		assert.NotContains(t, manifest.Files, filepath.ToSlash(workflow))
+		assert.Contains(t, manifest.Files, "envs/dev/us-east-1/orders/backend.tf")
+	})
+
+	t.Run("refuses non-generated tf files without force", func(t *testing.T) {
+		filesystem := setup(t)
+		require.NoError(t, filesystem.WriteFile(filepath.Join(appPath, "main.tf"), []byte("x"), 0644))
+
+		_, err := NewGenerator(cfg, filesystem, logger.New(false)).Remove("dev", "us-east-1", "payments", false)
+		assert.ErrorIs(t, err, ErrNonGeneratedFiles)
+		assert.True(t, filesystem.FileExists(filepath.Join(appPath, "backend.tf")))
+
+		_, err = NewGenerator(cfg, filesystem, logger.New(false)).Remove("dev", "us-east-1", "payments", true)
+		require.NoError(t, err)
+		assert.False(t, filesystem.FileExists(filepath.Join(appPath, "main.tf")))
+	})
+
+	t.Run("dry run keeps every file", func(t *testing.T) {
+		filesystem := setup(t)
+		dryRun := fs.NewDryRunFileSystem(filesystem)
+
+		_, err := NewGenerator(cfg, dryRun, logger.New(false)).Remove("dev", "us-east-1", "payments", false)
+		require.NoError(t, err)
+		assert.True(t, filesystem.FileExists(filepath.Join(appPath, "backend.tf")))
+		assert.True(t, filesystem.FileExists(workflow))
+		assert.Contains(t, dryRun.Changes(), fs.Change{Action: fs.ActionDelete, Path: appPath, IsDir: true})
+	})
+
+	t.Run("missing app directory", func(t *testing.T) {
+		_, err := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false)).Remove("dev", "us-east-1", "payments", false)
+		assert.ErrorIs(t, err, ErrAppNotFound)
+	})
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ishuar/tfskel/internal/config"
+)
+
+// dataKeys maps template data fields to the config key they are read from
+// Fields that are missing are derived from other values
+var dataKeys = map[string]string{
+	"TerraformVersion":       "terraform_version",
+	"AWSProviderVersion":     "provider.aws.version",
+	"AzureRMProviderVersion": "provider.azurerm.version",
+	"GoogleProviderVersion":  "provider.google.version",
+	"S3BucketName":           "backend.s3.bucket_name",
+	"BackendType":            "backend.type",
+	"AppPath":                "layout.app_path",
+}
+
+// DataSettings lists the template data of a target with the source of every value
+// Values read from config are annotated with the source of their config key, e.g. ".tfskel.yaml:12 (terraform_version)";
+// Env, Region and AppDir are left without a source for the caller to fill in
+func (g *Generator) DataSettings(env, region, appDir string, sources *config.Sources) ([]config.Setting, error) {
+	data, err := g.prepareTemplateData(env, region, appDir)
+	if err != nil {
+		return nil, err
+	}
+
+	target := sources.ForTarget(g.config, env, appDir)
+	settings := config.Flatten(data)
+	for i, setting := range settings {
+		switch key := g.dataKey(setting.Key, data.Env, data.Region, data.Cloud, data.BackendType); key {
+		case "":
+			settings[i].Source = "derived"
+		case "-":
+		default:
+			settings[i].Source = fmt.Sprintf("%s (%s)", target.Source(key), key)
+		}
+	}
+	return settings, nil
+}
+
+// dataKey returns the config key a template data field is read from
+// It returns "-" for the target fields and an empty string for derived fields
+func (g *Generator) dataKey(field, env, region, cloud, backendType string) string {
+	if key, ok := dataKeys[field]; ok {
+		return key
+	}
+
+	name, rest, _ := strings.Cut(field, ".")
+	switch name {
+	case "Env", "Region", "AppDir":
+		return "-"
+	case "AccountID", "SubscriptionID", "ProjectID":
+		return g.config.EnvironmentMappingKey() + "." + env
+	case "ShortRegion":
+		if _, ok := g.config.RegionAliases[region]; ok {
+			return "region_aliases." + region
+		}
+	case "AWSRoleArn":
+		if workflows := g.config.Generate; workflows != nil && workflows.GithubWorkflows != nil && workflows.GithubWorkflows.AWSRoleArn != "" {
+			return "generate.github_workflows.aws_role_arn"
+		}
+		return "generate.github_workflows.aws_role_name"
+	case "DefaultTags":
+		if cloud == config.CloudGoogle {
+			return "provider.google.default_labels." + rest
+		}
+		return "provider." + cloud + ".default_tags." + rest
+	case "AzureRMFeatures":
+		return "provider.azurerm.features." + rest
+	case "Vars":
+		return "vars." + rest
+	case "Backend":
+		switch {
+		case backendType == config.BackendS3 && rest == "bucket":
+			return "backend.s3.bucket_name"
+		case backendType == config.BackendAzureRM && rest == "subscription_id":
+			return g.config.EnvironmentMappingKey() + "." + env
+		}
+		return "backend." + backendType + "." + rest
+	}
+	return ""
+}
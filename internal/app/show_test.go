@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_DataSettings(t *testing.T) {
+	content := `provider:
+  aws:
+    account_mapping:
+      dev: "111111111111"
+backend:
+  s3:
+    bucket_name: "tfstate-{{.Env}}"
+region_aliases:
+  ap-southeast-1: sg1
+`
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(content)))
+	cmd := &cobra.Command{}
+	cfg, err := config.Load(cmd, v)
+	require.NoError(t, err)
+	sources, err := config.NewSources(cmd, v, []config.File{{Path: ".tfskel.yaml", Content: []byte(content)}})
+	require.NoError(t, err)
+
+	settings, err := NewGenerator(cfg, fs.NewMemoryFileSystem(), logger.New(false)).DataSettings("dev", "ap-southeast-1", "payments", sources)
+	require.NoError(t, err)
+
+	got := make(map[string]config.Setting)
+	for _, setting := range settings {
+		got[setting.Key] = setting
+	}
+	assert.Equal(t, config.Setting{Key: "Env", Value: "dev"}, got["Env"])
+	assert.Equal(t, config.Setting{Key: "AccountID", Value: "111111111111", Source: ".tfskel.yaml:4 (provider.aws.account_mapping.dev)"}, got["AccountID"])
+	assert.Equal(t, config.Setting{Key: "ShortRegion", Value: "sg1", Source: ".tfskel.yaml:9 (region_aliases.ap-southeast-1)"}, got["ShortRegion"])
+	assert.Equal(t, config.Setting{Key: "S3BucketName", Value: "tfstate-dev", Source: ".tfskel.yaml:7 (backend.s3.bucket_name)"}, got["S3BucketName"])
+	assert.Equal(t, config.Setting{Key: "Backend.bucket", Value: "tfstate-dev", Source: ".tfskel.yaml:7 (backend.s3.bucket_name)"}, got["Backend.bucket"])
+	assert.Equal(t, config.Setting{Key: "TerraformVersion", Value: "~> 1.13", Source: "default (terraform_version)"}, got["TerraformVersion"])
+	assert.Equal(t, config.Setting{Key: "Cloud", Value: "aws", Source: "derived"}, got["Cloud"])
+}
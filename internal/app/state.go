@@ -0,0 +1,157 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+
+	"github.com/ishuar/tfskel/internal/config"
+)
+
+// ErrNoBackendBlock indicates a backend.tf has no backend or cloud block
+var ErrNoBackendBlock = errors.New("no backend or cloud block found")
+
+// StateLocation is the remote state configured in a backend.tf
+type StateLocation struct {
+	Type     string            // Backend type, "cloud" for the HCP Terraform cloud {} block
+	Settings map[string]string // String attributes of the block, nested blocks joined with a dot (workspaces.name)
+}
+
+// String describes where the state is stored
+func (s *StateLocation) String() string {
+	switch s.Type {
+	case config.BackendS3:
+		return fmt.Sprintf("s3://%s/%s", s.Settings["bucket"], s.Settings["key"])
+	case config.BackendAzureRM:
+		return fmt.Sprintf("azurerm storage account %s, container %s, key %s",
+			s.Settings["storage_account_name"], s.Settings["container_name"], s.Settings["key"])
+	case config.BackendGCS:
+		return fmt.Sprintf("gs://%s/%s/", s.Settings["bucket"], strings.TrimSuffix(s.Settings["prefix"], "/"))
+	case config.BackendHTTP:
+		return s.Settings["address"]
+	case config.BackendCloud:
+		return fmt.Sprintf("HCP Terraform workspace %s/%s", s.Settings["organization"], s.Settings["workspaces.name"])
+	case config.BackendLocal:
+		return fmt.Sprintf("local file %s", s.Settings["path"])
+	default:
+		return s.Type + " backend"
+	}
+}
+
+// Container returns where the states of a backend are grouped and the key of this state in it:
+// the bucket and key of s3, the bucket and prefix of gcs, the container and key of azurerm and
+// the organization and workspace of HCP Terraform. Both are empty for http and local backends
+func (s *StateLocation) Container() (string, string) {
+	switch s.Type {
+	case config.BackendS3:
+		return s.Settings["bucket"], s.Settings["key"]
+	case config.BackendGCS:
+		return s.Settings["bucket"], s.Settings["prefix"]
+	case config.BackendAzureRM:
+		return s.Settings["container_name"], s.Settings["key"]
+	case config.BackendCloud:
+		return s.Settings["organization"], s.Settings["workspaces.name"]
+	default:
+		return "", ""
+	}
+}
+
+// readStateLocation reads the backend or cloud block of a backend.tf
+// Attributes that are not plain strings (e.g. interpolations) are left out
+func readStateLocation(content []byte) (*StateLocation, error) {
+	file, diags := hclwrite.ParseConfig(content, "backend.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse backend.tf: %s", diags.Error())
+	}
+
+	backendType, body := stateBlock(file)
+	if body == nil {
+		return nil, ErrNoBackendBlock
+	}
+	return &StateLocation{Type: backendType, Settings: stringAttributes(body, "")}, nil
+}
+
+// stateBlock returns the type and body of the backend or cloud block of a backend.tf
+// The body is nil when the file has neither
+func stateBlock(file *hclwrite.File) (string, *hclwrite.Body) {
+	for _, terraform := range file.Body().Blocks() {
+		if terraform.Type() != "terraform" {
+			continue
+		}
+		for _, block := range terraform.Body().Blocks() {
+			switch {
+			case block.Type() == "backend" && len(block.Labels()) == 1:
+				return block.Labels()[0], block.Body()
+			case block.Type() == config.BackendCloud:
+				return config.BackendCloud, block.Body()
+			}
+		}
+	}
+	return "", nil
+}
+
+// moveStateAttributes points an existing backend.tf at the state of another target
+// Only attributes that still hold the value rendered for the old target are changed to the
+// value rendered for the new one, so hand edits such as a custom key are kept
+func moveStateAttributes(content []byte, oldState, newState *StateLocation) ([]byte, error) {
+	file, err := parseManagedFile(content, "backend.tf")
+	if err != nil {
+		return nil, err
+	}
+	_, body := stateBlock(file)
+	if body == nil {
+		return nil, ErrNoBackendBlock
+	}
+	current := stringAttributes(body, "")
+
+	for name, value := range newState.Settings {
+		oldValue, ok := oldState.Settings[name]
+		if !ok || oldValue == value || current[name] != oldValue {
+			continue
+		}
+		attrBody := body
+		path := strings.Split(name, ".")
+		for _, blockType := range path[:len(path)-1] {
+			block := attrBody.FirstMatchingBlock(blockType, nil)
+			if block == nil {
+				attrBody = nil
+				break
+			}
+			attrBody = block.Body()
+		}
+		if attrBody != nil {
+			setStringAttribute(attrBody, path[len(path)-1], value)
+		}
+	}
+	return file.Bytes(), nil
+}
+
+// stringAttributes collects the string literal attributes of body and its nested blocks
+func stringAttributes(body *hclwrite.Body, prefix string) map[string]string {
+	settings := make(map[string]string)
+	for name, attr := range body.Attributes() {
+		if value, ok := stringLiteral(attr); ok {
+			settings[prefix+name] = value
+		}
+	}
+	for _, block := range body.Blocks() {
+		for name, value := range stringAttributes(block.Body(), prefix+block.Type()+".") {
+			settings[name] = value
+		}
+	}
+	return settings
+}
+
+// stringLiteral returns the value of an attribute holding a plain quoted string
+func stringLiteral(attr *hclwrite.Attribute) (string, bool) {
+	tokens := attr.Expr().BuildTokens(nil)
+	if len(tokens) != 3 || tokens[0].Type != hclsyntax.TokenOQuote ||
+		tokens[1].Type != hclsyntax.TokenQuotedLit || tokens[2].Type != hclsyntax.TokenCQuote {
+		return "", false
+	}
+	return string(tokens[1].Bytes), true
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStateLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  error
+	}{
+		{
+			name: "s3 backend",
+			content: `terraform {
+  backend "s3" {
+    bucket  = "tfstate"
+    key     = "payments-dev-us-east-1/terraform.tfstate"
+    encrypt = true
+  }
+}
+`,
+			expected: "s3://tfstate/payments-dev-us-east-1/terraform.tfstate",
+		},
+		{
+			name: "gcs backend",
+			content: `terraform {
+  backend "gcs" {
+    bucket = "tfstate"
+    prefix = "payments/dev/"
+  }
+}
+`,
+			expected: "gs://tfstate/payments/dev/",
+		},
+		{
+			name: "cloud block with nested workspaces",
+			content: `terraform {
+  cloud {
+    organization = "acme"
+    workspaces {
+      name = "payments-dev"
+    }
+  }
+}
+`,
+			expected: "HCP Terraform workspace acme/payments-dev",
+		},
+		{
+			name:    "no backend",
+			content: "terraform {}\n",
+			wantErr: ErrNoBackendBlock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := readStateLocation([]byte(tt.content))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, state.String())
+		})
+	}
+}
+
+func TestMoveStateAttributes(t *testing.T) {
+	oldState := &StateLocation{Type: "s3", Settings: map[string]string{
+		"bucket": "tfstate", "key": "payments-dev-us-east-1/terraform.tfstate", "region": "us-east-1",
+	}}
+	newState := &StateLocation{Type: "s3", Settings: map[string]string{
+		"bucket": "tfstate", "key": "payments-dev-eu-west-1/terraform.tfstate", "region": "eu-west-1",
+	}}
+
+	content := `terraform {
+  backend "s3" {
+    bucket = "tfstate"
+    key    = "custom/terraform.tfstate" # hand picked
+    region = "us-east-1"
+  }
+}
+`
+	updated, err := moveStateAttributes([]byte(content), oldState, newState)
+	require.NoError(t, err)
+	assert.Contains(t, string(updated), `key    = "custom/terraform.tfstate" # hand picked`)
+	assert.Contains(t, string(updated), `region = "eu-west-1"`)
+
+	_, err = moveStateAttributes([]byte("terraform {}\n"), oldState, newState)
+	assert.ErrorIs(t, err, ErrNoBackendBlock)
+}
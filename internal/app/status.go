@@ -0,0 +1,104 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/ishuar/tfskel/internal/templates"
+)
+
+// FileState describes how a generated file compares to the manifest
+type FileState string
+
+// File states reported by Status
+const (
+	StateClean    FileState = "clean"    // Unchanged since generation and up to date
+	StateModified FileState = "modified" // Edited after generation
+	StateOutdated FileState = "outdated" // Template or config changed since generation
+	StateMissing  FileState = "missing"  // Deleted after generation
+)
+
+// FileStatus is the state of one file recorded in the manifest
+type FileStatus struct {
+	Path   string
+	State  FileState
+	Reason string
+}
+
+// Status compares every file recorded in the manifest with the file on disk and
+// with the content the current templates and config would produce
+func (g *Generator) Status() ([]FileStatus, error) {
+	if err := g.loadRenderer(); err != nil {
+		return nil, err
+	}
+	manifest, err := LoadManifest(g.fs, ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]FileStatus, 0, len(manifest.Files))
+	for _, path := range slices.Sorted(maps.Keys(manifest.Files)) {
+		statuses = append(statuses, g.fileStatus(path, manifest.Files[path]))
+	}
+	return statuses, nil
+}
+
+// fileStatus determines the state of a single manifest entry
+// A file that was edited and is also outdated is reported as modified
+func (g *Generator) fileStatus(path string, entry ManifestEntry) FileStatus {
+	status := FileStatus{Path: path, State: StateClean}
+
+	content, err := g.fs.ReadFile(filepath.FromSlash(path))
+	if err != nil {
+		status.State = StateMissing
+		return status
+	}
+
+	outdated := g.outdatedReason(entry)
+	if contentHash(string(content)) != entry.Hash {
+		status.State = StateModified
+		status.Reason = "content differs from the generated file"
+		if outdated != "" {
+			status.Reason += "; " + outdated
+		}
+		return status
+	}
+	if outdated != "" {
+		status.State = StateOutdated
+		status.Reason = outdated
+	}
+	return status
+}
+
+// outdatedReason re-renders the template of an entry and explains why it no longer matches
+// An empty reason means the template and config still produce the recorded content. The project
+// files of tfskel init are not rendered for a target, only edits and removal are reported for them
+func (g *Generator) outdatedReason(entry ManifestEntry) string {
+	if entry.Env == "" {
+		return ""
+	}
+	data, err := g.prepareTemplateData(entry.Env, entry.Region, entry.App)
+	if err != nil {
+		return fmt.Sprintf("config no longer resolves: %v", err)
+	}
+
+	templateData := g.templateData(entry.Template, templateLayout(entry.Template, g.renderer.GetFrontMatter(entry.Template)), data)
+	rendered, err := g.renderer.Render(entry.Template, &templateData)
+	if errors.Is(err, templates.ErrTemplateNotFound) {
+		return "template " + entry.Template + " no longer exists"
+	}
+	if err != nil {
+		return fmt.Sprintf("failed to render: %v", err)
+	}
+
+	if contentHash(rendered) != entry.Rendered {
+		if source := g.renderer.GetTemplateSource(entry.Template); source != entry.Source {
+			return fmt.Sprintf("template source changed: %s -> %s", entry.Source, source)
+		}
+		return "template or config changed"
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+	"github.com/ishuar/tfskel/internal/logger"
+)
+
+func TestGenerator_Status(t *testing.T) {
+	newConfig := func(bucket string) *config.Config {
+		return &config.Config{
+			TerraformVersion: "~> 1.13",
+			Provider: &config.Provider{
+				AWS: &config.AWSProvider{Version: "~> 6.0", AccountMapping: map[string]string{"dev": "123456789012"}},
+			},
+			Backend: &config.Backend{S3: &config.S3Backend{BucketName: bucket}},
+		}
+	}
+	appPath := filepath.Join("envs", "dev", "us-east-1", "payments")
+
+	tests := []struct {
+		name     string
+		modify   func(t *testing.T, filesystem fs.FileSystem)
+		bucket   string
+		expected map[string]FileState
+	}{
+		{
+			name:   "freshly generated files are clean",
+			bucket: "tfstate",
+			expected: map[string]FileState{
+				"envs/dev/us-east-1/payments/backend.tf":  StateClean,
+				"envs/dev/us-east-1/payments/versions.tf": StateClean,
+			},
+		},
+		{
+			name: "edited file is modified",
+			modify: func(t *testing.T, filesystem fs.FileSystem) {
+				require.NoError(t, filesystem.WriteFile(filepath.Join(appPath, "versions.tf"), []byte("# edited\n"), 0644))
+			},
+			bucket: "tfstate",
+			expected: map[string]FileState{
+				"envs/dev/us-east-1/payments/backend.tf":  StateClean,
+				"envs/dev/us-east-1/payments/versions.tf": StateModified,
+			},
+		},
+		{
+			name:   "config change makes a file outdated",
+			bucket: "other-tfstate",
+			expected: map[string]FileState{
+				"envs/dev/us-east-1/payments/backend.tf":  StateOutdated,
+				"envs/dev/us-east-1/payments/versions.tf": StateClean,
+			},
+		},
+		{
+			name: "deleted file is missing",
+			modify: func(t *testing.T, filesystem fs.FileSystem) {
+				manifest, err := LoadManifest(filesystem, ManifestFile)
+				require.NoError(t, err)
+				manifest.Files["envs/dev/us-east-1/payments/main.tf"] = manifest.Files["envs/dev/us-east-1/payments/backend.tf"]
+				require.NoError(t, manifest.Save(filesystem, ManifestFile))
+			},
+			bucket: "tfstate",
+			expected: map[string]FileState{
+				"envs/dev/us-east-1/payments/backend.tf":  StateClean,
+				"envs/dev/us-east-1/payments/main.tf":     StateMissing,
+				"envs/dev/us-east-1/payments/versions.tf": StateClean,
+			},
+		},
+		{
+			name: "project files of init are only checked for edits and removal",
+			modify: func(t *testing.T, filesystem fs.FileSystem) {
+				manifest, err := LoadManifest(filesystem, ManifestFile)
+				require.NoError(t, err)
+				manifest.TrackFile(filepath.Join("envs", "dev", ".terraform-version"), "root/.terraform-version.tmpl", "embedded:root/.terraform-version.tmpl", "1.13.1\n")
+				manifest.TrackFile(".gitignore", "root/.gitignore.tmpl", "embedded:root/.gitignore.tmpl", "*.tfstate\n")
+				require.NoError(t, manifest.Save(filesystem, ManifestFile))
+				require.NoError(t, filesystem.WriteFile(filepath.Join("envs", "dev", ".terraform-version"), []byte("1.14.0\n"), 0644))
+			},
+			bucket: "tfstate",
+			expected: map[string]FileState{
+				".gitignore":                              StateMissing,
+				"envs/dev/.terraform-version":             StateModified,
+				"envs/dev/us-east-1/payments/backend.tf":  StateClean,
+				"envs/dev/us-east-1/payments/versions.tf": StateClean,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filesystem := fs.NewMemoryFileSystem()
+			require.NoError(t, NewGenerator(newConfig("tfstate"), filesystem, logger.New(false)).Run("dev", "us-east-1", "payments"))
+			if tt.modify != nil {
+				tt.modify(t, filesystem)
+			}
+
+			statuses, err := NewGenerator(newConfig(tt.bucket), filesystem, logger.New(false)).Status()
+			require.NoError(t, err)
+
+			states := make(map[string]FileState)
+			for _, status := range statuses {
+				states[status.Path] = status.State
+			}
+			assert.Equal(t, tt.expected, states)
+		})
+	}
+}
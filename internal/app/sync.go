@@ -0,0 +1,94 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+)
+
+// FindUndeclaredApps returns the app directories of the layout that are not declared in cfg.Apps
+// An app directory is undeclared when its app is missing from apps or when its environment
+// or region is not one the app is declared for. Hidden directories are ignored
+func FindUndeclaredApps(filesystem fs.FileSystem, cfg *config.Config) ([]string, error) {
+	declared := make(map[string]bool)
+	for _, declaredApp := range cfg.Apps {
+		for _, env := range cfg.AppEnvironments(declaredApp) {
+			for _, region := range cfg.AppRegions(declaredApp) {
+				declared[cfg.AppPath(env, region, declaredApp.Name)] = true
+			}
+		}
+	}
+
+	apps, err := cfg.FindApps(visibleDirLister(filesystem))
+	if err != nil {
+		return nil, err
+	}
+
+	var undeclared []string
+	for _, found := range apps {
+		if !declared[found.Path] {
+			undeclared = append(undeclared, found.Path)
+		}
+	}
+	sort.Strings(undeclared)
+	return undeclared, nil
+}
+
+// visibleDirLister returns a listVisibleDirs for filesystem, to walk the layout with config.FindApps
+func visibleDirLister(filesystem fs.FileSystem) func(string) ([]string, error) {
+	return func(path string) ([]string, error) {
+		return listVisibleDirs(filesystem, path)
+	}
+}
+
+// listVisibleDirs lists the non-hidden directories under path, a missing path has none
+func listVisibleDirs(filesystem fs.FileSystem, path string) ([]string, error) {
+	names, err := filesystem.ListDirs(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", path, err)
+	}
+
+	visible := names[:0]
+	for _, name := range names {
+		if !strings.HasPrefix(name, ".") {
+			visible = append(visible, name)
+		}
+	}
+	return visible, nil
+}
+
+// checkRegionCodes fails when region shares its short code with a configured region or
+// with the region of an existing app directory, which would make workflow file names collide
+func (g *Generator) checkRegionCodes(region string) error {
+	regions := append([]string{region}, g.config.GetRegions()...)
+	for _, declaredApp := range g.config.Apps {
+		regions = append(regions, declaredApp.Regions...)
+	}
+
+	apps, err := g.config.FindApps(visibleDirLister(g.fs))
+	if err != nil {
+		return err
+	}
+	for _, found := range apps {
+		if found.Region != "" {
+			regions = append(regions, found.Region)
+		}
+	}
+
+	// Only collisions involving the region being generated are reported here
+	code := g.config.ShortRegion(region)
+	for _, other := range regions {
+		if other != region && g.config.ShortRegion(other) == code {
+			return g.config.CheckRegionCodes([]string{region, other})
+		}
+	}
+	return nil
+}
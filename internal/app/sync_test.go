@@ -0,0 +1,78 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ishuar/tfskel/internal/config"
+	"github.com/ishuar/tfskel/internal/fs"
+)
+
+func TestFindUndeclaredApps(t *testing.T) {
+	cfg := &config.Config{
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{
+				AccountMapping: map[string]string{"dev": "111111111111", "prd": "222222222222"},
+				Regions:        []string{"eu-central-1"},
+			},
+		},
+		Apps: []config.App{
+			{Name: "payments", Envs: []string{"dev"}},
+			{Name: "orders"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		dirs     []string
+		expected []string
+	}{
+		{
+			name:     "no envs directory",
+			expected: nil,
+		},
+		{
+			name: "only declared apps",
+			dirs: []string{
+				"envs/dev/eu-central-1/payments",
+				"envs/dev/eu-central-1/orders",
+				"envs/prd/eu-central-1/orders",
+			},
+			expected: nil,
+		},
+		{
+			name: "unknown app and app outside its declared envs",
+			dirs: []string{
+				"envs/dev/eu-central-1/payments",
+				"envs/prd/eu-central-1/payments",
+				"envs/dev/eu-central-1/legacy",
+				"envs/dev/eu-central-1/.terraform",
+			},
+			expected: []string{
+				filepath.Join("envs", "dev", "eu-central-1", "legacy"),
+				filepath.Join("envs", "prd", "eu-central-1", "payments"),
+			},
+		},
+		{
+			name:     "app in an undeclared region",
+			dirs:     []string{"envs/dev/us-east-1/orders"},
+			expected: []string{filepath.Join("envs", "dev", "us-east-1", "orders")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filesystem := fs.NewMemoryFileSystem()
+			for _, dir := range tt.dirs {
+				require.NoError(t, filesystem.MkdirAll(filepath.FromSlash(dir), 0755))
+			}
+
+			undeclared, err := FindUndeclaredApps(filesystem, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, undeclared)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v4"
+)
+
+// FileName is the name of the config file at the project root and in the directories below it
+const FileName = ".tfskel.yaml"
+
+// ErrExtendsCycle indicates config files extend each other in a loop
+var ErrExtendsCycle = errors.New("config files extend each other in a loop")
+
+// File is a config file merged into the configuration
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// cascade resolves the configuration of project directories from the config files above them
+// It is shared by the root configuration and every configuration derived from it
+type cascade struct {
+	cmd      *cobra.Command
+	root     *Config
+	settings map[string]any     // Merged settings of the root configuration, including environment variables
+	configs  map[string]*Config // Resolved configurations by directory relative to the project root
+}
+
+// ReadFiles reads a config file and the files it extends, the most general file first
+// extends is resolved relative to the directory of the file that declares it
+func ReadFiles(path string) ([]File, error) {
+	var files []File
+	seen := make(map[string]bool)
+	for path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve config file %s: %w", path, err)
+		}
+		if seen[abs] {
+			return nil, fmt.Errorf("%w: %s", ErrExtendsCycle, path)
+		}
+		seen[abs] = true
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		var header struct {
+			Extends string `yaml:"extends"`
+		}
+		if err := yaml.Unmarshal(content, &header); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		files = append([]File{{Path: path, Content: content}}, files...)
+
+		next := header.Extends
+		if next != "" && !filepath.IsAbs(next) {
+			next = filepath.Join(filepath.Dir(path), next)
+		}
+		path = next
+	}
+	return files, nil
+}
+
+// MergeExtends merges the files extended by the config file of v below it
+// v is left as-is when no config file was read or the file extends nothing
+func MergeExtends(v *viper.Viper) error {
+	file := v.ConfigFileUsed()
+	if file == "" {
+		return nil
+	}
+	files, err := ReadFiles(file)
+	if err != nil || len(files) == 1 {
+		return err
+	}
+	return MergeFiles(v, files)
+}
+
+// MergeFiles deep-merges config files into v in order, later files win
+// Mappings are merged key by key; lists and scalars replace the earlier value
+func MergeFiles(v *viper.Viper, files []File) error {
+	merged := make(map[string]any)
+	for _, file := range files {
+		var settings map[string]any
+		if err := yaml.Unmarshal(file.Content, &settings); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", file.Path, err)
+		}
+		mergeSettings(merged, settings)
+	}
+	return v.MergeConfigMap(merged)
+}
+
+// mergeSettings deep-merges src into dst, keys are lowercased like viper does
+func mergeSettings(dst, src map[string]any) {
+	for key, value := range src {
+		key = strings.ToLower(key)
+		nested, ok := value.(map[string]any)
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		target, ok := dst[key].(map[string]any)
+		if !ok {
+			target = make(map[string]any)
+			dst[key] = target
+		}
+		mergeSettings(target, nested)
+	}
+}
+
+// Files returns the config files merged into c, the most general file first
+// The root file is preceded by the files it extends and followed by the files of the directory c was resolved for
+func (c *Config) Files() []File {
+	return c.files
+}
+
+// ForDir returns the configuration of a directory of the project
+// The config files in every directory between the project root and dir, e.g. envs/<env>/.tfskel.yaml and
+// envs/<env>/<region>/<app>/.tfskel.yaml, are merged over the root configuration in that order.
+// The root configuration is returned when none of them exists or dir is outside the project
+func (c *Config) ForDir(dir string) (*Config, error) {
+	if c.cascade == nil {
+		return c, nil
+	}
+	return c.cascade.forDir(dir)
+}
+
+// forDir resolves and caches the configuration of dir
+func (s *cascade) forDir(dir string) (*Config, error) {
+	rel, err := relativeToRoot(dir)
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return s.root, nil //nolint:nilerr // The root and directories outside the project only use the root configuration
+	}
+	if cfg, ok := s.configs[rel]; ok {
+		return cfg, nil
+	}
+
+	var files []File
+	current := "."
+	for part := range strings.SplitSeq(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		path := filepath.Join(current, FileName)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		chain, err := ReadFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, chain...)
+	}
+
+	cfg := s.root
+	if len(files) > 0 {
+		if cfg, err = s.load(files); err != nil {
+			return nil, err
+		}
+	}
+	s.configs[rel] = cfg
+	return cfg, nil
+}
+
+// load merges files over the root settings and loads the result like Load does
+func (s *cascade) load(files []File) (*Config, error) {
+	v := viper.New()
+	v.AutomaticEnv()
+	if err := v.MergeConfigMap(s.settings); err != nil {
+		return nil, fmt.Errorf("failed to merge config files: %w", err)
+	}
+	if err := MergeFiles(v, files); err != nil {
+		return nil, err
+	}
+
+	cfg, err := load(s.cmd, v)
+	if err != nil {
+		return nil, err
+	}
+	// The layout decides which directories hold the files, so only the root configuration sets it
+	cfg.Layout = s.root.Layout
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration from %s: %w", files[len(files)-1].Path, err)
+	}
+	cfg.cascade = s
+	cfg.files = append(append([]File(nil), s.root.files...), files...)
+	return cfg, nil
+}
+
+// relativeToRoot returns dir relative to the project root, the working directory of every command but init
+func relativeToRoot(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(root, abs)
+}
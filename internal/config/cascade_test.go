@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes a config file below dir, creating its parent directories
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "platform/base.yaml", "terraform_version: \"~> 1.12\"\n")
+	writeFile(t, dir, "platform/org.yaml", "extends: base.yaml\n")
+	root := writeFile(t, dir, "repo/.tfskel.yaml", "extends: ../platform/org.yaml\n")
+
+	files, err := ReadFiles(root)
+	require.NoError(t, err)
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal(t, []string{
+		filepath.Join(dir, "platform/base.yaml"),
+		filepath.Join(dir, "platform/org.yaml"),
+		root,
+	}, paths)
+
+	writeFile(t, dir, "platform/base.yaml", "extends: ../repo/.tfskel.yaml\n")
+	_, err = ReadFiles(root)
+	assert.ErrorIs(t, err, ErrExtendsCycle)
+
+	writeFile(t, dir, "platform/base.yaml", "extends: missing.yaml\n")
+	_, err = ReadFiles(root)
+	assert.ErrorContains(t, err, "missing.yaml")
+}
+
+func TestMergeExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.yaml", `terraform_version: "~> 1.12"
+provider:
+  aws:
+    version: "~> 5.0"
+    default_tags:
+      org: acme
+    regions: [eu-central-1, us-east-1]
+`)
+	root := writeFile(t, dir, ".tfskel.yaml", `extends: base.yaml
+terraform_version: "~> 1.13"
+provider:
+  aws:
+    default_tags:
+      team: platform
+    regions: [eu-west-1]
+`)
+	v := viper.New()
+	v.SetConfigFile(root)
+	require.NoError(t, v.ReadInConfig())
+	require.NoError(t, MergeExtends(v))
+
+	cfg, err := Load(&cobra.Command{}, v)
+	require.NoError(t, err)
+	assert.Equal(t, "~> 1.13", cfg.TerraformVersion)
+	assert.Equal(t, "~> 5.0", cfg.Provider.AWS.Version)
+	assert.Equal(t, map[string]string{"org": "acme", "team": "platform"}, cfg.Provider.AWS.DefaultTags, "mappings are merged")
+	assert.Equal(t, []string{"eu-west-1"}, cfg.Provider.AWS.Regions, "lists are replaced")
+	assert.Len(t, cfg.Files(), 2)
+}
+
+func TestForDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	root := writeFile(t, ".", FileName, `terraform_version: "~> 1.13"
+provider:
+  aws:
+    version: "~> 6.0"
+    account_mapping:
+      dev: "111111111111"
+      prd: "222222222222"
+`)
+	writeFile(t, ".", "envs/dev/"+FileName, "provider:\n  aws:\n    version: \"~> 6.1\"\nlayout:\n  app_path: '{{.AppDir}}/{{.Env}}'\n")
+	writeFile(t, ".", "envs/dev/eu-central-1/payments/"+FileName, "vars:\n  owner: payments-team\n")
+
+	v := viper.New()
+	v.SetConfigFile(root)
+	require.NoError(t, v.ReadInConfig())
+	cfg, err := Load(&cobra.Command{}, v)
+	require.NoError(t, err)
+
+	app, err := cfg.ForDir("envs/dev/eu-central-1/payments")
+	require.NoError(t, err)
+	assert.Equal(t, "~> 1.13", app.TerraformVersion)
+	assert.Equal(t, "~> 6.1", app.Provider.AWS.Version)
+	assert.Equal(t, map[string]any{"owner": "payments-team"}, app.Vars)
+	assert.Len(t, app.Files(), 3)
+	assert.Equal(t, cfg.Layout, app.Layout, "the layout is only read from the root file")
+
+	cached, err := app.ForDir("envs/dev/eu-central-1/payments")
+	require.NoError(t, err)
+	assert.Same(t, app, cached)
+
+	for _, dir := range []string{".", "envs/prd/eu-central-1/payments", t.TempDir()} {
+		same, err := cfg.ForDir(dir)
+		require.NoError(t, err)
+		assert.Same(t, cfg, same, dir)
+	}
+
+	writeFile(t, ".", "envs/stg/"+FileName, "backend:\n  type: nope\n")
+	_, err = cfg.ForDir("envs/stg")
+	assert.ErrorIs(t, err, ErrUnsupportedBackendType)
+}
+
+func TestSourcesAcrossFiles(t *testing.T) {
+	cmd := &cobra.Command{}
+	sources, err := NewSources(cmd, viper.New(), []File{
+		{Path: "base.yaml", Content: []byte("terraform_version: \"~> 1.12\"\nbackend:\n  s3:\n    bucket_name: acme\n")},
+		{Path: ".tfskel.yaml", Content: []byte("terraform_version: \"~> 1.13\"\n")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ".tfskel.yaml:1", sources.Source("terraform_version"))
+	assert.Equal(t, "base.yaml:4", sources.Source("backend.s3.bucket_name"))
+	assert.Equal(t, SourceDefault, sources.Source("templates_dir"))
+}
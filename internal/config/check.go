@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidS3BucketName indicates a bucket name breaks the S3 bucket naming rules
+var ErrInvalidS3BucketName = errors.New("invalid S3 bucket name")
+
+var (
+	// accountIDPattern matches AWS account IDs
+	accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+	// subscriptionIDPattern matches Azure subscription IDs
+	subscriptionIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// projectIDPattern matches Google Cloud project IDs
+	projectIDPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	// placeholderPattern matches the placeholder values of the example config and the defaults
+	placeholderPattern = regexp.MustCompile(`(?i)replace_with|change_?me|todo|^[x0-]+$|^<.*>$`)
+	// versionConstraintPattern matches a single Terraform version constraint such as ~> 1.13 or >= 5.0.0-beta1
+	versionConstraintPattern = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	// s3BucketNamePattern matches the characters and boundaries allowed in S3 bucket names
+	s3BucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*[a-z0-9]$`)
+)
+
+// s3ReservedPrefixes and s3ReservedSuffixes are reserved by AWS for other bucket types
+var (
+	s3ReservedPrefixes = []string{"xn--", "sthree-", "amzn-s3-demo-"}
+	s3ReservedSuffixes = []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3", "--table-s3"}
+)
+
+// Issue is a problem found in the configuration by Check
+type Issue struct {
+	Path    string `json:"path"` // Config key, e.g. provider.aws.account_mapping.dev or apps[0].regions[1]
+	File    string `json:"file"` // Config file that defines the key, empty when unknown
+	Line    int    `json:"line"` // Line of the key in File, 0 when unknown
+	Message string `json:"message"`
+	Warning bool   `json:"warning"` // Reported without failing the validation
+}
+
+// String formats the issue as [warning: ][line N: ]path: message
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Warning {
+		b.WriteString("warning: ")
+	}
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", i.Line)
+	}
+	if i.Path != "" {
+		b.WriteString(i.Path + ": ")
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+// Check runs the semantic checks that go beyond Validate: the config version, account IDs,
+// regions, version constraints and templates_dir. Checks that render templates live in the app package
+// An outdated config version is a warning, older files still load
+func (c *Config) Check() []Issue {
+	var issues []Issue
+	if err := c.Validate(); err != nil {
+		issues = append(issues, Issue{Message: err.Error()})
+	}
+	issues = append(issues, c.checkFileVersion(CurrentVersion)...)
+	issues = append(issues, c.checkEnvironmentMapping()...)
+	issues = append(issues, c.checkRegions()...)
+	issues = append(issues, c.checkVersions()...)
+	return append(issues, c.checkTemplatesDir()...)
+}
+
+// checkFileVersion warns when the config file has a layout version older than current
+func (c *Config) checkFileVersion(current int) []Issue {
+	if version := c.FileVersion(); version < current {
+		return []Issue{{Path: "version", Message: fmt.Sprintf("config version %d is outdated, run tfskel config migrate", version), Warning: true}}
+	}
+	return nil
+}
+
+// checkEnvironmentMapping checks the account, subscription or project ID of every environment
+func (c *Config) checkEnvironmentMapping() []Issue {
+	kind, pattern, rule := "account ID", accountIDPattern, "must be 12 digits"
+	switch c.Cloud() {
+	case CloudAzureRM:
+		kind, pattern, rule = "subscription ID", subscriptionIDPattern, "must be a UUID"
+	case CloudGoogle:
+		kind, pattern, rule = "project ID", projectIDPattern, "must be 6 to 30 lowercase letters, digits or hyphens starting with a letter"
+	}
+
+	mapping := c.EnvironmentMapping()
+	var issues []Issue
+	for _, env := range slices.Sorted(maps.Keys(mapping)) {
+		id := mapping[env]
+		path := c.EnvironmentMappingKey() + "." + env
+		switch {
+		case placeholderPattern.MatchString(id):
+			issues = append(issues, Issue{Path: path, Message: fmt.Sprintf("%s '%s' is a placeholder, set the real %s", kind, id, kind)})
+		case !pattern.MatchString(id):
+			issues = append(issues, Issue{Path: path, Message: fmt.Sprintf("%s '%s' %s", kind, id, rule)})
+		}
+	}
+	return issues
+}
+
+// checkRegions checks the configured regions and the regions of every declared app
+func (c *Config) checkRegions() []Issue {
+	var issues []Issue
+	check := func(path, region string) {
+		if !c.KnownRegion(region) {
+			issues = append(issues, Issue{Path: path, Message: fmt.Sprintf("unknown region '%s', fix the name or add it to region_aliases", region)})
+		}
+	}
+	for i, region := range c.GetRegions() {
+		check(fmt.Sprintf("provider.%s.regions[%d]", c.Cloud(), i), region)
+	}
+	for i, app := range c.Apps {
+		for j, region := range app.Regions {
+			check(fmt.Sprintf("apps[%d].regions[%d]", i, j), region)
+		}
+	}
+	return issues
+}
+
+// checkVersions checks that the global, environment and app version constraints parse
+func (c *Config) checkVersions() []Issue {
+	issues := versionIssues("", Overrides{TerraformVersion: c.TerraformVersion, Provider: c.Provider})
+	for _, env := range slices.Sorted(maps.Keys(c.Envs)) {
+		issues = append(issues, versionIssues("environments."+env+".", c.Envs[env].Overrides)...)
+	}
+	for i, app := range c.Apps {
+		issues = append(issues, versionIssues(fmt.Sprintf("apps[%d].", i), app.Overrides)...)
+	}
+	return issues
+}
+
+// versionIssues checks the version constraints of one set of overrides, keys are prefixed with prefix
+func versionIssues(prefix string, overrides Overrides) []Issue {
+	versions := map[string]string{"terraform_version": overrides.TerraformVersion}
+	if p := overrides.Provider; p != nil {
+		if p.AWS != nil {
+			versions["provider.aws.version"] = p.AWS.Version
+		}
+		if p.AzureRM != nil {
+			versions["provider.azurerm.version"] = p.AzureRM.Version
+		}
+		if p.Google != nil {
+			versions["provider.google.version"] = p.Google.Version
+		}
+	}
+
+	var issues []Issue
+	for _, key := range slices.Sorted(maps.Keys(versions)) {
+		if versions[key] != "" && !ValidVersionConstraint(versions[key]) {
+			issues = append(issues, Issue{Path: prefix + key, Message: fmt.Sprintf("version constraint '%s' does not parse", versions[key])})
+		}
+	}
+	return issues
+}
+
+// checkTemplatesDir checks that a configured templates_dir is an existing directory
+func (c *Config) checkTemplatesDir() []Issue {
+	if c.TemplatesDir == "" {
+		return nil
+	}
+	info, err := os.Stat(c.TemplatesDir)
+	switch {
+	case err != nil:
+		return []Issue{{Path: "templates_dir", Message: fmt.Sprintf("'%s' does not exist", c.TemplatesDir)}}
+	case !info.IsDir():
+		return []Issue{{Path: "templates_dir", Message: fmt.Sprintf("'%s' is not a directory", c.TemplatesDir)}}
+	}
+	return nil
+}
+
+// ValidVersionConstraint reports whether constraint is a valid Terraform version constraint
+// Constraints are comma separated, e.g. ">= 1.5, < 2.0"
+func ValidVersionConstraint(constraint string) bool {
+	for part := range strings.SplitSeq(constraint, ",") {
+		if !versionConstraintPattern.MatchString(strings.TrimSpace(part)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateS3BucketName checks a bucket name against the S3 general purpose bucket naming rules
+func ValidateS3BucketName(name string) error {
+	switch {
+	case len(name) < 3 || len(name) > 63:
+		return fmt.Errorf("%w: '%s' must be between 3 and 63 characters long", ErrInvalidS3BucketName, name)
+	case !s3BucketNamePattern.MatchString(name):
+		return fmt.Errorf("%w: '%s' must consist of lowercase letters, digits, dots and hyphens and begin and end with a letter or digit", ErrInvalidS3BucketName, name)
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("%w: '%s' must not contain two adjacent periods", ErrInvalidS3BucketName, name)
+	case net.ParseIP(name) != nil:
+		return fmt.Errorf("%w: '%s' must not be formatted as an IP address", ErrInvalidS3BucketName, name)
+	}
+	for _, prefix := range s3ReservedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("%w: '%s' must not start with the reserved prefix '%s'", ErrInvalidS3BucketName, name, prefix)
+		}
+	}
+	for _, suffix := range s3ReservedSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return fmt.Errorf("%w: '%s' must not end with the reserved suffix '%s'", ErrInvalidS3BucketName, name, suffix)
+		}
+	}
+	return nil
+}
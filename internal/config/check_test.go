@@ -0,0 +1,97 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	cfg := &Config{
+		Version:          CurrentVersion,
+		TerraformVersion: ">= 1.5, < 2.0",
+		Provider: &Provider{AWS: &AWSProvider{
+			Version: "~> six",
+			AccountMapping: map[string]string{
+				"dev": "REPLACE_WITH_YOUR_DEV_ACCOUNT_ID",
+				"stg": "12345",
+				"prd": "123456789012",
+			},
+			Regions: []string{"eu-central-1", "eu-centrall-1"},
+		}},
+		Backend: &Backend{S3: &S3Backend{BucketName: "tfstate"}},
+		Envs: map[string]Environment{
+			"prd": {Overrides: Overrides{TerraformVersion: "1.13.x"}},
+		},
+		Apps:         []App{{Name: "payments", Regions: []string{"us-east-1", "us-east-9"}}},
+		TemplatesDir: t.TempDir() + "/missing",
+	}
+
+	var paths []string
+	for _, issue := range cfg.Check() {
+		paths = append(paths, issue.Path)
+	}
+	assert.Equal(t, []string{
+		"provider.aws.account_mapping.dev",
+		"provider.aws.account_mapping.stg",
+		"provider.aws.regions[1]",
+		"apps[0].regions[1]",
+		"provider.aws.version",
+		"environments.prd.terraform_version",
+		"templates_dir",
+	}, paths)
+
+	cfg = &Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &Provider{AzureRM: &AzureRMProvider{
+			Version:             "~> 4.0",
+			SubscriptionMapping: map[string]string{"dev": "00000000-0000-0000-0000-000000000000", "prd": "8f0d4c8e-7a43-4c57-9a5e-2b8a1f0e4d21"},
+			Regions:             []string{"westeurope"},
+		}},
+		Backend:      &Backend{Type: BackendLocal, Local: &LocalBackend{Path: "terraform.tfstate"}},
+		TemplatesDir: t.TempDir(),
+	}
+	issues := cfg.Check()
+	require.Len(t, issues, 1)
+	assert.Equal(t, "provider.azurerm.subscription_mapping.dev", issues[0].Path)
+	assert.Contains(t, issues[0].Message, "placeholder")
+}
+
+func TestCheckFileVersion(t *testing.T) {
+	cfg := &Config{}
+	assert.Empty(t, cfg.checkFileVersion(CurrentVersion), "files without a version key are current")
+
+	issues := cfg.checkFileVersion(2)
+	require.Len(t, issues, 1)
+	assert.Equal(t, Issue{Path: "version", Message: "config version 1 is outdated, run tfskel config migrate", Warning: true}, issues[0])
+	assert.Equal(t, "warning: version: config version 1 is outdated, run tfskel config migrate", issues[0].String())
+	assert.Empty(t, (&Config{Version: 2}).checkFileVersion(2))
+}
+
+func TestValidVersionConstraint(t *testing.T) {
+	for _, constraint := range []string{"~> 1.13", "1.13.0", ">= 5.0, < 7.0", "= 1.6.0-beta1", "!=1.7.2"} {
+		assert.True(t, ValidVersionConstraint(constraint), constraint)
+	}
+	for _, constraint := range []string{"", "latest", "~> 1.x", ">= 1.5,", "=> 1.5", "1.2.3.4"} {
+		assert.False(t, ValidVersionConstraint(constraint), constraint)
+	}
+}
+
+func TestValidateS3BucketName(t *testing.T) {
+	for _, name := range []string{"tfstate", "acme-tfstate-dev-euc1", "acme.tfstate.123"} {
+		assert.NoError(t, ValidateS3BucketName(name), name)
+	}
+	for _, name := range []string{
+		"ab",
+		"CHANGE_ME_WITH_YOUR_GLOBALLY_UNIQUE_S3_BUCKET_NAME",
+		"-tfstate",
+		"tfstate..dev",
+		"192.168.5.4",
+		"xn--tfstate",
+		"tfstate-s3alias",
+		"tfstate-dev-account-with-a-bucket-name-that-is-far-too-long-to-be-valid",
+	} {
+		assert.ErrorIs(t, ValidateS3BucketName(name), ErrInvalidS3BucketName, name)
+	}
+}
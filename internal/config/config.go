@@ -3,9 +3,19 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/ishuar/tfskel/internal/templates"
+	"github.com/ishuar/tfskel/internal/util"
 )
 
 var (
@@ -13,6 +23,84 @@ var (
 	ErrAWSProviderRequired = errors.New("AWS provider configuration is required")
 	// ErrAccountMappingRequired indicates AWS account mapping is missing from provider configuration
 	ErrAccountMappingRequired = errors.New("AWS account mapping is required in provider configuration")
+	// ErrSubscriptionMappingRequired indicates Azure subscription mapping is missing from provider configuration
+	ErrSubscriptionMappingRequired = errors.New("subscription mapping is required in azurerm provider configuration")
+	// ErrProjectMappingRequired indicates Google project mapping is missing from provider configuration
+	ErrProjectMappingRequired = errors.New("project mapping is required in google provider configuration")
+	// ErrGCSBackendIncomplete indicates the gcs backend is missing its bucket
+	ErrGCSBackendIncomplete = errors.New("gcs backend requires bucket")
+	// ErrAzureRMBackendIncomplete indicates the azurerm backend is missing required settings
+	ErrAzureRMBackendIncomplete = errors.New("azurerm backend requires resource_group_name, storage_account_name and container_name")
+	// ErrHTTPBackendIncomplete indicates the http backend is missing its address
+	ErrHTTPBackendIncomplete = errors.New("http backend requires address")
+	// ErrCloudBackendIncomplete indicates the Terraform Cloud block is missing its organization
+	ErrCloudBackendIncomplete = errors.New("cloud backend requires organization")
+	// ErrUnsupportedBackendType indicates backend.type is not a supported backend
+	ErrUnsupportedBackendType = errors.New("unsupported backend type")
+	// ErrAppNameRequired indicates an entry in apps has no name
+	ErrAppNameRequired = errors.New("app name is required")
+	// ErrDuplicateApp indicates the same app is declared more than once in apps
+	ErrDuplicateApp = errors.New("app is declared more than once")
+	// ErrUnknownAppEnvironment indicates an app references an environment without a mapping
+	ErrUnknownAppEnvironment = errors.New("app references an environment that is not in the environment mapping")
+	// ErrReservedTemplateCategory indicates template_categories redefines a built-in template category
+	ErrReservedTemplateCategory = errors.New("template category is built-in and cannot be mapped")
+	// ErrInvalidCategoryOutput indicates a template category output path is not inside the project
+	ErrInvalidCategoryOutput = errors.New("template category output must be a relative path inside the project")
+	// ErrUnknownEnvironmentOverride indicates environments configures an environment without a mapping
+	ErrUnknownEnvironmentOverride = errors.New("environments configures an environment that is not in the environment mapping")
+	// ErrOverrideProviderNotConfigured indicates an override targets a provider that is not configured
+	ErrOverrideProviderNotConfigured = errors.New("override targets a provider that is not configured")
+	// ErrHookCommandRequired indicates a hook has no command to run
+	ErrHookCommandRequired = errors.New("hook command is required")
+	// ErrInvalidHookPolicy indicates a hook on_failure value is not supported
+	ErrInvalidHookPolicy = errors.New("hook on_failure must be fail or warn")
+	// ErrInvalidSetVar indicates a --set value is not in key=value form
+	ErrInvalidSetVar = errors.New("invalid --set value, expected key=value")
+	// ErrInvalidRegionAlias indicates a region_aliases short code is not lowercase alphanumeric
+	ErrInvalidRegionAlias = errors.New("region alias must be lowercase letters and digits")
+	// ErrRegionCodeCollision indicates two regions abbreviate to the same short code
+	ErrRegionCodeCollision = errors.New("regions share the same short code")
+)
+
+// regionAliasPattern matches the short codes accepted in region_aliases
+var regionAliasPattern = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// Supported clouds, named after their Terraform provider
+const (
+	CloudAWS     = "aws"
+	CloudAzureRM = "azurerm"
+	CloudGoogle  = "google"
+)
+
+// Supported backend types, named after their Terraform backend
+const (
+	BackendS3      = "s3"
+	BackendAzureRM = "azurerm"
+	BackendGCS     = "gcs"
+	BackendHTTP    = "http"
+	BackendCloud   = "cloud" // Terraform Cloud / HCP Terraform cloud {} block
+	BackendLocal   = "local"
+)
+
+// BackendTypes lists every supported backend type
+var BackendTypes = []string{BackendS3, BackendAzureRM, BackendGCS, BackendHTTP, BackendCloud, BackendLocal}
+
+const (
+	// DefaultAccountID is returned when no AWS account is mapped for an environment
+	DefaultAccountID = "000000000000"
+	// DefaultSubscriptionID is returned when no Azure subscription is mapped for an environment
+	DefaultSubscriptionID = "00000000-0000-0000-0000-000000000000"
+	// DefaultProjectID is returned when no Google project is mapped for an environment
+	DefaultProjectID = "CHANGE_ME_WITH_YOUR_GCP_PROJECT_ID"
+	// DefaultStateKey is the default state key template for backends that take one
+	DefaultStateKey = "{{.AppDir}}-{{.Env}}-{{.Region}}/terraform.tfstate"
+	// DefaultStatePrefix is the default state prefix template for the gcs backend
+	DefaultStatePrefix = "{{.AppDir}}-{{.Env}}-{{.Region}}"
+	// DefaultWorkspaceName is the default workspace name template for the cloud backend
+	DefaultWorkspaceName = "{{.AppDir}}-{{.Env}}-{{.Region}}"
+	// DefaultLocalStatePath is the default state path for the local backend
+	DefaultLocalStatePath = "terraform.tfstate"
 )
 
 // AWSProvider holds AWS provider configuration
@@ -23,9 +111,28 @@ type AWSProvider struct {
 	Regions        []string          `mapstructure:"regions"`
 }
 
+// AzureRMProvider holds Azure (azurerm) provider configuration
+type AzureRMProvider struct {
+	Version             string                    `mapstructure:"version"`
+	SubscriptionMapping map[string]string         `mapstructure:"subscription_mapping"`
+	Features            map[string]map[string]any `mapstructure:"features"`
+	DefaultTags         map[string]string         `mapstructure:"default_tags"`
+	Regions             []string                  `mapstructure:"regions"`
+}
+
+// GoogleProvider holds Google Cloud (google) provider configuration
+type GoogleProvider struct {
+	Version        string            `mapstructure:"version"`
+	ProjectMapping map[string]string `mapstructure:"project_mapping"`
+	DefaultLabels  map[string]string `mapstructure:"default_labels"`
+	Regions        []string          `mapstructure:"regions"`
+}
+
 // Provider holds all provider configurations
 type Provider struct {
-	AWS *AWSProvider `mapstructure:"aws"`
+	AWS     *AWSProvider     `mapstructure:"aws"`
+	AzureRM *AzureRMProvider `mapstructure:"azurerm"`
+	Google  *GoogleProvider  `mapstructure:"google"`
 }
 
 // S3Backend holds S3 backend configuration
@@ -33,9 +140,51 @@ type S3Backend struct {
 	BucketName string `mapstructure:"bucket_name"`
 }
 
+// AzureRMBackend holds azurerm backend configuration
+type AzureRMBackend struct {
+	ResourceGroupName  string `mapstructure:"resource_group_name"`
+	StorageAccountName string `mapstructure:"storage_account_name"`
+	ContainerName      string `mapstructure:"container_name"`
+	Key                string `mapstructure:"key"`
+}
+
+// GCSBackend holds gcs backend configuration
+type GCSBackend struct {
+	Bucket string `mapstructure:"bucket"`
+	Prefix string `mapstructure:"prefix"`
+}
+
+// HTTPBackend holds http backend configuration
+// Credentials are expected from TF_HTTP_USERNAME / TF_HTTP_PASSWORD, not from config
+type HTTPBackend struct {
+	Address       string `mapstructure:"address"`
+	LockAddress   string `mapstructure:"lock_address"`
+	UnlockAddress string `mapstructure:"unlock_address"`
+}
+
+// CloudBackend holds Terraform Cloud / HCP Terraform (cloud {} block) configuration
+type CloudBackend struct {
+	Organization string `mapstructure:"organization"`
+	Hostname     string `mapstructure:"hostname"`
+	Project      string `mapstructure:"project"`
+	Workspace    string `mapstructure:"workspace"`
+}
+
+// LocalBackend holds local backend configuration
+type LocalBackend struct {
+	Path string `mapstructure:"path"`
+}
+
 // Backend holds backend configuration
+// Type selects the backend; when empty it is inferred from the configured backend block (s3 by default)
 type Backend struct {
-	S3 *S3Backend `mapstructure:"s3"`
+	Type    string          `mapstructure:"type"`
+	S3      *S3Backend      `mapstructure:"s3"`
+	AzureRM *AzureRMBackend `mapstructure:"azurerm"`
+	GCS     *GCSBackend     `mapstructure:"gcs"`
+	HTTP    *HTTPBackend    `mapstructure:"http"`
+	Cloud   *CloudBackend   `mapstructure:"cloud"`
+	Local   *LocalBackend   `mapstructure:"local"`
 }
 
 // GithubWorkflows holds GitHub workflows configuration
@@ -51,18 +200,102 @@ type Generate struct {
 	GithubWorkflows *GithubWorkflows `mapstructure:"github_workflows"`
 }
 
+// Hook failure policies
+const (
+	HookFail = "fail" // Abort the command when the hook fails (default)
+	HookWarn = "warn" // Log the failure and continue
+)
+
+// Hook is a command run after files were generated
+// Every element of Command is rendered as a template against the template data
+type Hook struct {
+	Name      string   `mapstructure:"name"`
+	Command   []string `mapstructure:"command"`
+	OnFailure string   `mapstructure:"on_failure"`
+}
+
+// Hooks holds the commands run after generate (in each app directory) and after init (in the project root)
+type Hooks struct {
+	PostGenerate []Hook `mapstructure:"post_generate"`
+	PostInit     []Hook `mapstructure:"post_init"`
+}
+
+// Overrides holds the settings that can be overridden per environment and per app
+// Only versions, default tags/labels and backend settings are taken from the provider
+// and backend blocks; empty values keep the global setting and tags are merged
+type Overrides struct {
+	TerraformVersion string    `mapstructure:"terraform_version"`
+	Provider         *Provider `mapstructure:"provider"`
+	Backend          *Backend  `mapstructure:"backend"`
+}
+
+// App declares an application directory that tfskel sync keeps in place
+// Envs and Regions default to every configured environment and region when empty
+type App struct {
+	Name      string         `mapstructure:"name"`
+	Envs      []string       `mapstructure:"envs"`
+	Regions   []string       `mapstructure:"regions"`
+	Vars      map[string]any `mapstructure:"vars"` // Overrides vars and environments.<env>.vars for this app
+	Overrides `mapstructure:",squash"`
+}
+
+// Environment holds settings that only apply to one environment
+type Environment struct {
+	Vars      map[string]any `mapstructure:"vars"` // Overrides vars for this environment
+	Overrides `mapstructure:",squash"`
+}
+
 // Config holds the application configuration
 type Config struct {
+	// Version is the layout version of the config file, see CurrentVersion and Migrate
+	Version int `mapstructure:"version"`
+	// Extends is the path of a config file merged below this one, relative to this file, see ReadFiles
+	Extends                 string    `mapstructure:"extends"`
 	TerraformVersion        string    `mapstructure:"terraform_version"`
 	Provider                *Provider `mapstructure:"provider"`
 	Backend                 *Backend  `mapstructure:"backend"`
 	Generate                *Generate `mapstructure:"generate"`
 	TemplatesDir            string    `mapstructure:"templates_dir"`
 	ExtraTemplateExtensions []string  `mapstructure:"extra_template_extensions"`
+	// TemplateCategories maps user-defined template categories (directories in templates_dir)
+	// to an output directory relative to the project root, e.g. docs: "docs/{{.AppDir}}"
+	TemplateCategories map[string]string `mapstructure:"template_categories"`
+	Apps               []App             `mapstructure:"apps"`
+	// Vars are user-defined template variables exposed as .Vars, see ResolveVars
+	Vars  map[string]any         `mapstructure:"vars"`
+	Envs  map[string]Environment `mapstructure:"environments"`
+	Hooks *Hooks                 `mapstructure:"hooks"`
+	// RegionAliases overrides the short code of a region, e.g. ap-southeast-1: sg1
+	RegionAliases map[string]string `mapstructure:"region_aliases"`
+	Layout        *Layout           `mapstructure:"layout"`
+	// SetVars holds the --set key=value flags, they take precedence over every vars level
+	SetVars map[string]string `mapstructure:"-"`
+
+	cascade *cascade // Resolves the configuration of project directories, see ForDir
+	files   []File   // Config files merged into this configuration, see Files
 }
 
 // Load reads configuration from viper and command line flags
+// The config file of v and the files it extends are recorded, so that ForDir can merge directory config files over them
 func Load(cmd *cobra.Command, v *viper.Viper) (*Config, error) {
+	cfg, err := load(cmd, v)
+	if err != nil {
+		return nil, err
+	}
+
+	if file := v.ConfigFileUsed(); file != "" {
+		if _, err := os.Stat(file); err == nil {
+			if cfg.files, err = ReadFiles(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	cfg.cascade = &cascade{cmd: cmd, root: cfg, settings: v.AllSettings(), configs: make(map[string]*Config)}
+	return cfg, nil
+}
+
+// load reads configuration from viper and command line flags and applies the defaults
+func load(cmd *cobra.Command, v *viper.Viper) (*Config, error) {
 	cfg := &Config{}
 
 	// Unmarshal viper config into struct
@@ -71,7 +304,9 @@ func Load(cmd *cobra.Command, v *viper.Viper) (*Config, error) {
 	}
 
 	// Override with command line flags if provided
-	applyFlagOverrides(cmd, cfg)
+	if err := applyFlagOverrides(cmd, cfg); err != nil {
+		return nil, err
+	}
 
 	// Set defaults
 	setDefaults(cfg)
@@ -83,11 +318,12 @@ func Load(cmd *cobra.Command, v *viper.Viper) (*Config, error) {
 }
 
 // applyFlagOverrides applies command line flag values to the config
-func applyFlagOverrides(cmd *cobra.Command, cfg *Config) {
+func applyFlagOverrides(cmd *cobra.Command, cfg *Config) error {
 	applyTemplatesDirOverride(cmd, cfg)
 	applyS3BucketNameOverride(cmd, cfg)
 	applyExtraTemplateExtensionsOverride(cmd, cfg)
 	applyCreateGithubWorkflowsOverride(cmd, cfg)
+	return applySetVarsOverride(cmd, cfg)
 }
 
 func applyTemplatesDirOverride(cmd *cobra.Command, cfg *Config) {
@@ -144,6 +380,36 @@ func applyCreateGithubWorkflowsOverride(cmd *cobra.Command, cfg *Config) {
 	cfg.Generate.GithubWorkflows.Create = createWorkflows
 }
 
+func applySetVarsOverride(cmd *cobra.Command, cfg *Config) error {
+	if !cmd.Flags().Changed("set") {
+		return nil
+	}
+	values, err := cmd.Flags().GetStringArray("set")
+	if err != nil {
+		return nil
+	}
+	setVars, err := ParseSetVars(values)
+	if err != nil {
+		return err
+	}
+	cfg.SetVars = setVars
+	return nil
+}
+
+// ParseSetVars parses key=value pairs; keys are lowercased like the keys of vars in the config file
+func ParseSetVars(values []string) (map[string]string, error) {
+	setVars := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSetVar, value)
+		}
+		setVars[key] = val
+	}
+	return setVars, nil
+}
+
 // setDefaults initializes default values for unset configuration fields
 func setDefaults(cfg *Config) {
 	if cfg.TerraformVersion == "" {
@@ -152,12 +418,19 @@ func setDefaults(cfg *Config) {
 	if cfg.Provider == nil {
 		cfg.Provider = &Provider{}
 	}
-	if cfg.Provider.AWS == nil {
+	// AWS stays the default cloud unless another provider is configured
+	if cfg.Provider.AWS == nil && cfg.Provider.AzureRM == nil && cfg.Provider.Google == nil {
 		cfg.Provider.AWS = &AWSProvider{}
 	}
-	if cfg.Provider.AWS.Version == "" {
+	if cfg.Provider.AWS != nil && cfg.Provider.AWS.Version == "" {
 		cfg.Provider.AWS.Version = "~> 6.0"
 	}
+	if cfg.Provider.AzureRM != nil && cfg.Provider.AzureRM.Version == "" {
+		cfg.Provider.AzureRM.Version = "~> 4.0"
+	}
+	if cfg.Provider.Google != nil && cfg.Provider.Google.Version == "" {
+		cfg.Provider.Google.Version = "~> 7.0"
+	}
 	if cfg.Backend == nil {
 		cfg.Backend = &Backend{}
 	}
@@ -167,6 +440,51 @@ func setDefaults(cfg *Config) {
 	if cfg.Backend.S3.BucketName == "" {
 		cfg.Backend.S3.BucketName = "CHANGE_ME_WITH_YOUR_GLOBALLY_UNIQUE_S3_BUCKET_NAME"
 	}
+	setBackendDefaults(cfg.Backend)
+	if cfg.Layout == nil {
+		cfg.Layout = &Layout{}
+	}
+	if cfg.Layout.AppPath == "" {
+		cfg.Layout.AppPath = DefaultAppPath
+	}
+}
+
+// setBackendDefaults initializes default values for the selected backend type
+func setBackendDefaults(backend *Backend) {
+	switch backend.resolveType() {
+	case BackendAzureRM:
+		if backend.AzureRM == nil {
+			backend.AzureRM = &AzureRMBackend{}
+		}
+		if backend.AzureRM.Key == "" {
+			backend.AzureRM.Key = DefaultStateKey
+		}
+	case BackendGCS:
+		if backend.GCS == nil {
+			backend.GCS = &GCSBackend{}
+		}
+		if backend.GCS.Prefix == "" {
+			backend.GCS.Prefix = DefaultStatePrefix
+		}
+	case BackendHTTP:
+		if backend.HTTP == nil {
+			backend.HTTP = &HTTPBackend{}
+		}
+	case BackendCloud:
+		if backend.Cloud == nil {
+			backend.Cloud = &CloudBackend{}
+		}
+		if backend.Cloud.Workspace == "" {
+			backend.Cloud.Workspace = DefaultWorkspaceName
+		}
+	case BackendLocal:
+		if backend.Local == nil {
+			backend.Local = &LocalBackend{}
+		}
+		if backend.Local.Path == "" {
+			backend.Local.Path = DefaultLocalStatePath
+		}
+	}
 }
 
 // normalizeTemplateExtensions ensures tf.tmpl is always present and deduplicates extensions
@@ -193,16 +511,447 @@ func normalizeTemplateExtensions(cfg *Config) {
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
-	// Validate that required configuration sections exist
-	if c.Provider == nil || c.Provider.AWS == nil {
-		return ErrAWSProviderRequired
+	if c.Version > CurrentVersion {
+		return fmt.Errorf("%w: version %d, supported up to %d", ErrUnsupportedConfigVersion, c.Version, CurrentVersion)
+	}
+	if err := c.validateProvider(); err != nil {
+		return err
+	}
+	if err := c.validateBackend(); err != nil {
+		return err
+	}
+	if err := c.validateTemplateCategories(); err != nil {
+		return err
+	}
+	if err := c.validateEnvironments(); err != nil {
+		return err
+	}
+	if err := c.validateHooks(); err != nil {
+		return err
+	}
+	if err := c.validateRegionAliases(); err != nil {
+		return err
+	}
+	if err := c.validateLayout(); err != nil {
+		return err
+	}
+	return c.validateApps()
+}
+
+// validateRegionAliases checks that region aliases are plain short codes used by a single region
+func (c *Config) validateRegionAliases() error {
+	for _, region := range slices.Sorted(maps.Keys(c.RegionAliases)) {
+		if !regionAliasPattern.MatchString(c.RegionAliases[region]) {
+			return fmt.Errorf("%w: region_aliases.%s = '%s'", ErrInvalidRegionAlias, region, c.RegionAliases[region])
+		}
+	}
+	return c.CheckRegionCodes(slices.Collect(maps.Keys(c.RegionAliases)))
+}
+
+// validateHooks checks that every hook has a command and a supported failure policy
+func (c *Config) validateHooks() error {
+	if c.Hooks == nil {
+		return nil
+	}
+	stages := []struct {
+		key   string
+		hooks []Hook
+	}{
+		{"hooks.post_generate", c.Hooks.PostGenerate},
+		{"hooks.post_init", c.Hooks.PostInit},
+	}
+	for _, stage := range stages {
+		for i, hook := range stage.hooks {
+			if len(hook.Command) == 0 || hook.Command[0] == "" {
+				return fmt.Errorf("%w (%s[%d])", ErrHookCommandRequired, stage.key, i)
+			}
+			switch hook.OnFailure {
+			case "", HookFail, HookWarn:
+			default:
+				return fmt.Errorf("%w (%s[%d]): %q", ErrInvalidHookPolicy, stage.key, i, hook.OnFailure)
+			}
+		}
+	}
+	return nil
+}
+
+// PostGenerateHooks returns the hooks run after an app was generated
+func (c *Config) PostGenerateHooks() []Hook {
+	if c.Hooks == nil {
+		return nil
+	}
+	return c.Hooks.PostGenerate
+}
+
+// PostInitHooks returns the hooks run after the project was initialized
+func (c *Config) PostInitHooks() []Hook {
+	if c.Hooks == nil {
+		return nil
+	}
+	return c.Hooks.PostInit
+}
+
+// validateEnvironments checks that environments only configures mapped environments
+// and that the overrides of every environment resolve to a valid configuration
+func (c *Config) validateEnvironments() error {
+	mapping := c.EnvironmentMapping()
+	for _, env := range slices.Sorted(maps.Keys(c.Envs)) {
+		if _, ok := mapping[env]; !ok {
+			return fmt.Errorf("%w: '%s', add it to %s", ErrUnknownEnvironmentOverride, env, c.EnvironmentMappingKey())
+		}
+		if err := c.validateOverrides(c.Envs[env].Overrides, env, ""); err != nil {
+			return fmt.Errorf("environments.%s: %w", env, err)
+		}
+	}
+	return nil
+}
+
+// validateOverrides checks that overrides only target configured providers
+// and that the backend of the resolved target is complete
+func (c *Config) validateOverrides(overrides Overrides, env, appName string) error {
+	if p := overrides.Provider; p != nil && c.Provider != nil {
+		switch {
+		case p.AWS != nil && c.Provider.AWS == nil:
+			return fmt.Errorf("%w: %s", ErrOverrideProviderNotConfigured, CloudAWS)
+		case p.AzureRM != nil && c.Provider.AzureRM == nil:
+			return fmt.Errorf("%w: %s", ErrOverrideProviderNotConfigured, CloudAzureRM)
+		case p.Google != nil && c.Provider.Google == nil:
+			return fmt.Errorf("%w: %s", ErrOverrideProviderNotConfigured, CloudGoogle)
+		}
 	}
-	if len(c.Provider.AWS.AccountMapping) == 0 {
-		return ErrAccountMappingRequired
+	if overrides.Backend != nil {
+		return c.ForTarget(env, appName).validateBackend()
 	}
 	return nil
 }
 
+// validateTemplateCategories checks that user-defined categories do not shadow built-in ones
+// and that their output stays inside the project
+func (c *Config) validateTemplateCategories() error {
+	for category, output := range c.TemplateCategories {
+		if slices.Contains(templates.BuiltinCategories, category) {
+			return fmt.Errorf("%w: %s", ErrReservedTemplateCategory, category)
+		}
+		if !filepath.IsLocal(output) {
+			return fmt.Errorf("%w: %s: %q", ErrInvalidCategoryOutput, category, output)
+		}
+	}
+	return nil
+}
+
+// validateApps checks that declared apps are named, unique and only use mapped environments
+func (c *Config) validateApps() error {
+	seen := make(map[string]bool, len(c.Apps))
+	mapping := c.EnvironmentMapping()
+	for i, app := range c.Apps {
+		if app.Name == "" {
+			return fmt.Errorf("%w (apps[%d])", ErrAppNameRequired, i)
+		}
+		if seen[app.Name] {
+			return fmt.Errorf("%w: %s", ErrDuplicateApp, app.Name)
+		}
+		seen[app.Name] = true
+
+		for _, env := range app.Envs {
+			if _, ok := mapping[env]; !ok {
+				return fmt.Errorf("%w: %s uses '%s', add it to %s", ErrUnknownAppEnvironment, app.Name, env, c.EnvironmentMappingKey())
+			}
+		}
+		if err := c.validateOverrides(app.Overrides, "", app.Name); err != nil {
+			return fmt.Errorf("apps.%s: %w", app.Name, err)
+		}
+	}
+	return nil
+}
+
+// FindApp returns the declared app with the given name
+func (c *Config) FindApp(name string) (App, bool) {
+	for _, app := range c.Apps {
+		if app.Name == name {
+			return app, true
+		}
+	}
+	return App{}, false
+}
+
+// ForTarget returns the configuration of an app in an environment
+// Overrides are applied in order: global settings, environments.<env>, apps[name]
+// The receiver is not modified
+func (c *Config) ForTarget(env, appName string) *Config {
+	resolved := *c
+	if environment, ok := c.Envs[env]; ok {
+		resolved.applyOverrides(environment.Overrides)
+	}
+	if app, ok := c.FindApp(appName); ok {
+		resolved.applyOverrides(app.Overrides)
+	}
+	return &resolved
+}
+
+// applyOverrides merges overrides into c, copying every block it changes
+func (c *Config) applyOverrides(overrides Overrides) {
+	overrideString(&c.TerraformVersion, overrides.TerraformVersion)
+	if overrides.Provider != nil && c.Provider != nil {
+		c.Provider = mergeProvider(c.Provider, overrides.Provider)
+	}
+	if overrides.Backend != nil {
+		c.Backend = mergeBackend(c.Backend, overrides.Backend)
+	}
+}
+
+// mergeProvider returns a copy of base with the versions and tags of overrides applied
+// Providers that are not configured in base are ignored
+func mergeProvider(base, overrides *Provider) *Provider {
+	merged := *base
+	if merged.AWS != nil && overrides.AWS != nil {
+		aws := *merged.AWS
+		overrideString(&aws.Version, overrides.AWS.Version)
+		aws.DefaultTags = mergeTags(aws.DefaultTags, overrides.AWS.DefaultTags)
+		merged.AWS = &aws
+	}
+	if merged.AzureRM != nil && overrides.AzureRM != nil {
+		azurerm := *merged.AzureRM
+		overrideString(&azurerm.Version, overrides.AzureRM.Version)
+		azurerm.DefaultTags = mergeTags(azurerm.DefaultTags, overrides.AzureRM.DefaultTags)
+		merged.AzureRM = &azurerm
+	}
+	if merged.Google != nil && overrides.Google != nil {
+		google := *merged.Google
+		overrideString(&google.Version, overrides.Google.Version)
+		google.DefaultLabels = mergeTags(google.DefaultLabels, overrides.Google.DefaultLabels)
+		merged.Google = &google
+	}
+	return &merged
+}
+
+// mergeBackend returns a copy of base with the non-empty settings of overrides applied
+func mergeBackend(base, overrides *Backend) *Backend {
+	merged := &Backend{}
+	if base != nil {
+		*merged = *base
+	}
+	overrideString(&merged.Type, overrides.Type)
+	if o := overrides.S3; o != nil {
+		s3 := copyOrNew(merged.S3)
+		overrideString(&s3.BucketName, o.BucketName)
+		merged.S3 = s3
+	}
+	if o := overrides.AzureRM; o != nil {
+		azurerm := copyOrNew(merged.AzureRM)
+		overrideString(&azurerm.ResourceGroupName, o.ResourceGroupName)
+		overrideString(&azurerm.StorageAccountName, o.StorageAccountName)
+		overrideString(&azurerm.ContainerName, o.ContainerName)
+		overrideString(&azurerm.Key, o.Key)
+		merged.AzureRM = azurerm
+	}
+	if o := overrides.GCS; o != nil {
+		gcs := copyOrNew(merged.GCS)
+		overrideString(&gcs.Bucket, o.Bucket)
+		overrideString(&gcs.Prefix, o.Prefix)
+		merged.GCS = gcs
+	}
+	if o := overrides.HTTP; o != nil {
+		http := copyOrNew(merged.HTTP)
+		overrideString(&http.Address, o.Address)
+		overrideString(&http.LockAddress, o.LockAddress)
+		overrideString(&http.UnlockAddress, o.UnlockAddress)
+		merged.HTTP = http
+	}
+	if o := overrides.Cloud; o != nil {
+		cloud := copyOrNew(merged.Cloud)
+		overrideString(&cloud.Organization, o.Organization)
+		overrideString(&cloud.Hostname, o.Hostname)
+		overrideString(&cloud.Project, o.Project)
+		overrideString(&cloud.Workspace, o.Workspace)
+		merged.Cloud = cloud
+	}
+	if o := overrides.Local; o != nil {
+		local := copyOrNew(merged.Local)
+		overrideString(&local.Path, o.Path)
+		merged.Local = local
+	}
+	setBackendDefaults(merged)
+	return merged
+}
+
+// copyOrNew returns a copy of *block, or a new zero block when block is nil
+func copyOrNew[T any](block *T) *T {
+	copied := new(T)
+	if block != nil {
+		*copied = *block
+	}
+	return copied
+}
+
+// overrideString sets *dst to value unless value is empty
+func overrideString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// mergeTags returns base with the entries of overrides added, without modifying base
+func mergeTags(base, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	maps.Copy(merged, base)
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// ResolveVars returns the template variables for an app in an environment
+// Later levels override earlier ones: vars, environments.<env>.vars, apps[].vars, --set
+func (c *Config) ResolveVars(env, appName string) map[string]any {
+	vars := make(map[string]any)
+	maps.Copy(vars, c.Vars)
+	if environment, ok := c.Envs[env]; ok {
+		maps.Copy(vars, environment.Vars)
+	}
+	if app, ok := c.FindApp(appName); ok {
+		maps.Copy(vars, app.Vars)
+	}
+	for key, value := range c.SetVars {
+		vars[key] = value
+	}
+	return vars
+}
+
+// AppEnvironments returns the environments an app is declared for, all configured environments by default
+func (c *Config) AppEnvironments(app App) []string {
+	if len(app.Envs) > 0 {
+		return app.Envs
+	}
+	return c.Environments()
+}
+
+// AppRegions returns the regions an app is declared for, all configured regions by default
+func (c *Config) AppRegions(app App) []string {
+	if len(app.Regions) > 0 {
+		return app.Regions
+	}
+	return c.GetRegions()
+}
+
+// validateProvider checks the environment mapping of the active cloud
+func (c *Config) validateProvider() error {
+	switch c.Cloud() {
+	case CloudAzureRM:
+		if len(c.Provider.AzureRM.SubscriptionMapping) == 0 {
+			return ErrSubscriptionMappingRequired
+		}
+	case CloudGoogle:
+		if len(c.Provider.Google.ProjectMapping) == 0 {
+			return ErrProjectMappingRequired
+		}
+	default:
+		// Validate that required configuration sections exist
+		if c.Provider == nil || c.Provider.AWS == nil {
+			return ErrAWSProviderRequired
+		}
+		if len(c.Provider.AWS.AccountMapping) == 0 {
+			return ErrAccountMappingRequired
+		}
+	}
+	return nil
+}
+
+// validateBackend checks that the selected backend type is supported and has its required settings
+func (c *Config) validateBackend() error {
+	if c.Backend == nil {
+		return nil
+	}
+
+	backendType := c.BackendType()
+	switch backendType {
+	case BackendS3, BackendLocal:
+		return nil
+	case BackendAzureRM:
+		b := c.Backend.AzureRM
+		if b == nil || b.ResourceGroupName == "" || b.StorageAccountName == "" || b.ContainerName == "" {
+			return ErrAzureRMBackendIncomplete
+		}
+	case BackendGCS:
+		if c.Backend.GCS == nil || c.Backend.GCS.Bucket == "" {
+			return ErrGCSBackendIncomplete
+		}
+	case BackendHTTP:
+		if c.Backend.HTTP == nil || c.Backend.HTTP.Address == "" {
+			return ErrHTTPBackendIncomplete
+		}
+	case BackendCloud:
+		if c.Backend.Cloud == nil || c.Backend.Cloud.Organization == "" {
+			return ErrCloudBackendIncomplete
+		}
+	default:
+		return fmt.Errorf("%w '%s' (supported: %s)", ErrUnsupportedBackendType, backendType, strings.Join(BackendTypes, ", "))
+	}
+	return nil
+}
+
+// Cloud returns the cloud the configuration targets
+// AWS wins when configured, otherwise the first configured provider is used
+func (c *Config) Cloud() string {
+	if c.Provider == nil || c.Provider.AWS != nil {
+		return CloudAWS
+	}
+	if c.Provider.AzureRM != nil {
+		return CloudAzureRM
+	}
+	if c.Provider.Google != nil {
+		return CloudGoogle
+	}
+	return CloudAWS
+}
+
+// ProviderVersion returns the configured version constraint of a provider (aws, azurerm or google)
+// It is empty for other providers and providers without a config block
+func (c *Config) ProviderVersion(name string) string {
+	if c.Provider == nil {
+		return ""
+	}
+	switch {
+	case name == CloudAWS && c.Provider.AWS != nil:
+		return c.Provider.AWS.Version
+	case name == CloudAzureRM && c.Provider.AzureRM != nil:
+		return c.Provider.AzureRM.Version
+	case name == CloudGoogle && c.Provider.Google != nil:
+		return c.Provider.Google.Version
+	}
+	return ""
+}
+
+// BackendType returns the Terraform backend type used for generated backend.tf files
+func (c *Config) BackendType() string {
+	if c.Backend == nil {
+		return BackendS3
+	}
+	return c.Backend.resolveType()
+}
+
+// resolveType returns the explicit backend type, or infers it from the configured backend block
+// The s3 block is always populated by defaults and flags, so it only wins when nothing else is configured
+func (b *Backend) resolveType() string {
+	if b.Type != "" {
+		return strings.ToLower(b.Type)
+	}
+	switch {
+	case b.AzureRM != nil:
+		return BackendAzureRM
+	case b.GCS != nil:
+		return BackendGCS
+	case b.HTTP != nil:
+		return BackendHTTP
+	case b.Cloud != nil:
+		return BackendCloud
+	case b.Local != nil:
+		return BackendLocal
+	default:
+		return BackendS3
+	}
+}
+
 // GetAccountID returns the AWS account ID for the specified environment
 func (c *Config) GetAccountID(env string) string {
 	if c.Provider != nil && c.Provider.AWS != nil &&
@@ -211,14 +960,123 @@ func (c *Config) GetAccountID(env string) string {
 			return id
 		}
 	}
-	return "000000000000"
+	return DefaultAccountID
+}
+
+// GetSubscriptionID returns the Azure subscription ID for the specified environment
+func (c *Config) GetSubscriptionID(env string) string {
+	if c.Provider != nil && c.Provider.AzureRM != nil &&
+		c.Provider.AzureRM.SubscriptionMapping != nil {
+		if id, ok := c.Provider.AzureRM.SubscriptionMapping[env]; ok {
+			return id
+		}
+	}
+	return DefaultSubscriptionID
+}
+
+// GetProjectID returns the Google Cloud project ID for the specified environment
+func (c *Config) GetProjectID(env string) string {
+	if c.Provider != nil && c.Provider.Google != nil &&
+		c.Provider.Google.ProjectMapping != nil {
+		if id, ok := c.Provider.Google.ProjectMapping[env]; ok {
+			return id
+		}
+	}
+	return DefaultProjectID
+}
+
+// EnvironmentMappingKey returns the config key of the active cloud's environment mapping
+func (c *Config) EnvironmentMappingKey() string {
+	switch c.Cloud() {
+	case CloudAzureRM:
+		return "provider.azurerm.subscription_mapping"
+	case CloudGoogle:
+		return "provider.google.project_mapping"
+	default:
+		return "provider.aws.account_mapping"
+	}
+}
+
+// EnvironmentMapping returns the environment mapping of the active cloud
+// (account_mapping for AWS, subscription_mapping for Azure, project_mapping for Google)
+func (c *Config) EnvironmentMapping() map[string]string {
+	if c.Provider == nil {
+		return nil
+	}
+	switch c.Cloud() {
+	case CloudAzureRM:
+		return c.Provider.AzureRM.SubscriptionMapping
+	case CloudGoogle:
+		return c.Provider.Google.ProjectMapping
+	default:
+		if c.Provider.AWS == nil {
+			return nil
+		}
+		return c.Provider.AWS.AccountMapping
+	}
+}
+
+// Environments returns the sorted environment names of the active cloud
+func (c *Config) Environments() []string {
+	mapping := c.EnvironmentMapping()
+	envs := make([]string, 0, len(mapping))
+	for env := range mapping {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	return envs
+}
+
+// ShortRegion returns the short code of a region used in workflow names and as .ShortRegion
+// region_aliases wins over the embedded AWS region catalog
+func (c *Config) ShortRegion(region string) string {
+	if alias, ok := c.RegionAliases[region]; ok {
+		return alias
+	}
+	return util.ShortRegionName(region)
+}
+
+// KnownRegion reports whether region is a valid region name for the active cloud
+// AWS regions must be in the region catalog or have a region_aliases entry, regions of other clouds are not checked
+func (c *Config) KnownRegion(region string) bool {
+	if c.Cloud() != CloudAWS {
+		return true
+	}
+	_, aliased := c.RegionAliases[region]
+	return aliased || util.KnownRegion(region)
+}
+
+// CheckRegionCodes returns ErrRegionCodeCollision when two of the regions share a short code
+func (c *Config) CheckRegionCodes(regions []string) error {
+	seen := make(map[string]string, len(regions))
+	for _, region := range slices.Sorted(slices.Values(regions)) {
+		code := c.ShortRegion(region)
+		if other, ok := seen[code]; ok && other != region {
+			return fmt.Errorf("%w: %s and %s both abbreviate to '%s', set region_aliases for one of them", ErrRegionCodeCollision, other, region, code)
+		}
+		seen[code] = region
+	}
+	return nil
 }
 
-// GetRegions returns the list of configured AWS regions
+// GetRegions returns the list of configured regions for the active cloud
 func (c *Config) GetRegions() []string {
-	if c.Provider != nil && c.Provider.AWS != nil &&
-		c.Provider.AWS.Regions != nil {
-		return c.Provider.AWS.Regions
+	if c.Provider == nil {
+		return []string{}
+	}
+	switch c.Cloud() {
+	case CloudAzureRM:
+		if c.Provider.AzureRM.Regions != nil {
+			return c.Provider.AzureRM.Regions
+		}
+	case CloudGoogle:
+		if c.Provider.Google.Regions != nil {
+			return c.Provider.Google.Regions
+		}
+	default:
+		if c.Provider.AWS != nil && c.Provider.AWS.Regions != nil {
+			return c.Provider.AWS.Regions
+		}
 	}
 	return []string{}
 }
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -45,6 +47,291 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "account mapping is required",
 		},
+		{
+			name: "valid azurerm config",
+			config: &Config{
+				Provider: &Provider{
+					AzureRM: &AzureRMProvider{
+						SubscriptionMapping: map[string]string{
+							"dev": "11111111-2222-3333-4444-555555555555",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing azurerm subscription mapping",
+			config: &Config{
+				Provider: &Provider{
+					AzureRM: &AzureRMProvider{},
+				},
+			},
+			wantErr: true,
+			errMsg:  "subscription mapping is required",
+		},
+		{
+			name: "valid google config",
+			config: &Config{
+				Provider: &Provider{
+					Google: &GoogleProvider{
+						ProjectMapping: map[string]string{"dev": "my-dev-project"},
+					},
+				},
+				Backend: &Backend{
+					GCS: &GCSBackend{Bucket: "tfstate"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing google project mapping",
+			config: &Config{
+				Provider: &Provider{
+					Google: &GoogleProvider{},
+				},
+			},
+			wantErr: true,
+			errMsg:  "project mapping is required",
+		},
+		{
+			name: "gcs backend without bucket",
+			config: &Config{
+				Provider: &Provider{
+					Google: &GoogleProvider{
+						ProjectMapping: map[string]string{"dev": "my-dev-project"},
+					},
+				},
+				Backend: &Backend{
+					GCS: &GCSBackend{},
+				},
+			},
+			wantErr: true,
+			errMsg:  "gcs backend requires bucket",
+		},
+		{
+			name: "incomplete azurerm backend",
+			config: &Config{
+				Provider: &Provider{
+					AzureRM: &AzureRMProvider{
+						SubscriptionMapping: map[string]string{"dev": "11111111-2222-3333-4444-555555555555"},
+					},
+				},
+				Backend: &Backend{
+					AzureRM: &AzureRMBackend{StorageAccountName: "sttfstate"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "azurerm backend requires",
+		},
+		{
+			name: "http backend without address",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Backend: &Backend{Type: BackendHTTP},
+			},
+			wantErr: true,
+			errMsg:  "http backend requires address",
+		},
+		{
+			name: "cloud backend without organization",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Backend: &Backend{Cloud: &CloudBackend{}},
+			},
+			wantErr: true,
+			errMsg:  "cloud backend requires organization",
+		},
+		{
+			name: "local backend needs no settings",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Backend: &Backend{Type: BackendLocal},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported backend type",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Backend: &Backend{Type: "consul"},
+			},
+			wantErr: true,
+			errMsg:  "unsupported backend type",
+		},
+		{
+			name: "valid apps",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012", "prd": "210987654321"}},
+				},
+				Apps: []App{{Name: "payments", Envs: []string{"dev", "prd"}}, {Name: "orders"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "app without name",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Apps: []App{{Envs: []string{"dev"}}},
+			},
+			wantErr: true,
+			errMsg:  "app name is required (apps[0])",
+		},
+		{
+			name: "duplicate app",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Apps: []App{{Name: "payments"}, {Name: "payments"}},
+			},
+			wantErr: true,
+			errMsg:  "app is declared more than once: payments",
+		},
+		{
+			name: "invalid region alias",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				RegionAliases: map[string]string{"ap-southeast-1": "AP-SG"},
+			},
+			wantErr: true,
+			errMsg:  "region_aliases.ap-southeast-1 = 'AP-SG'",
+		},
+		{
+			name: "region alias colliding with another region",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				RegionAliases: map[string]string{"ap-southeast-1": "sg1", "ap-southeast-2": "sg1"},
+			},
+			wantErr: true,
+			errMsg:  "ap-southeast-1 and ap-southeast-2 both abbreviate to 'sg1'",
+		},
+		{
+			name: "app with unmapped environment",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Apps: []App{{Name: "payments", Envs: []string{"stg"}}},
+			},
+			wantErr: true,
+			errMsg:  "payments uses 'stg'",
+		},
+		{
+			name: "template category shadowing a built-in category",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				TemplateCategories: map[string]string{"github": ".github/actions"},
+			},
+			wantErr: true,
+			errMsg:  "template category is built-in and cannot be mapped: github",
+		},
+		{
+			name: "template category output outside the project",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				TemplateCategories: map[string]string{"docs": "../docs"},
+			},
+			wantErr: true,
+			errMsg:  "template category output must be a relative path inside the project",
+		},
+		{
+			name: "valid template category",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				TemplateCategories: map[string]string{"docs": "docs/{{.AppDir}}"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "environments override for an unmapped environment",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Envs: map[string]Environment{"qa": {Vars: map[string]any{"owner": "qa"}}},
+			},
+			wantErr: true,
+			errMsg:  "environments configures an environment that is not in the environment mapping: 'qa'",
+		},
+		{
+			name: "environment override for a provider that is not configured",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Envs: map[string]Environment{"dev": {Overrides: Overrides{Provider: &Provider{Google: &GoogleProvider{Version: "~> 7.1"}}}}},
+			},
+			wantErr: true,
+			errMsg:  "environments.dev: override targets a provider that is not configured: google",
+		},
+		{
+			name: "app backend override without required settings",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Apps: []App{{Name: "payments", Overrides: Overrides{Backend: &Backend{Type: BackendHTTP}}}},
+			},
+			wantErr: true,
+			errMsg:  "apps.payments: http backend requires address",
+		},
+		{
+			name: "hook without command",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Hooks: &Hooks{PostGenerate: []Hook{{Name: "fmt"}}},
+			},
+			wantErr: true,
+			errMsg:  "hook command is required (hooks.post_generate[0])",
+		},
+		{
+			name: "hook with unsupported failure policy",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Hooks: &Hooks{PostInit: []Hook{{Command: []string{"git", "init"}, OnFailure: "ignore"}}},
+			},
+			wantErr: true,
+			errMsg:  `hook on_failure must be fail or warn (hooks.post_init[0]): "ignore"`,
+		},
+		{
+			name: "valid hooks",
+			config: &Config{
+				Provider: &Provider{
+					AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "123456789012"}},
+				},
+				Hooks: &Hooks{PostGenerate: []Hook{
+					{Command: []string{"terraform", "fmt"}},
+					{Command: []string{"terraform", "init", "-backend=false"}, OnFailure: HookWarn},
+				}},
+			},
+			wantErr: false,
+		},
 		{
 			name: "empty account mapping",
 			config: &Config{
@@ -114,6 +401,140 @@ func TestGetAccountID(t *testing.T) {
 	}
 }
 
+func TestCloudAndBackendType(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          *Config
+		expectedCloud   string
+		expectedBackend string
+	}{
+		{
+			name:            "empty config defaults to aws and s3",
+			config:          &Config{},
+			expectedCloud:   CloudAWS,
+			expectedBackend: BackendS3,
+		},
+		{
+			name: "azurerm only",
+			config: &Config{
+				Provider: &Provider{AzureRM: &AzureRMProvider{}},
+				Backend:  &Backend{AzureRM: &AzureRMBackend{}},
+			},
+			expectedCloud:   CloudAzureRM,
+			expectedBackend: BackendAzureRM,
+		},
+		{
+			name: "google only",
+			config: &Config{
+				Provider: &Provider{Google: &GoogleProvider{}},
+				Backend:  &Backend{GCS: &GCSBackend{}},
+			},
+			expectedCloud:   CloudGoogle,
+			expectedBackend: BackendGCS,
+		},
+		{
+			name: "aws wins when both providers are configured",
+			config: &Config{
+				Provider: &Provider{AWS: &AWSProvider{}, AzureRM: &AzureRMProvider{}},
+			},
+			expectedCloud:   CloudAWS,
+			expectedBackend: BackendS3,
+		},
+		{
+			name: "explicit type wins over configured blocks",
+			config: &Config{
+				Backend: &Backend{Type: "HTTP", S3: &S3Backend{BucketName: "tfstate"}},
+			},
+			expectedCloud:   CloudAWS,
+			expectedBackend: BackendHTTP,
+		},
+		{
+			name:            "local block without type",
+			config:          &Config{Backend: &Backend{Local: &LocalBackend{}}},
+			expectedCloud:   CloudAWS,
+			expectedBackend: BackendLocal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedCloud, tt.config.Cloud())
+			assert.Equal(t, tt.expectedBackend, tt.config.BackendType())
+		})
+	}
+}
+
+func TestGetSubscriptionID(t *testing.T) {
+	cfg := &Config{
+		Provider: &Provider{
+			AzureRM: &AzureRMProvider{
+				SubscriptionMapping: map[string]string{"dev": "11111111-2222-3333-4444-555555555555"},
+			},
+		},
+	}
+
+	assert.Equal(t, "11111111-2222-3333-4444-555555555555", cfg.GetSubscriptionID("dev"))
+	assert.Equal(t, DefaultSubscriptionID, cfg.GetSubscriptionID("prd"))
+	assert.Equal(t, []string{"dev"}, cfg.Environments())
+}
+
+func TestGetProjectID(t *testing.T) {
+	cfg := &Config{
+		Provider: &Provider{
+			Google: &GoogleProvider{
+				ProjectMapping: map[string]string{"dev": "my-dev-project", "prd": "my-prd-project"},
+			},
+		},
+	}
+
+	assert.Equal(t, "my-dev-project", cfg.GetProjectID("dev"))
+	assert.Equal(t, DefaultProjectID, cfg.GetProjectID("stg"))
+	assert.Equal(t, []string{"dev", "prd"}, cfg.Environments())
+	assert.Equal(t, "provider.google.project_mapping", cfg.EnvironmentMappingKey())
+}
+
+func TestSetDefaults_Google(t *testing.T) {
+	cfg := &Config{
+		Provider: &Provider{Google: &GoogleProvider{}},
+		Backend:  &Backend{GCS: &GCSBackend{Bucket: "tfstate"}},
+	}
+
+	setDefaults(cfg)
+
+	assert.Nil(t, cfg.Provider.AWS, "AWS provider should not be defaulted when google is configured")
+	assert.Equal(t, "~> 7.0", cfg.Provider.Google.Version)
+	assert.Equal(t, DefaultStatePrefix, cfg.Backend.GCS.Prefix)
+}
+
+func TestSetDefaults_AzureRM(t *testing.T) {
+	cfg := &Config{
+		Provider: &Provider{AzureRM: &AzureRMProvider{}},
+		Backend:  &Backend{AzureRM: &AzureRMBackend{}},
+	}
+
+	setDefaults(cfg)
+
+	assert.Nil(t, cfg.Provider.AWS, "AWS provider should not be defaulted when azurerm is configured")
+	assert.Equal(t, "~> 4.0", cfg.Provider.AzureRM.Version)
+	assert.Equal(t, DefaultStateKey, cfg.Backend.AzureRM.Key)
+}
+
+func TestSetDefaults_BackendTypes(t *testing.T) {
+	t.Run("cloud workspace defaults to app pattern", func(t *testing.T) {
+		cfg := &Config{Backend: &Backend{Type: BackendCloud}}
+		setDefaults(cfg)
+		require.NotNil(t, cfg.Backend.Cloud)
+		assert.Equal(t, DefaultWorkspaceName, cfg.Backend.Cloud.Workspace)
+	})
+
+	t.Run("local path defaults to terraform.tfstate", func(t *testing.T) {
+		cfg := &Config{Backend: &Backend{Type: BackendLocal}}
+		setDefaults(cfg)
+		require.NotNil(t, cfg.Backend.Local)
+		assert.Equal(t, DefaultLocalStatePath, cfg.Backend.Local.Path)
+	})
+}
+
 func TestConfig_DefaultValues(t *testing.T) {
 	t.Run("config with empty defaults gets populated", func(t *testing.T) {
 		cfg := &Config{}
@@ -313,3 +734,219 @@ func TestApplyCreateGithubWorkflowsOverride(t *testing.T) {
 		})
 	}
 }
+
+func TestAppEnvironmentsAndRegions(t *testing.T) {
+	cfg := &Config{
+		Provider: &Provider{
+			AWS: &AWSProvider{
+				AccountMapping: map[string]string{"prd": "210987654321", "dev": "123456789012"},
+				Regions:        []string{"eu-central-1", "us-east-1"},
+			},
+		},
+	}
+
+	declared := App{Name: "payments", Envs: []string{"prd"}, Regions: []string{"us-east-1"}}
+	assert.Equal(t, []string{"prd"}, cfg.AppEnvironments(declared))
+	assert.Equal(t, []string{"us-east-1"}, cfg.AppRegions(declared))
+
+	defaulted := App{Name: "orders"}
+	assert.Equal(t, []string{"dev", "prd"}, cfg.AppEnvironments(defaulted))
+	assert.Equal(t, []string{"eu-central-1", "us-east-1"}, cfg.AppRegions(defaulted))
+}
+
+func TestParseSetVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "key value pairs",
+			values:   []string{"owner=platform", "Cost_Center=1234", "cidr=10.0.0.0/16,10.1.0.0/16"},
+			expected: map[string]string{"owner": "platform", "cost_center": "1234", "cidr": "10.0.0.0/16,10.1.0.0/16"},
+		},
+		{
+			name:     "value may contain equals signs and be empty",
+			values:   []string{"query=a=b", "empty="},
+			expected: map[string]string{"query": "a=b", "empty": ""},
+		},
+		{
+			name:    "missing equals sign",
+			values:  []string{"owner"},
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			values:  []string{"=platform"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setVars, err := ParseSetVars(tt.values)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSetVar)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, setVars)
+		})
+	}
+}
+
+func TestResolveVars(t *testing.T) {
+	cfg := &Config{
+		Vars: map[string]any{"owner": "platform", "cost_center": "1000", "tier": "standard"},
+		Envs: map[string]Environment{
+			"prd": {Vars: map[string]any{"owner": "sre", "tier": "critical"}},
+		},
+		Apps: []App{
+			{Name: "payments", Vars: map[string]any{"cost_center": "2000", "tier": "pci"}},
+		},
+	}
+
+	assert.Equal(t, map[string]any{"owner": "platform", "cost_center": "1000", "tier": "standard"}, cfg.ResolveVars("dev", "orders"))
+	assert.Equal(t, map[string]any{"owner": "sre", "cost_center": "1000", "tier": "critical"}, cfg.ResolveVars("prd", "orders"))
+	assert.Equal(t, map[string]any{"owner": "sre", "cost_center": "2000", "tier": "pci"}, cfg.ResolveVars("prd", "payments"))
+
+	cfg.SetVars = map[string]string{"tier": "test"}
+	assert.Equal(t, "test", cfg.ResolveVars("prd", "payments")["tier"], "--set overrides every level")
+	assert.Equal(t, map[string]any{"owner": "platform", "cost_center": "1000", "tier": "standard"}, cfg.Vars, "config vars are not modified")
+}
+
+func TestForTarget(t *testing.T) {
+	cfg := &Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &Provider{
+			AWS: &AWSProvider{
+				Version:        "~> 6.0",
+				AccountMapping: map[string]string{"dev": "111111111111", "prd": "222222222222"},
+				DefaultTags:    map[string]string{"team": "platform", "tier": "standard"},
+			},
+		},
+		Backend: &Backend{S3: &S3Backend{BucketName: "global-tfstate"}},
+		Envs: map[string]Environment{
+			"dev": {Overrides: Overrides{
+				TerraformVersion: "~> 1.14",
+				Provider:         &Provider{AWS: &AWSProvider{DefaultTags: map[string]string{"tier": "sandbox"}}},
+				Backend:          &Backend{S3: &S3Backend{BucketName: "dev-tfstate"}},
+			}},
+		},
+		Apps: []App{
+			{Name: "payments", Overrides: Overrides{
+				Provider: &Provider{AWS: &AWSProvider{Version: "~> 6.2"}},
+			}},
+		},
+	}
+
+	t.Run("global settings without overrides", func(t *testing.T) {
+		resolved := cfg.ForTarget("prd", "orders")
+		assert.Equal(t, "~> 1.13", resolved.TerraformVersion)
+		assert.Equal(t, "~> 6.0", resolved.Provider.AWS.Version)
+		assert.Equal(t, "global-tfstate", resolved.Backend.S3.BucketName)
+	})
+
+	t.Run("environment overrides", func(t *testing.T) {
+		resolved := cfg.ForTarget("dev", "orders")
+		assert.Equal(t, "~> 1.14", resolved.TerraformVersion)
+		assert.Equal(t, "~> 6.0", resolved.Provider.AWS.Version)
+		assert.Equal(t, map[string]string{"team": "platform", "tier": "sandbox"}, resolved.Provider.AWS.DefaultTags)
+		assert.Equal(t, "dev-tfstate", resolved.Backend.S3.BucketName)
+		assert.Equal(t, "111111111111", resolved.GetAccountID("dev"), "mappings are kept")
+	})
+
+	t.Run("app overrides apply on top of the environment", func(t *testing.T) {
+		resolved := cfg.ForTarget("dev", "payments")
+		assert.Equal(t, "~> 1.14", resolved.TerraformVersion)
+		assert.Equal(t, "~> 6.2", resolved.Provider.AWS.Version)
+		assert.Equal(t, "sandbox", resolved.Provider.AWS.DefaultTags["tier"])
+	})
+
+	t.Run("the global configuration is not modified", func(t *testing.T) {
+		cfg.ForTarget("dev", "payments")
+		assert.Equal(t, "~> 1.13", cfg.TerraformVersion)
+		assert.Equal(t, "~> 6.0", cfg.Provider.AWS.Version)
+		assert.Equal(t, map[string]string{"team": "platform", "tier": "standard"}, cfg.Provider.AWS.DefaultTags)
+		assert.Equal(t, "global-tfstate", cfg.Backend.S3.BucketName)
+	})
+
+	t.Run("backend type override gets backend defaults", func(t *testing.T) {
+		withLocal := *cfg
+		withLocal.Envs = map[string]Environment{"dev": {Overrides: Overrides{Backend: &Backend{Type: BackendLocal}}}}
+		resolved := withLocal.ForTarget("dev", "")
+		assert.Equal(t, BackendLocal, resolved.BackendType())
+		assert.Equal(t, DefaultLocalStatePath, resolved.Backend.Local.Path)
+		assert.Equal(t, BackendS3, cfg.BackendType())
+	})
+}
+
+func TestOverrides_FromYAML(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+terraform_version: "~> 1.13"
+provider:
+  aws:
+    account_mapping:
+      dev: "111111111111"
+environments:
+  dev:
+    terraform_version: "~> 1.14"
+    provider:
+      aws:
+        default_tags:
+          tier: sandbox
+apps:
+  - name: payments
+    provider:
+      aws:
+        version: "~> 6.2"
+`)))
+
+	cfg := &Config{}
+	require.NoError(t, v.Unmarshal(cfg))
+	assert.Equal(t, "~> 1.14", cfg.Envs["dev"].TerraformVersion)
+	assert.Equal(t, map[string]string{"tier": "sandbox"}, cfg.Envs["dev"].Provider.AWS.DefaultTags)
+	require.Len(t, cfg.Apps, 1)
+	assert.Equal(t, "~> 6.2", cfg.Apps[0].Provider.AWS.Version)
+}
+
+func TestTargetFromPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		env     string
+		appName string
+	}{
+		{path: "envs/dev/eu-central-1/payments/versions.tf", env: "dev", appName: "payments"},
+		{path: "/repo/envs/prd/us-east-1/orders/modules/versions.tf", env: "prd", appName: "orders"},
+		{path: "envs/dev/versions.tf", env: "dev"},
+		{path: "modules/network/versions.tf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			env, appName := (&Config{}).TargetFromPath(tt.path)
+			assert.Equal(t, tt.env, env)
+			assert.Equal(t, tt.appName, appName)
+		})
+	}
+}
+
+func TestShortRegion(t *testing.T) {
+	cfg := &Config{RegionAliases: map[string]string{"ap-southeast-1": "sg1", "us-east-9": "use9"}}
+
+	assert.Equal(t, "sg1", cfg.ShortRegion("ap-southeast-1"))
+	assert.Equal(t, "aps1", cfg.ShortRegion("ap-south-1"))
+	assert.Equal(t, "usgovw1", cfg.ShortRegion("us-gov-west-1"))
+
+	assert.True(t, cfg.KnownRegion("eu-central-1"))
+	assert.True(t, cfg.KnownRegion("us-east-9"), "regions with an alias are known")
+	assert.False(t, cfg.KnownRegion("eu-centrall-1"))
+	assert.True(t, (&Config{Provider: &Provider{AzureRM: &AzureRMProvider{}}}).KnownRegion("westeurope"))
+
+	require.NoError(t, cfg.CheckRegionCodes([]string{"ap-south-1", "ap-southeast-1", "ap-south-1"}))
+	err := (&Config{RegionAliases: map[string]string{"ap-southeast-1": "aps1"}}).CheckRegionCodes([]string{"ap-southeast-1", "ap-south-1"})
+	assert.ErrorIs(t, err, ErrRegionCodeCollision)
+}
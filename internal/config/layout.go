@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultAppPath is the default layout.app_path
+const DefaultAppPath = "envs/{{.Env}}/{{.Region}}/{{.AppDir}}"
+
+// ErrInvalidAppPath indicates layout.app_path is not a valid app directory template
+var ErrInvalidAppPath = errors.New("invalid layout.app_path")
+
+// appPathFieldPattern matches the placeholders of layout.app_path
+var appPathFieldPattern = regexp.MustCompile(`\{\{\s*\.(Env|Region|AppDir)\s*\}\}`)
+
+// Layout holds the directory layout of the repository
+type Layout struct {
+	// AppPath is the directory of an app relative to the project root, e.g. accounts/{{.Env}}/{{.Region}}/{{.AppDir}}
+	// It is built from {{.Env}}, {{.Region}} and {{.AppDir}}; {{.Env}} and {{.AppDir}} are required
+	AppPath string `mapstructure:"app_path"`
+}
+
+// AppLocation is an app directory found in the layout
+type AppLocation struct {
+	Env    string
+	Region string // Empty when the layout has no region directory
+	App    string
+	Path   string
+}
+
+// appPathSegment is one directory of layout.app_path
+type appPathSegment struct {
+	text    string         // Directory as written, e.g. {{.Env}}
+	fields  []string       // Placeholders of the directory, e.g. Env
+	pattern *regexp.Regexp // Matches directory names and captures the fields
+}
+
+// appPathTemplate returns layout.app_path or DefaultAppPath
+func (c *Config) appPathTemplate() string {
+	if c.Layout == nil || c.Layout.AppPath == "" {
+		return DefaultAppPath
+	}
+	return c.Layout.AppPath
+}
+
+// parseAppPath splits an app_path template into its directories
+func parseAppPath(appPath string) ([]appPathSegment, error) {
+	if filepath.IsAbs(appPath) || !filepath.IsLocal(filepath.FromSlash(appPath)) {
+		return nil, fmt.Errorf("%w: '%s' must be a relative path inside the project", ErrInvalidAppPath, appPath)
+	}
+	if rest := appPathFieldPattern.ReplaceAllString(appPath, ""); strings.Contains(rest, "{{") {
+		return nil, fmt.Errorf("%w: '%s' may only use {{.Env}}, {{.Region}} and {{.AppDir}}", ErrInvalidAppPath, appPath)
+	}
+
+	var segments []appPathSegment
+	seen := make(map[string]bool)
+	for text := range strings.SplitSeq(filepath.ToSlash(filepath.Clean(filepath.FromSlash(appPath))), "/") {
+		segment := appPathSegment{text: text}
+		var pattern strings.Builder
+		last := 0
+		for _, match := range appPathFieldPattern.FindAllStringSubmatchIndex(text, -1) {
+			field := text[match[2]:match[3]]
+			if seen[field] {
+				return nil, fmt.Errorf("%w: '%s' uses {{.%s}} more than once", ErrInvalidAppPath, appPath, field)
+			}
+			seen[field] = true
+			segment.fields = append(segment.fields, field)
+			pattern.WriteString(regexp.QuoteMeta(text[last:match[0]]) + "([^/]+?)")
+			last = match[1]
+		}
+		pattern.WriteString(regexp.QuoteMeta(text[last:]))
+		segment.pattern = regexp.MustCompile("^" + pattern.String() + "$")
+		segments = append(segments, segment)
+	}
+	for _, field := range []string{"Env", "AppDir"} {
+		if !seen[field] {
+			return nil, fmt.Errorf("%w: '%s' must contain {{.%s}}", ErrInvalidAppPath, appPath, field)
+		}
+	}
+	return segments, nil
+}
+
+// validateLayout checks that layout.app_path parses
+func (c *Config) validateLayout() error {
+	_, err := parseAppPath(c.appPathTemplate())
+	return err
+}
+
+// AppPath returns the directory of an app for an environment and region, see layout.app_path
+// Empty values cut the path before the first directory that needs them, so AppPath(env, "", "")
+// is the environment directory of the default layout, envs/<env>
+func (c *Config) AppPath(env, region, appDir string) string {
+	segments, err := parseAppPath(c.appPathTemplate())
+	if err != nil {
+		// Validate reports invalid layouts, fall back to the default so paths stay inside the project
+		segments, _ = parseAppPath(DefaultAppPath)
+	}
+
+	values := map[string]string{"Env": env, "Region": region, "AppDir": appDir}
+	parts := []string{"."}
+	for _, segment := range segments {
+		if slices.ContainsFunc(segment.fields, func(field string) bool { return values[field] == "" }) {
+			break
+		}
+		parts = append(parts, appPathFieldPattern.ReplaceAllStringFunc(segment.text, func(placeholder string) string {
+			return values[appPathFieldPattern.FindStringSubmatch(placeholder)[1]]
+		}))
+	}
+	return filepath.Join(parts...)
+}
+
+// TargetFromPath returns the environment and app of a file below an app directory of the layout
+// Paths are relative to the project root; when the layout starts with a fixed directory such as envs,
+// the last occurrence of it in the path is used, so absolute paths work too. Parts that are not
+// in the path are returned empty
+func (c *Config) TargetFromPath(path string) (env, appName string) {
+	segments, err := parseAppPath(c.appPathTemplate())
+	if err != nil {
+		return "", ""
+	}
+
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(relativeToProject(path))), "/")
+	starts := []int{0}
+	if len(segments[0].fields) == 0 {
+		starts = nil
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if segments[0].pattern.MatchString(dirs[i]) {
+				starts = append(starts, i)
+			}
+		}
+	}
+	for _, start := range starts {
+		if values := matchSegments(segments, dirs[start:]); len(values) > 0 {
+			return values["Env"], values["AppDir"]
+		}
+	}
+	return "", ""
+}
+
+// matchSegments matches the leading directories of a path against the layout and returns the captured fields
+func matchSegments(segments []appPathSegment, dirs []string) map[string]string {
+	values := make(map[string]string)
+	for i, segment := range segments {
+		if i >= len(dirs) {
+			break
+		}
+		match := segment.pattern.FindStringSubmatch(dirs[i])
+		if match == nil {
+			break
+		}
+		for j, field := range segment.fields {
+			values[field] = match[j+1]
+		}
+	}
+	return values
+}
+
+// relativeToProject returns an absolute path below the project root relative to it, other paths are returned as-is
+func relativeToProject(path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	return path
+}
+
+// FindApps walks the directories of layout.app_path and returns every app directory it finds
+// list returns the subdirectories of a directory that may hold apps, nil for a missing directory
+func (c *Config) FindApps(list func(dir string) ([]string, error)) ([]AppLocation, error) {
+	segments, err := parseAppPath(c.appPathTemplate())
+	if err != nil {
+		return nil, err
+	}
+
+	var apps []AppLocation
+	var walk func(dir string, depth int, values map[string]string) error
+	walk = func(dir string, depth int, values map[string]string) error {
+		if depth == len(segments) {
+			apps = append(apps, AppLocation{Env: values["Env"], Region: values["Region"], App: values["AppDir"], Path: dir})
+			return nil
+		}
+		names, err := list(dir)
+		if err != nil {
+			return err
+		}
+		segment := segments[depth]
+		for _, name := range names {
+			match := segment.pattern.FindStringSubmatch(name)
+			if match == nil {
+				continue
+			}
+			next := maps.Clone(values)
+			for j, field := range segment.fields {
+				next[field] = match[j+1]
+			}
+			if err := walk(filepath.Join(dir, name), depth+1, next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(".", 0, map[string]string{}); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
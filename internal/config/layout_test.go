@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAppPath(t *testing.T) {
+	for _, appPath := range []string{DefaultAppPath, "accounts/{{.Env}}/{{.Region}}/{{.AppDir}}", "{{.AppDir}}/{{.Env}}", "stacks/{{ .Env }}-{{.Region}}/{{.AppDir}}"} {
+		_, err := parseAppPath(appPath)
+		assert.NoError(t, err, appPath)
+	}
+
+	tests := map[string]string{
+		"/envs/{{.Env}}/{{.AppDir}}":             "relative path",
+		"../{{.Env}}/{{.AppDir}}":                "relative path",
+		"envs/{{.Env}}/{{.Account}}/{{.AppDir}}": "may only use",
+		"envs/{{.Env}}/{{.Env}}/{{.AppDir}}":     "more than once",
+		"envs/{{.Region}}/{{.AppDir}}":           "must contain {{.Env}}",
+		"envs/{{.Env}}/{{.Region}}":              "must contain {{.AppDir}}",
+	}
+	for appPath, message := range tests {
+		_, err := parseAppPath(appPath)
+		require.ErrorIs(t, err, ErrInvalidAppPath, appPath)
+		assert.ErrorContains(t, err, message, appPath)
+	}
+
+	err := (&Config{Provider: &Provider{AWS: &AWSProvider{AccountMapping: map[string]string{"dev": "111111111111"}}}, Layout: &Layout{AppPath: "{{.Env}}"}}).Validate()
+	assert.ErrorIs(t, err, ErrInvalidAppPath)
+}
+
+func TestAppPath(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, filepath.FromSlash("envs/dev/eu-central-1/payments"), cfg.AppPath("dev", "eu-central-1", "payments"))
+	assert.Equal(t, filepath.FromSlash("envs/dev"), cfg.AppPath("dev", "", ""))
+
+	cfg.Layout = &Layout{AppPath: "{{.AppDir}}/{{.Env}}"}
+	assert.Equal(t, filepath.FromSlash("payments/dev"), cfg.AppPath("dev", "eu-central-1", "payments"))
+	assert.Equal(t, ".", cfg.AppPath("dev", "", ""))
+
+	cfg.Layout = &Layout{AppPath: "stacks/{{.Env}}-{{.Region}}/{{.AppDir}}"}
+	assert.Equal(t, filepath.FromSlash("stacks/dev-eu-central-1/payments"), cfg.AppPath("dev", "eu-central-1", "payments"))
+	assert.Equal(t, "stacks", cfg.AppPath("dev", "", ""))
+}
+
+func TestTargetFromPathLayout(t *testing.T) {
+	tests := []struct {
+		appPath string
+		path    string
+		env     string
+		appName string
+	}{
+		{"accounts/{{.Env}}/{{.Region}}/{{.AppDir}}", "/repo/accounts/prd/us-east-1/orders/versions.tf", "prd", "orders"},
+		{"accounts/{{.Env}}/{{.Region}}/{{.AppDir}}", "envs/prd/us-east-1/orders/versions.tf", "", ""},
+		{"{{.AppDir}}/{{.Env}}", "payments/dev/versions.tf", "dev", "payments"},
+		{"{{.AppDir}}/{{.Env}}", "payments/versions.tf", "", "payments"},
+		{"stacks/{{.Env}}-{{.Region}}/{{.AppDir}}", "stacks/dev-eu-central-1/payments/main.tf", "dev", "payments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.appPath+" "+tt.path, func(t *testing.T) {
+			env, appName := (&Config{Layout: &Layout{AppPath: tt.appPath}}).TargetFromPath(tt.path)
+			assert.Equal(t, tt.env, env)
+			assert.Equal(t, tt.appName, appName)
+		})
+	}
+}
+
+func TestFindApps(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"payments/dev", "payments/prd", "orders/dev", "docs"} {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	list := func(dir string) ([]string, error) {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		var names []string
+		for _, entry := range entries {
+			if entry.IsDir() {
+				names = append(names, entry.Name())
+			}
+		}
+		return names, err
+	}
+
+	apps, err := (&Config{Layout: &Layout{AppPath: "{{.AppDir}}/{{.Env}}"}}).FindApps(list)
+	require.NoError(t, err)
+	assert.Equal(t, []AppLocation{
+		{Env: "dev", App: "orders", Path: filepath.FromSlash("orders/dev")},
+		{Env: "dev", App: "payments", Path: filepath.FromSlash("payments/dev")},
+		{Env: "prd", App: "payments", Path: filepath.FromSlash("payments/prd")},
+	}, apps)
+
+	apps, err = (&Config{}).FindApps(list)
+	require.NoError(t, err)
+	assert.Empty(t, apps, "the default layout needs an envs directory")
+
+	require.NoError(t, os.MkdirAll("envs/dev/eu-central-1/payments", 0755))
+	apps, err = (&Config{}).FindApps(list)
+	require.NoError(t, err)
+	assert.Equal(t, []AppLocation{{Env: "dev", Region: "eu-central-1", App: "payments", Path: filepath.FromSlash("envs/dev/eu-central-1/payments")}}, apps)
+}
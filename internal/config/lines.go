@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// KeyLines maps every key of a YAML config file to the line it is defined on
+// Keys are lowercased like viper does and written like Issue paths, e.g. apps[0].regions[1]
+func KeyLines(content []byte) (map[string]int, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	lines := make(map[string]int)
+	if len(root.Content) > 0 {
+		collectKeyLines(root.Content[0], "", lines)
+	}
+	return lines, nil
+}
+
+// collectKeyLines records the line of every mapping key and sequence item below node
+func collectKeyLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := strings.ToLower(node.Content[i].Value)
+			if path != "" {
+				key = path + "." + key
+			}
+			lines[key] = node.Content[i].Line
+			collectKeyLines(node.Content[i+1], key, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			lines[key] = item.Line
+			collectKeyLines(item, key, lines)
+		}
+	}
+}
+
+// SetIssueLines sets the file and line of every issue from the config files, the most general file first
+// Issues are attributed to the most specific file that defines their key, see Config.Files;
+// issues whose key is in none of the files are left without a file and line
+func SetIssueLines(issues []Issue, files []File) error {
+	lines := make([]map[string]int, len(files))
+	for i, file := range files {
+		fileLines, err := KeyLines(file.Content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Path, err)
+		}
+		lines[i] = fileLines
+	}
+	for i := range issues {
+		if issues[i].Path == "" {
+			continue
+		}
+		for j := len(files) - 1; j >= 0; j-- {
+			if line, ok := lines[j][issues[i].Path]; ok {
+				issues[i].File = files[j].Path
+				issues[i].Line = line
+				break
+			}
+		}
+	}
+	return nil
+}
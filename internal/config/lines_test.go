@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetIssueLines(t *testing.T) {
+	base := `provider:
+  aws:
+    version: "~> 6.0"
+    account_mapping:
+      dev: "REPLACE_WITH_YOUR_DEV_ACCOUNT_ID"
+`
+	content := `extends: base.yaml
+terraform_version: "~> 1.13"
+provider:
+  aws:
+    account_mapping:
+      Prd: "REPLACE_WITH_YOUR_PRD_ACCOUNT_ID"
+apps:
+  - name: payments
+    regions:
+      - us-east-1
+      - us-east-9
+`
+	files := []File{{Path: "base.yaml", Content: []byte(base)}, {Path: ".tfskel.yaml", Content: []byte(content)}}
+	issues := []Issue{
+		{Path: "provider.aws.account_mapping.dev"},
+		{Path: "provider.aws.account_mapping.prd"},
+		{Path: "apps[0].regions[1]"},
+		{Path: "apps[0].terraform_version"}, // not in any file
+		{Path: "templates_dir"},
+		{Message: "no key"},
+	}
+	require.NoError(t, SetIssueLines(issues, files))
+
+	var locations []string
+	for _, issue := range issues {
+		locations = append(locations, fmt.Sprintf("%s:%d", issue.File, issue.Line))
+	}
+	assert.Equal(t, []string{"base.yaml:5", ".tfskel.yaml:6", ".tfskel.yaml:11", ":0", ":0", ":0"}, locations)
+	assert.Equal(t, "line 10: apps[0].regions[1]: unknown region", Issue{Path: "apps[0].regions[1]", Line: 10, Message: "unknown region"}.String())
+
+	assert.Error(t, SetIssueLines(issues, []File{{Path: "broken.yaml", Content: []byte("provider: [")}}))
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// ErrUnsupportedConfigVersion indicates the config file was written for a newer tfskel
+var ErrUnsupportedConfigVersion = errors.New("config version is newer than this tfskel supports, upgrade tfskel")
+
+// CurrentVersion is the config layout version written by tfskel init and config migrate
+// Config files without a version key have version 1, it is one past the last migration
+const CurrentVersion = 1
+
+// migration rewrites the config file of one version into the layout of the next version
+type migration struct {
+	description string
+	apply       func(root *yaml.Node)
+}
+
+// migrations upgrade the config layout one version at a time, migrations[i] turns version i+1 into version i+2
+// A layout change adds its migration here and raises CurrentVersion
+var migrations []migration
+
+// FileVersion returns the layout version of the loaded config file
+func (c *Config) FileVersion() int {
+	if c.Version == 0 {
+		return 1
+	}
+	return c.Version
+}
+
+// Migrate rewrites a config file to CurrentVersion and returns the descriptions of the applied migrations
+// The YAML node tree is edited in place so comments are kept; content is returned as-is when it is current
+func Migrate(content []byte) ([]byte, []string, error) {
+	return migrate(content, migrations)
+}
+
+// migrate rewrites a config file with migrations to the version after the last migration
+func migrate(content []byte, migrations []migration) ([]byte, []string, error) {
+	current := len(migrations) + 1
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return content, nil, nil
+	}
+	root := doc.Content[0]
+
+	version := 1
+	if node := mappingValue(root, "version"); node != nil {
+		parsed, err := strconv.Atoi(node.Value)
+		if err != nil || parsed < 1 {
+			return nil, nil, fmt.Errorf("invalid config version '%s'", node.Value)
+		}
+		version = parsed
+	}
+	switch {
+	case version > current:
+		return nil, nil, fmt.Errorf("%w: version %d, supported up to %d", ErrUnsupportedConfigVersion, version, current)
+	case version == current:
+		return content, nil, nil
+	}
+
+	first := 0
+	if len(root.Content) > 0 {
+		first = root.Content[0].Line
+	}
+	var applied []string
+	for ; version < current; version++ {
+		migrations[version-1].apply(root)
+		applied = append(applied, migrations[version-1].description)
+	}
+	setVersion(root, current)
+	spaced := spacedKeys(root, strings.Split(string(content), "\n"), first)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	migrated, err := restoreBlankLines(buf.Bytes(), spaced)
+	if err != nil {
+		return nil, nil, err
+	}
+	return migrated, applied, nil
+}
+
+// spacedKeys returns the top-level keys that follow a blank line in the original lines of the file
+// The encoder drops these blank lines, the first key is skipped as its blank line belongs to the file head comment
+func spacedKeys(root *yaml.Node, lines []string, first int) map[string]bool {
+	spaced := make(map[string]bool)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if key.Line > first && blankAbove(lines, key.Line-1) {
+			spaced[key.Value] = true
+		}
+	}
+	return spaced
+}
+
+// restoreBlankLines adds a blank line above the spaced top-level keys of the encoded file and their head comments
+func restoreBlankLines(content []byte, spaced map[string]bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	root := doc.Content[0]
+	lines := strings.Split(string(content), "\n")
+	for i := len(root.Content) - 2; i >= 0; i -= 2 {
+		key := root.Content[i]
+		if !spaced[key.Value] {
+			continue
+		}
+		above := key.Line - 1
+		for above > 0 && strings.HasPrefix(lines[above-1], "#") {
+			above--
+		}
+		if above > 0 && !blankAbove(lines, key.Line-1) {
+			lines = slices.Insert(lines, above, "")
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// blankAbove reports whether the comment lines directly above line i (0-based) are preceded by a blank line
+func blankAbove(lines []string, i int) bool {
+	j := i - 1
+	for j >= 0 && strings.HasPrefix(lines[j], "#") {
+		j--
+	}
+	return j >= 0 && strings.TrimSpace(lines[j]) == ""
+}
+
+// setVersion sets the version key, a new key is added first and takes over the head comment of the file
+func setVersion(root *yaml.Node, version int) {
+	if node := mappingValue(root, "version"); node != nil {
+		node.Value = strconv.Itoa(version)
+		return
+	}
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+	if len(root.Content) > 0 {
+		key.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{key, value}, root.Content...)
+}
+
+// mappingValue returns the value of key in a mapping node, nil when the key is missing
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if i := keyIndex(mapping, key); i >= 0 {
+		return mapping.Content[i+1]
+	}
+	return nil
+}
+
+// keyIndex returns the index of key in the content of a mapping node, -1 when the key is missing
+func keyIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
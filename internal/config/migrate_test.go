@@ -0,0 +1,118 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v4"
+)
+
+// testMigrations renames the top-level key top_n to top_n_count, a layout change used to test migrate
+var testMigrations = []migration{
+	{"rename top_n to top_n_count", func(root *yaml.Node) {
+		if i := keyIndex(root, "top_n"); i >= 0 {
+			root.Content[i].Value = "top_n_count"
+		}
+	}},
+}
+
+func TestMigrate(t *testing.T) {
+	content := `# tfskel configuration file
+
+terraform_version: ~> 1.13 # pinned
+# Commented example that belongs to the file
+# region_aliases:
+#   ap-southeast-1: sg1
+
+# Summary rows of tfskel drift
+top_n: 15
+`
+	migrated, applied, err := migrate([]byte(content), testMigrations)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testMigrations[0].description}, applied)
+	assert.Equal(t, `# tfskel configuration file
+
+version: 2
+terraform_version: ~> 1.13 # pinned
+# Commented example that belongs to the file
+# region_aliases:
+#   ap-southeast-1: sg1
+
+# Summary rows of tfskel drift
+top_n_count: 15
+`, string(migrated))
+
+	again, applied, err := migrate(migrated, testMigrations)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Equal(t, migrated, again)
+}
+
+func TestMigrateCurrent(t *testing.T) {
+	assert.Equal(t, len(migrations)+1, CurrentVersion)
+
+	content := []byte("# tfskel configuration file\nterraform_version: ~> 1.13\n")
+	migrated, applied, err := Migrate(content)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Equal(t, content, migrated)
+}
+
+func TestMigrateBlankLines(t *testing.T) {
+	content := `# tfskel configuration file
+
+terraform_version: ~> 1.13
+
+provider:
+  aws:
+    version: ~> 6.0
+
+    regions: [us-east-1]
+
+# Summary rows of tfskel drift
+top_n: 15
+
+backend:
+  s3:
+    bucket_name: state
+`
+	migrated, _, err := migrate([]byte(content), testMigrations)
+	require.NoError(t, err)
+	assert.Equal(t, `# tfskel configuration file
+
+version: 2
+terraform_version: ~> 1.13
+
+provider:
+  aws:
+    version: ~> 6.0
+    regions: [us-east-1]
+
+# Summary rows of tfskel drift
+top_n_count: 15
+
+backend:
+  s3:
+    bucket_name: state
+`, string(migrated))
+}
+
+func TestMigrateErrors(t *testing.T) {
+	_, _, err := Migrate([]byte("version: 2\n"))
+	assert.ErrorIs(t, err, ErrUnsupportedConfigVersion)
+
+	_, _, err = migrate([]byte("version: 3\n"), testMigrations)
+	assert.ErrorIs(t, err, ErrUnsupportedConfigVersion)
+
+	_, _, err = Migrate([]byte("version: two\n"))
+	assert.ErrorContains(t, err, "invalid config version")
+
+	_, _, err = Migrate([]byte("provider: ["))
+	assert.Error(t, err)
+}
+
+func TestFileVersion(t *testing.T) {
+	assert.Equal(t, 1, (&Config{}).FileVersion())
+	assert.Equal(t, 2, (&Config{Version: 2}).FileVersion())
+}
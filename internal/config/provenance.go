@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// SourceDefault is the source of values that are set nowhere and fall back to a built-in default
+const SourceDefault = "default"
+
+// flagKeys maps the command line flags that override config keys, see applyFlagOverrides
+var flagKeys = map[string]string{
+	"templates-dir":             "templates_dir",
+	"s3-bucket-name":            "backend.s3.bucket_name",
+	"extra-template-extensions": "extra_template_extensions",
+	"create-github-workflows":   "generate.github_workflows.create",
+}
+
+// overridablePattern matches the keys environments.<env> and apps[] can override, see applyOverrides
+var overridablePattern = regexp.MustCompile(`^(terraform_version|backend\..+|vars\..+|provider\.[a-z]+\.(version|default_tags\..+|default_labels\..+))$`)
+
+// Setting is a resolved value and where it came from
+type Setting struct {
+	Key    string `json:"key" yaml:"key"`
+	Value  any    `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"` // flag --name, file:line, env NAME or default
+}
+
+// Sources tells where the value of a config key comes from
+// Precedence follows Load: flags, environment variables, the config files, then defaults
+type Sources struct {
+	cmd      *cobra.Command
+	v        *viper.Viper
+	files    []string         // Config files, the most general file first
+	lines    []map[string]int // Key lines of every config file
+	setVars  map[string]string
+	prefixes []string // Override sections checked before the top-level key, most specific first
+}
+
+// NewSources creates the sources of a configuration merged from files, see Config.Files
+// files is empty when no config file was found
+func NewSources(cmd *cobra.Command, v *viper.Viper, files []File) (*Sources, error) {
+	sources := &Sources{cmd: cmd, v: v}
+	for _, file := range files {
+		lines, err := KeyLines(file.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+		sources.files = append(sources.files, file.Path)
+		sources.lines = append(sources.lines, lines)
+	}
+	return sources, nil
+}
+
+// ForTarget returns sources that resolve overridable keys from the environments.<env>
+// and apps[] sections of the target first, like Config.ForTarget does
+func (s *Sources) ForTarget(c *Config, env, appName string) *Sources {
+	target := *s
+	target.setVars = c.SetVars
+	target.prefixes = nil
+	for i, app := range c.Apps {
+		if app.Name == appName {
+			target.prefixes = append(target.prefixes, fmt.Sprintf("apps[%d].", i))
+		}
+	}
+	if _, ok := c.Envs[env]; ok {
+		target.prefixes = append(target.prefixes, "environments."+env+".")
+	}
+	return &target
+}
+
+// Source returns where the value of a config key comes from
+func (s *Sources) Source(key string) string {
+	for _, name := range slices.Sorted(maps.Keys(flagKeys)) {
+		if flagKeys[name] == key && s.cmd.Flags().Changed(name) {
+			return "flag --" + name
+		}
+	}
+	if name, ok := strings.CutPrefix(key, "vars."); ok {
+		if _, set := s.setVars[name]; set {
+			return "flag --set"
+		}
+	}
+
+	if overridablePattern.MatchString(key) {
+		for _, prefix := range s.prefixes {
+			if source := s.fileSource(prefix + key); source != "" {
+				return source
+			}
+		}
+	}
+	if source := s.fileSource(key); source != "" {
+		return source
+	}
+	return SourceDefault
+}
+
+// fileSource returns the environment variable or the config file line a key is read from, empty when neither sets it
+// The most specific file that sets the key wins. viper.AutomaticEnv only applies to keys viper knows,
+// so variables for keys missing from the config files are ignored
+func (s *Sources) fileSource(key string) string {
+	file := -1
+	for i := len(s.lines) - 1; i >= 0 && file < 0; i-- {
+		if _, ok := s.lines[i][key]; ok {
+			file = i
+		}
+	}
+	if file >= 0 || slices.Contains(s.v.AllKeys(), key) {
+		if _, ok := os.LookupEnv(strings.ToUpper(key)); ok {
+			return "env " + strings.ToUpper(key)
+		}
+	}
+	if file >= 0 {
+		return fmt.Sprintf("%s:%d", s.files[file], s.lines[file][key])
+	}
+	return ""
+}
+
+// Settings lists the configuration resolved for an environment and app with the source of every value
+// The vars are resolved like they are for templates; env and appName may be empty
+func (c *Config) Settings(env, appName string, sources *Sources) []Setting {
+	resolved := c.ForTarget(env, appName)
+	resolved.Vars = c.ResolveVars(env, appName)
+
+	target := sources.ForTarget(c, env, appName)
+	settings := Flatten(resolved)
+	for i := range settings {
+		settings[i].Source = target.Source(settings[i].Key)
+	}
+	return settings
+}
+
+// Flatten lists the leaf values of a struct as dotted keys, e.g. provider.aws.version or apps[0].name
+// Keys are taken from mapstructure tags, or the field name for untagged structs. Maps are walked in key order,
+// slices of structs are indexed and other slices are kept as a single value. Nil, empty and zero numbers are left out
+func Flatten(value any) []Setting {
+	var settings []Setting
+	flatten("", reflect.ValueOf(value), &settings)
+	return settings
+}
+
+// flatten appends the leaf values below v to settings
+func flatten(key string, v reflect.Value, settings *[]Setting) {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		flatten(key, v.Elem(), settings)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, options, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			switch {
+			case name == "-":
+				continue
+			case options == "squash":
+				flatten(key, v.Field(i), settings)
+				continue
+			case name == "":
+				name = field.Name
+			}
+			flatten(joinKey(key, name), v.Field(i), settings)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for _, k := range keys {
+			flatten(joinKey(key, k.String()), v.MapIndex(k), settings)
+		}
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return
+		}
+		elem := v.Type().Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			*settings = append(*settings, Setting{Key: key, Value: v.Interface()})
+			return
+		}
+		for i := range v.Len() {
+			flatten(fmt.Sprintf("%s[%d]", key, i), v.Index(i), settings)
+		}
+	case reflect.String:
+		if v.String() != "" {
+			*settings = append(*settings, Setting{Key: key, Value: v.String()})
+		}
+	case reflect.Int:
+		if v.Int() != 0 {
+			*settings = append(*settings, Setting{Key: key, Value: v.Interface()})
+		}
+	default:
+		*settings = append(*settings, Setting{Key: key, Value: v.Interface()})
+	}
+}
+
+// joinKey appends name to a dotted key
+func joinKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
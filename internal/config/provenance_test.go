@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettings(t *testing.T) {
+	content := `terraform_version: "~> 1.13"
+provider:
+  aws:
+    account_mapping:
+      dev: "111111111111"
+    default_tags:
+      team: platform
+environments:
+  dev:
+    terraform_version: "~> 1.14"
+apps:
+  - name: payments
+    provider:
+      aws:
+        default_tags:
+          team: payments
+vars:
+  owner: platform
+  tier: gold
+`
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.AutomaticEnv()
+	require.NoError(t, v.ReadConfig(strings.NewReader(content)))
+	t.Setenv("VARS.TIER", "silver")
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringArray("set", nil, "")
+	require.NoError(t, cmd.Flags().Set("set", "owner=payments-team"))
+	cfg, err := Load(cmd, v)
+	require.NoError(t, err)
+
+	sources, err := NewSources(cmd, v, []File{{Path: ".tfskel.yaml", Content: []byte(content)}})
+	require.NoError(t, err)
+
+	got := make(map[string]Setting)
+	for _, setting := range cfg.Settings("dev", "payments", sources) {
+		got[setting.Key] = setting
+	}
+	assert.Equal(t, Setting{Key: "terraform_version", Value: "~> 1.14", Source: ".tfskel.yaml:10"}, got["terraform_version"])
+	assert.Equal(t, Setting{Key: "provider.aws.default_tags.team", Value: "payments", Source: ".tfskel.yaml:16"}, got["provider.aws.default_tags.team"])
+	assert.Equal(t, Setting{Key: "provider.aws.version", Value: "~> 6.0", Source: SourceDefault}, got["provider.aws.version"])
+	assert.Equal(t, Setting{Key: "vars.owner", Value: "payments-team", Source: "flag --set"}, got["vars.owner"])
+	assert.Equal(t, Setting{Key: "vars.tier", Value: "silver", Source: "env VARS.TIER"}, got["vars.tier"])
+
+	got = make(map[string]Setting)
+	for _, setting := range cfg.Settings("", "", sources) {
+		got[setting.Key] = setting
+	}
+	assert.Equal(t, Setting{Key: "terraform_version", Value: "~> 1.13", Source: ".tfskel.yaml:1"}, got["terraform_version"])
+	assert.Equal(t, Setting{Key: "provider.aws.default_tags.team", Value: "platform", Source: ".tfskel.yaml:7"}, got["provider.aws.default_tags.team"])
+}
+
+func TestFlatten(t *testing.T) {
+	settings := Flatten(&Config{
+		TerraformVersion: "~> 1.13",
+		Provider:         &Provider{AWS: &AWSProvider{Regions: []string{"eu-central-1"}, DefaultTags: map[string]string{"b": "2", "a": "1"}}},
+		Generate:         &Generate{GithubWorkflows: &GithubWorkflows{}},
+		Apps:             []App{{Name: "payments", Overrides: Overrides{TerraformVersion: "~> 1.14"}}},
+		SetVars:          map[string]string{"ignored": "true"},
+	})
+
+	assert.Equal(t, []Setting{
+		{Key: "terraform_version", Value: "~> 1.13"},
+		{Key: "provider.aws.default_tags.a", Value: "1"},
+		{Key: "provider.aws.default_tags.b", Value: "2"},
+		{Key: "provider.aws.regions", Value: []string{"eu-central-1"}},
+		{Key: "generate.github_workflows.create", Value: false},
+		{Key: "apps[0].name", Value: "payments"},
+		{Key: "apps[0].terraform_version", Value: "~> 1.14"},
+	}, settings)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// envsDir is the directory below the project root that holds envs/<env>/<region>/<app>
+const envsDir = "envs"
+
+// FindRoot returns the project root of dir: the nearest directory at or above dir that holds
+// .tfskel.yaml or an envs directory, unless it lies in the layout of a project above it.
+// Config files in the environment, region and app directories of layout.app_path are directory
+// overrides, the directory of the project they belong to wins. ok is false when dir is not inside a project
+func FindRoot(dir string) (root string, ok bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if isProjectRoot(dir) && (root == "" || inLayout(dir, root)) {
+			root = dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return root, root != ""
+		}
+		dir = parent
+	}
+}
+
+// isProjectRoot reports whether dir holds a config file or an envs directory
+func isProjectRoot(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, FileName)); err == nil && !info.IsDir() {
+		return true
+	}
+	info, err := os.Stat(filepath.Join(dir, envsDir))
+	return err == nil && info.IsDir()
+}
+
+// inLayout reports whether path is a directory of the app layout of the project at root,
+// e.g. root/envs/dev, or a directory below an app directory
+func inLayout(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || !filepath.IsLocal(rel) {
+		return false
+	}
+	segments, err := parseAppPath(rootLayout(root).appPathTemplate())
+	if err != nil {
+		segments, _ = parseAppPath(DefaultAppPath)
+	}
+
+	dirs := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		if i == len(dirs) {
+			break
+		}
+		if !segment.pattern.MatchString(dirs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// rootLayout reads layout.app_path from the config file of root and the files it extends
+// Unreadable files are left out, like a missing file they leave the default layout
+func rootLayout(root string) *Config {
+	cfg := &Config{}
+	files, err := ReadFiles(filepath.Join(root, FileName))
+	if err != nil {
+		return cfg
+	}
+	for _, file := range files {
+		var settings struct {
+			Layout struct {
+				AppPath string `yaml:"app_path"`
+			} `yaml:"layout"`
+		}
+		if err := yaml.Unmarshal(file.Content, &settings); err == nil && settings.Layout.AppPath != "" {
+			cfg.Layout = &Layout{AppPath: settings.Layout.AppPath}
+		}
+	}
+	return cfg
+}
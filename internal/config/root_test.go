@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRoot(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	app := filepath.Join(project, "envs", "dev", "eu-central-1", "payments")
+	require.NoError(t, os.MkdirAll(filepath.Join(app, "modules"), 0755))
+	writeFile(t, project, FileName, "terraform_version: \"~> 1.13\"\n")
+	writeFile(t, project, "envs/dev/"+FileName, "terraform_version: \"~> 1.14\"\n")
+	writeFile(t, app, FileName, "vars:\n  owner: payments\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "scratch"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "bare", "envs", "dev"), 0755))
+
+	// App directories of a custom layout may hold config files with only directory overrides
+	custom := filepath.Join(dir, "custom")
+	writeFile(t, custom, FileName, "layout:\n  app_path: '{{.AppDir}}/{{.Env}}'\n")
+	writeFile(t, custom, "payments/dev/"+FileName, "vars:\n  owner: payments\n")
+
+	// The layout may come from an extended file; projects outside the layout keep their own root
+	platform := filepath.Join(dir, "platform")
+	writeFile(t, dir, "base.yaml", "layout:\n  app_path: 'accounts/{{.Env}}/{{.Region}}/{{.AppDir}}'\n")
+	writeFile(t, platform, FileName, "extends: ../base.yaml\n")
+	writeFile(t, platform, "accounts/dev/"+FileName, "terraform_version: \"~> 1.14\"\n")
+	writeFile(t, platform, "tools/bootstrap/"+FileName, "terraform_version: \"~> 1.14\"\n")
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"project root", project, project},
+		{"directory config files do not make a root", filepath.Join(project, "envs", "dev"), project},
+		{"below an app", filepath.Join(app, "modules"), project},
+		{"envs directory without config", filepath.Join(dir, "bare", "envs", "dev"), filepath.Join(dir, "bare")},
+		{"app config file of a custom layout", filepath.Join(custom, "payments", "dev"), custom},
+		{"layout from an extended file", filepath.Join(platform, "accounts", "dev", "eu-central-1"), platform},
+		{"nested project outside the layout", filepath.Join(platform, "tools", "bootstrap"), filepath.Join(platform, "tools", "bootstrap")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, ok := FindRoot(tt.dir)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, root)
+		})
+	}
+
+	// The temp directory may itself be below a project, only check the result is not inside it
+	if root, ok := FindRoot(filepath.Join(dir, "scratch")); ok {
+		assert.False(t, isBelow(root, dir), root)
+	}
+}
+
+// isBelow reports whether path is parent or a directory below it
+func isBelow(path, parent string) bool {
+	rel, err := filepath.Rel(parent, path)
+	return err == nil && filepath.IsLocal(rel)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// schemaDialect is the JSON Schema dialect of Schema
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaDescriptions documents the top-level keys of the config file in the schema
+var schemaDescriptions = map[string]string{
+	"version":                   "Layout version of this file, upgrade older files with tfskel config migrate",
+	"extends":                   "Config file merged below this one, relative to this file, e.g. ../platform/tfskel-base.yaml",
+	"terraform_version":         "Terraform version constraint written to versions.tf and .terraform-version",
+	"provider":                  "Provider settings of the target cloud, configure one of aws, azurerm or google",
+	"backend":                   "State backend, type selects it and defaults to the configured block (s3 by default)",
+	"generate":                  "Settings of tfskel generate",
+	"templates_dir":             "Directory with custom templates that replace or extend the embedded ones",
+	"extra_template_extensions": "Template file extensions processed from templates_dir, tf.tmpl is always included",
+	"template_categories":       "Output directory of user-defined template categories, relative to the project root",
+	"apps":                      "Apps kept in place by tfskel sync, with their own overrides",
+	"vars":                      "User-defined template variables exposed as .Vars",
+	"environments":              "Overrides per environment",
+	"hooks":                     "Commands run after generate and init",
+	"region_aliases":            "Short codes of regions, overriding the embedded region catalog",
+	"layout":                    "Directory layout of the repository, layout.app_path defaults to envs/{{.Env}}/{{.Region}}/{{.AppDir}}",
+	"critical_resources":        "Resource types added to the default critical resources of tfskel drift",
+	"top_n_count":               "Number of rows in the summary tables of tfskel drift, 0 shows all",
+}
+
+// Schema returns the JSON Schema of the config file, generated from the mapstructure tags of Config
+// extra adds the top-level keys of structs read by other packages, e.g. drift.DriftConfig{}
+func Schema(extra ...any) map[string]any {
+	schema := typeSchema(reflect.TypeFor[Config]())
+	properties := schema["properties"].(map[string]any)
+	for _, value := range extra {
+		maps.Copy(properties, typeSchema(reflect.TypeOf(value))["properties"].(map[string]any))
+	}
+	for key, description := range schemaDescriptions {
+		if property, ok := properties[key].(map[string]any); ok {
+			property["description"] = description
+		}
+	}
+
+	properties["version"] = map[string]any{
+		"type":        "integer",
+		"minimum":     1,
+		"maximum":     CurrentVersion,
+		"description": schemaDescriptions["version"],
+	}
+	schemaProperty(schema, "backend", "type")["enum"] = BackendTypes
+	for _, stage := range []string{"post_generate", "post_init"} {
+		hook := schemaProperty(schema, "hooks", stage)["items"].(map[string]any)
+		hook["required"] = []string{"command"}
+		hook["properties"].(map[string]any)["on_failure"].(map[string]any)["enum"] = []string{HookFail, HookWarn}
+	}
+	schemaProperty(schema, "region_aliases")["additionalProperties"] = map[string]any{
+		"type":    "string",
+		"pattern": regionAliasPattern.String(),
+	}
+	schemaProperty(schema, "apps")["items"].(map[string]any)["required"] = []string{"name"}
+
+	schema["$schema"] = schemaDialect
+	schema["title"] = "tfskel configuration (.tfskel.yaml)"
+	return schema
+}
+
+// schemaProperty returns the schema of a nested object property
+func schemaProperty(schema map[string]any, path ...string) map[string]any {
+	for _, name := range path {
+		schema = schema["properties"].(map[string]any)[name].(map[string]any)
+	}
+	return schema
+}
+
+// typeSchema returns the JSON Schema of a Go type, objects use the mapstructure tags of their fields
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.Struct:
+		properties := make(map[string]any)
+		addStructProperties(t, properties)
+		return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+// addStructProperties adds the schema of every mapstructure field of t to properties, squashed structs are inlined
+func addStructProperties(t reflect.Type, properties map[string]any) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		switch {
+		case !field.IsExported() || name == "-":
+		case options == "squash":
+			addStructProperties(field.Type, properties)
+		case name != "":
+			properties[name] = typeSchema(field.Type)
+		}
+	}
+}
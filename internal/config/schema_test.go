@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	type extra struct {
+		TopN int `mapstructure:"top_n_count"`
+	}
+	schema := Schema(extra{})
+
+	assert.Equal(t, schemaDialect, schema["$schema"])
+	assert.Equal(t, false, schema["additionalProperties"])
+	assert.Equal(t, CurrentVersion, schemaProperty(schema, "version")["maximum"])
+	assert.Equal(t, map[string]any{"type": "string"}, schemaProperty(schema, "provider", "aws", "version"))
+	assert.Equal(t, BackendTypes, schemaProperty(schema, "backend", "type")["enum"])
+	assert.Equal(t, "array", schemaProperty(schema, "provider", "aws", "regions")["type"])
+	assert.Equal(t, "integer", schemaProperty(schema, "top_n_count")["type"])
+	assert.Equal(t, schemaDescriptions["top_n_count"], schemaProperty(schema, "top_n_count")["description"])
+
+	app := schemaProperty(schema, "apps")["items"].(map[string]any)
+	assert.Equal(t, []string{"name"}, app["required"])
+	assert.Contains(t, app["properties"], "terraform_version", "squashed overrides are inlined")
+}
@@ -2,6 +2,7 @@ package drift
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -36,12 +37,13 @@ func (a *Analyzer) Analyze(scanRoot string, versionInfos []VersionInfo) *DriftRe
 	}
 
 	for _, info := range versionInfos {
-		if info.ParseError != nil {
+		cfg, err := a.configFor(scanRoot, info.FilePath)
+		if info.ParseError != nil || err != nil {
 			report.Summary.FilesWithErrors++
 			continue
 		}
 
-		record := a.analyzeVersionInfo(info)
+		record := a.analyzeVersionInfo(cfg, info)
 		report.Records = append(report.Records, record)
 
 		// Update summary statistics
@@ -89,23 +91,20 @@ func categorizeDriftSeverity(summary *DriftSummary, record DriftRecord) {
 }
 
 // analyzeVersionInfo compares a single version info against config
-func (a *Analyzer) analyzeVersionInfo(info VersionInfo) DriftRecord {
+// cfg is resolved for the file by configFor, so that directory config files and
+// environments.<env> and apps[] overrides are honored
+func (a *Analyzer) analyzeVersionInfo(cfg *config.Config, info VersionInfo) DriftRecord {
 	record := DriftRecord{
 		FilePath:             info.FilePath,
-		TerraformExpected:    a.config.TerraformVersion,
+		TerraformExpected:    cfg.TerraformVersion,
 		TerraformActual:      info.TerraformVersion,
-		TerraformDriftStatus: a.compareTerraformVersion(a.config.TerraformVersion, info.TerraformVersion),
+		TerraformDriftStatus: a.compareTerraformVersion(cfg.TerraformVersion, info.TerraformVersion),
 	}
 
 	// Analyze providers
 	for providerName, providerVer := range info.Providers {
-		var expected string
-
-		// Currently only support AWS provider from config
-		// Can be extended to support more providers
-		if providerName == "aws" && a.config.Provider != nil && a.config.Provider.AWS != nil {
-			expected = a.config.Provider.AWS.Version
-		}
+		// Providers tfskel does not configure are reported as not managed
+		expected := cfg.ProviderVersion(providerName)
 
 		drift := ProviderDrift{
 			Name:        providerName,
@@ -130,6 +129,17 @@ func (a *Analyzer) analyzeVersionInfo(info VersionInfo) DriftRecord {
 	return record
 }
 
+// configFor returns the configuration resolved for the directory, environment and app of a scanned file
+func (a *Analyzer) configFor(scanRoot, filePath string) (*config.Config, error) {
+	path := filepath.Join(scanRoot, filePath)
+	dirConfig, err := a.config.ForDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	env, appName := a.config.TargetFromPath(path)
+	return dirConfig.ForTarget(env, appName), nil
+}
+
 // compareTerraformVersion compares terraform versions and returns drift status
 func (a *Analyzer) compareTerraformVersion(expected, actual string) DriftStatus {
 	if actual == "" {
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/ishuar/tfskel/internal/config"
 )
@@ -194,6 +195,97 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	}
 }
 
+func TestAnalyzer_Analyze_Overrides(t *testing.T) {
+	cfg := &config.Config{
+		TerraformVersion: "~> 1.13",
+		Provider: &config.Provider{
+			AWS: &config.AWSProvider{Version: "~> 6.0"},
+		},
+		Envs: map[string]config.Environment{
+			"dev": {Overrides: config.Overrides{TerraformVersion: "~> 1.14"}},
+		},
+		Apps: []config.App{
+			{Name: "payments", Overrides: config.Overrides{Provider: &config.Provider{AWS: &config.AWSProvider{Version: "~> 6.2"}}}},
+		},
+	}
+	analyzer := NewAnalyzer(cfg)
+
+	newInfo := func(path, tfVersion, awsVersion string) VersionInfo {
+		return VersionInfo{
+			FilePath:         path,
+			TerraformVersion: tfVersion,
+			Providers:        map[string]ProviderVer{"aws": {Version: awsVersion, Source: "hashicorp/aws"}},
+		}
+	}
+	report := analyzer.Analyze("/repo", []VersionInfo{
+		newInfo("envs/dev/eu-central-1/orders/versions.tf", "~> 1.14", "~> 6.0"),
+		newInfo("envs/prd/eu-central-1/orders/versions.tf", "~> 1.13", "~> 6.0"),
+		newInfo("envs/dev/eu-central-1/payments/versions.tf", "~> 1.14", "~> 6.2"),
+		newInfo("envs/prd/eu-central-1/payments/versions.tf", "~> 1.14", "~> 6.2"),
+	})
+
+	expected := map[string]string{
+		"envs/dev/eu-central-1/orders/versions.tf":   "~> 1.14",
+		"envs/prd/eu-central-1/orders/versions.tf":   "~> 1.13",
+		"envs/dev/eu-central-1/payments/versions.tf": "~> 1.14",
+		"envs/prd/eu-central-1/payments/versions.tf": "~> 1.13",
+	}
+	for _, record := range report.Records {
+		assert.Equal(t, expected[record.FilePath], record.TerraformExpected, record.FilePath)
+	}
+	assert.Equal(t, "~> 6.2", report.Records[2].Providers[0].Expected)
+	assert.Equal(t, 3, report.Summary.FilesInSync)
+	assert.Equal(t, 1, report.FilesWithDrift, "only prd payments drifts from the global terraform version")
+}
+
+func TestAnalyzer_Analyze_CloudProviders(t *testing.T) {
+	cfg := &config.Config{
+		Provider: &config.Provider{
+			AzureRM: &config.AzureRMProvider{Version: "~> 4.0"},
+			Google:  &config.GoogleProvider{Version: "~> 7.0"},
+		},
+		Envs: map[string]config.Environment{
+			"dev": {Overrides: config.Overrides{Provider: &config.Provider{AzureRM: &config.AzureRMProvider{Version: "~> 4.2"}}}},
+		},
+	}
+	analyzer := NewAnalyzer(cfg)
+
+	report := analyzer.Analyze("/repo", []VersionInfo{
+		{
+			FilePath: "envs/dev/westeurope/orders/versions.tf",
+			Providers: map[string]ProviderVer{
+				"azurerm": {Version: "~> 4.2", Source: "hashicorp/azurerm"},
+			},
+		},
+		{
+			FilePath: "envs/prd/westeurope/orders/versions.tf",
+			Providers: map[string]ProviderVer{
+				"azurerm": {Version: "~> 4.2", Source: "hashicorp/azurerm"},
+				"google":  {Version: "~> 7.0", Source: "hashicorp/google"},
+			},
+		},
+	})
+
+	require.Len(t, report.Records, 2)
+	dev := report.Records[0]
+	require.Len(t, dev.Providers, 1)
+	assert.Equal(t, "~> 4.2", dev.Providers[0].Expected)
+	assert.Equal(t, StatusInSync, dev.Providers[0].DriftStatus)
+
+	prd := report.Records[1]
+	require.Len(t, prd.Providers, 2)
+	for _, provider := range prd.Providers {
+		switch provider.Name {
+		case "azurerm":
+			assert.Equal(t, "~> 4.0", provider.Expected)
+			assert.Equal(t, StatusMinorDrift, provider.DriftStatus)
+		case "google":
+			assert.Equal(t, "~> 7.0", provider.Expected)
+			assert.Equal(t, StatusInSync, provider.DriftStatus)
+		}
+	}
+}
+
 func TestAnalyzer_CompareSemverConstraints(t *testing.T) {
 	analyzer := NewAnalyzer(&config.Config{})
 
@@ -0,0 +1,275 @@
+package fs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Action describes what would happen to a path
+type Action string
+
+const (
+	// ActionCreate means the path does not exist and would be created
+	ActionCreate Action = "create"
+	// ActionUpdate means the file exists and would be overwritten
+	ActionUpdate Action = "update"
+	// ActionSkip means the path would be left untouched
+	ActionSkip Action = "skip"
+	// ActionDelete means the path exists and would be deleted
+	ActionDelete Action = "delete"
+)
+
+// Change is a single intended filesystem change recorded during a dry run
+type Change struct {
+	Action Action
+	Path   string
+	IsDir  bool
+	Reason string
+}
+
+// Recorder is implemented by filesystems that record changes instead of applying them
+// Callers use it to explain why a path would be skipped or changed
+type Recorder interface {
+	// Record adds a change that is not the result of a write, e.g. a skipped file
+	Record(change Change)
+	// Explain attaches a reason to the change already recorded for path
+	Explain(path, reason string)
+	// Changes returns the recorded changes in the order they were made
+	Changes() []Change
+}
+
+// DryRunFileSystem records writes instead of applying them
+// Reads fall through to the base filesystem so that existing files are detected,
+// while paths written during the run are visible to later reads from an in-memory overlay
+type DryRunFileSystem struct {
+	mu      sync.Mutex
+	base    FileSystem
+	overlay *MemoryFileSystem
+	changes []Change
+	index   map[string]int
+	removed []string // Paths deleted during the run, hidden from later reads of the base filesystem
+}
+
+// NewDryRunFileSystem creates a DryRunFileSystem on top of base
+func NewDryRunFileSystem(base FileSystem) *DryRunFileSystem {
+	return &DryRunFileSystem{
+		base:    base,
+		overlay: NewMemoryFileSystem(),
+		index:   make(map[string]int),
+	}
+}
+
+// MkdirAll records the directory creation if the directory does not exist yet
+func (fs *DryRunFileSystem) MkdirAll(path string, perm os.FileMode) error {
+	if !fs.DirExists(path) {
+		fs.Record(Change{Action: ActionCreate, Path: path, IsDir: true})
+	}
+	return fs.overlay.MkdirAll(path, perm)
+}
+
+// WriteFile records the file as created or updated depending on whether it exists
+func (fs *DryRunFileSystem) WriteFile(path string, data []byte, perm os.FileMode) error {
+	action := ActionCreate
+	if fs.FileExists(path) {
+		action = ActionUpdate
+	}
+	fs.Record(Change{Action: action, Path: path})
+	return fs.overlay.WriteFile(path, data, perm)
+}
+
+// ReadFile reads from the overlay first and falls back to the base filesystem
+func (fs *DryRunFileSystem) ReadFile(path string) ([]byte, error) {
+	if data, err := fs.overlay.ReadFile(path); err == nil {
+		return data, nil
+	}
+	if fs.isRemoved(path) {
+		return nil, os.ErrNotExist
+	}
+	return fs.base.ReadFile(path)
+}
+
+// FileExists checks the overlay and the base filesystem
+func (fs *DryRunFileSystem) FileExists(path string) bool {
+	return fs.overlay.FileExists(path) || (!fs.isRemoved(path) && fs.base.FileExists(path))
+}
+
+// DirExists checks the overlay and the base filesystem
+func (fs *DryRunFileSystem) DirExists(path string) bool {
+	return fs.overlay.DirExists(path) || (!fs.isRemoved(path) && fs.base.DirExists(path))
+}
+
+// ListFiles merges the files of the overlay and the base filesystem
+func (fs *DryRunFileSystem) ListFiles(path string) ([]string, error) {
+	baseFiles, baseErr := fs.base.ListFiles(path)
+	if baseErr != nil && !errors.Is(baseErr, os.ErrNotExist) {
+		return nil, baseErr
+	}
+	overlayFiles, overlayErr := fs.overlay.ListFiles(path)
+	if baseErr != nil && overlayErr != nil {
+		return nil, baseErr
+	}
+
+	var files []string
+	for _, file := range baseFiles {
+		if !fs.isRemoved(file) {
+			files = append(files, file)
+		}
+	}
+	for _, file := range overlayFiles {
+		if !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Remove records the deletion of a file
+func (fs *DryRunFileSystem) Remove(path string) error {
+	if !fs.FileExists(path) {
+		return os.ErrNotExist
+	}
+	fs.Record(Change{Action: ActionDelete, Path: path})
+	fs.markRemoved(path)
+	return fs.overlay.RemoveAll(path)
+}
+
+// RemoveAll records the deletion of a directory and everything it contains
+func (fs *DryRunFileSystem) RemoveAll(path string) error {
+	switch {
+	case fs.FileExists(path):
+		fs.Record(Change{Action: ActionDelete, Path: path})
+	case fs.DirExists(path):
+		fs.Record(Change{Action: ActionDelete, Path: path, IsDir: true})
+	default:
+		if files, err := fs.ListFiles(path); err == nil && len(files) > 0 {
+			fs.Record(Change{Action: ActionDelete, Path: path, IsDir: true})
+		}
+	}
+	fs.markRemoved(path)
+	return fs.overlay.RemoveAll(path)
+}
+
+// Rename records the move as the deletion of oldPath and the creation of newPath
+// The moved files are copied to the overlay so later reads see them at newPath
+func (fs *DryRunFileSystem) Rename(oldPath, newPath string) error {
+	if fs.FileExists(oldPath) {
+		data, err := fs.ReadFile(oldPath)
+		if err != nil {
+			return err
+		}
+		fs.Record(Change{Action: ActionDelete, Path: oldPath})
+		fs.Record(Change{Action: ActionCreate, Path: newPath, Reason: "moved from " + oldPath})
+		fs.markRemoved(oldPath)
+		return fs.overlay.WriteFile(newPath, data, 0644)
+	}
+
+	files, err := fs.ListFiles(oldPath)
+	if err != nil {
+		return err
+	}
+	fs.Record(Change{Action: ActionDelete, Path: oldPath, IsDir: true})
+	fs.Record(Change{Action: ActionCreate, Path: newPath, IsDir: true, Reason: "moved from " + oldPath})
+	for _, file := range files {
+		data, err := fs.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(oldPath, file)
+		if err != nil {
+			return err
+		}
+		if err := fs.overlay.WriteFile(filepath.Join(newPath, rel), data, 0644); err != nil {
+			return err
+		}
+	}
+	fs.markRemoved(oldPath)
+	_ = fs.overlay.RemoveAll(oldPath) //nolint:errcheck // the overlay never fails to remove
+	return fs.overlay.MkdirAll(newPath, 0755)
+}
+
+// markRemoved hides path and everything under it in the base filesystem
+func (fs *DryRunFileSystem) markRemoved(path string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.removed = append(fs.removed, filepath.Clean(path))
+}
+
+// isRemoved reports whether path or one of its parents was deleted during the run
+func (fs *DryRunFileSystem) isRemoved(path string) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	path = filepath.Clean(path)
+	for _, removed := range fs.removed {
+		if path == removed || strings.HasPrefix(path, removed+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDirs merges the directories of the overlay and the base filesystem
+// Directories of the base filesystem removed during the run are left out, like in ListFiles
+func (fs *DryRunFileSystem) ListDirs(path string) ([]string, error) {
+	baseDirs, baseErr := fs.base.ListDirs(path)
+	if baseErr != nil && !errors.Is(baseErr, os.ErrNotExist) {
+		return nil, baseErr
+	}
+	overlayDirs, overlayErr := fs.overlay.ListDirs(path)
+	if baseErr != nil && overlayErr != nil {
+		return nil, baseErr
+	}
+
+	seen := make(map[string]bool, len(baseDirs)+len(overlayDirs))
+	var dirs []string
+	for _, name := range baseDirs {
+		if !fs.isRemoved(filepath.Join(path, name)) {
+			seen[name] = true
+			dirs = append(dirs, name)
+		}
+	}
+	for _, name := range overlayDirs {
+		if !seen[name] {
+			seen[name] = true
+			dirs = append(dirs, name)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// Record adds a change for a path
+// Only the first change per path is kept, so a file that is updated and then
+// reported as existing shows up once as an update
+func (fs *DryRunFileSystem) Record(change Change) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, ok := fs.index[change.Path]; ok {
+		return
+	}
+	fs.index[change.Path] = len(fs.changes)
+	fs.changes = append(fs.changes, change)
+}
+
+// Explain sets the reason of the change recorded for path, if any
+func (fs *DryRunFileSystem) Explain(path, reason string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if i, ok := fs.index[path]; ok {
+		fs.changes[i].Reason = reason
+	}
+}
+
+// Changes returns a copy of the recorded changes
+func (fs *DryRunFileSystem) Changes() []Change {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	changes := make([]Change, len(fs.changes))
+	copy(changes, fs.changes)
+	return changes
+}
@@ -0,0 +1,127 @@
+package fs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunFileSystem(t *testing.T) {
+	newFS := func(t *testing.T) (*MemoryFileSystem, *DryRunFileSystem) {
+		t.Helper()
+		base := NewMemoryFileSystem()
+		require.NoError(t, base.MkdirAll("envs/dev", 0755))
+		require.NoError(t, base.WriteFile("envs/dev/existing.tf", []byte("old"), 0644))
+		return base, NewDryRunFileSystem(base)
+	}
+
+	t.Run("records writes without touching the base filesystem", func(t *testing.T) {
+		base, dryRun := newFS(t)
+
+		require.NoError(t, dryRun.MkdirAll("envs/dev", 0755))
+		require.NoError(t, dryRun.MkdirAll("envs/dev/app", 0755))
+		require.NoError(t, dryRun.WriteFile("envs/dev/app/main.tf", []byte("new"), 0644))
+		require.NoError(t, dryRun.WriteFile("envs/dev/existing.tf", []byte("changed"), 0644))
+
+		expected := []Change{
+			{Action: ActionCreate, Path: "envs/dev/app", IsDir: true},
+			{Action: ActionCreate, Path: "envs/dev/app/main.tf"},
+			{Action: ActionUpdate, Path: "envs/dev/existing.tf"},
+		}
+		assert.Equal(t, expected, dryRun.Changes())
+
+		assert.False(t, base.DirExists("envs/dev/app"))
+		assert.False(t, base.FileExists("envs/dev/app/main.tf"))
+		content, err := base.ReadFile("envs/dev/existing.tf")
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(content))
+	})
+
+	t.Run("reads see pending writes", func(t *testing.T) {
+		_, dryRun := newFS(t)
+
+		require.NoError(t, dryRun.WriteFile("envs/dev/existing.tf", []byte("changed"), 0644))
+		content, err := dryRun.ReadFile("envs/dev/existing.tf")
+		require.NoError(t, err)
+		assert.Equal(t, "changed", string(content))
+
+		require.NoError(t, dryRun.WriteFile("envs/dev/new.tf", []byte("new"), 0644))
+		assert.True(t, dryRun.FileExists("envs/dev/new.tf"))
+
+		_, err = dryRun.ReadFile("envs/dev/missing.tf")
+		assert.Error(t, err)
+	})
+
+	t.Run("first change per path wins and can be explained", func(t *testing.T) {
+		_, dryRun := newFS(t)
+
+		require.NoError(t, dryRun.WriteFile("envs/dev/existing.tf", []byte("changed"), 0644))
+		dryRun.Explain("envs/dev/existing.tf", "bucket changed")
+		dryRun.Record(Change{Action: ActionSkip, Path: "envs/dev/existing.tf", Reason: "already exists"})
+		dryRun.Explain("envs/dev/unknown.tf", "ignored")
+
+		expected := []Change{
+			{Action: ActionUpdate, Path: "envs/dev/existing.tf", Reason: "bucket changed"},
+		}
+		assert.Equal(t, expected, dryRun.Changes())
+	})
+
+	t.Run("records deletes and hides removed paths", func(t *testing.T) {
+		base, dryRun := newFS(t)
+		require.NoError(t, base.WriteFile("envs/dev/app/main.tf", []byte("x"), 0644))
+		require.NoError(t, base.WriteFile("envs/dev/app/backend.tf", []byte("x"), 0644))
+
+		files, err := dryRun.ListFiles("envs/dev/app")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"envs/dev/app/backend.tf", "envs/dev/app/main.tf"}, files)
+
+		require.NoError(t, dryRun.Remove("envs/dev/existing.tf"))
+		require.NoError(t, dryRun.RemoveAll("envs/dev/app"))
+
+		expected := []Change{
+			{Action: ActionDelete, Path: "envs/dev/existing.tf"},
+			{Action: ActionDelete, Path: "envs/dev/app", IsDir: true},
+		}
+		assert.Equal(t, expected, dryRun.Changes())
+		assert.False(t, dryRun.FileExists("envs/dev/existing.tf"))
+		assert.False(t, dryRun.FileExists("envs/dev/app/main.tf"))
+		assert.True(t, base.FileExists("envs/dev/app/main.tf"))
+	})
+
+	t.Run("hides removed directories from listings", func(t *testing.T) {
+		base, dryRun := newFS(t)
+		require.NoError(t, base.WriteFile("envs/dev/us-east-1/orders/main.tf", []byte("x"), 0644))
+		require.NoError(t, base.WriteFile("envs/dev/us-east-1/payments/main.tf", []byte("x"), 0644))
+
+		require.NoError(t, dryRun.RemoveAll("envs/dev/us-east-1/payments"))
+		dirs, err := dryRun.ListDirs("envs/dev/us-east-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders"}, dirs)
+
+		// A directory created again during the run is listed
+		require.NoError(t, dryRun.MkdirAll("envs/dev/us-east-1/payments", 0755))
+		dirs, err = dryRun.ListDirs("envs/dev/us-east-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders", "payments"}, dirs)
+	})
+
+	t.Run("records moves and reads from the new path", func(t *testing.T) {
+		base, dryRun := newFS(t)
+		require.NoError(t, base.WriteFile("envs/dev/app/main.tf", []byte("x"), 0644))
+
+		require.NoError(t, dryRun.Rename("envs/dev/app", "envs/dev/billing"))
+
+		expected := []Change{
+			{Action: ActionDelete, Path: "envs/dev/app", IsDir: true},
+			{Action: ActionCreate, Path: "envs/dev/billing", IsDir: true, Reason: "moved from envs/dev/app"},
+		}
+		assert.Equal(t, expected, dryRun.Changes())
+		assert.False(t, dryRun.DirExists("envs/dev/app"))
+		content, err := dryRun.ReadFile("envs/dev/billing/main.tf")
+		require.NoError(t, err)
+		assert.Equal(t, "x", string(content))
+		assert.True(t, base.FileExists("envs/dev/app/main.tf"))
+		assert.False(t, base.DirExists("envs/dev/billing"))
+	})
+}
@@ -1,8 +1,10 @@
 package fs
 
 import (
+	iofs "io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 // FileSystem provides an abstraction over filesystem operations
@@ -22,6 +24,21 @@ type FileSystem interface {
 
 	// DirExists checks if a directory exists
 	DirExists(path string) bool
+
+	// ListDirs returns the sorted names of the directories directly under path
+	ListDirs(path string) ([]string, error)
+
+	// ListFiles returns the sorted paths of all files under path, including nested directories
+	ListFiles(path string) ([]string, error)
+
+	// Remove deletes a file
+	Remove(path string) error
+
+	// RemoveAll deletes path and everything it contains
+	RemoveAll(path string) error
+
+	// Rename moves a file or directory, creating the parent directories of newPath as needed
+	Rename(oldPath, newPath string) error
 }
 
 // OSFileSystem implements FileSystem using the real OS filesystem
@@ -69,3 +86,56 @@ func (fs *OSFileSystem) DirExists(path string) bool {
 	}
 	return info.IsDir()
 }
+
+// ListDirs returns the sorted names of the directories directly under path
+func (fs *OSFileSystem) ListDirs(path string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// ListFiles returns the sorted paths of all files under path
+func (fs *OSFileSystem) ListFiles(path string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(path, func(p string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Remove deletes a file
+func (fs *OSFileSystem) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// RemoveAll deletes path and everything it contains
+func (fs *OSFileSystem) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+// Rename moves a file or directory
+func (fs *OSFileSystem) Rename(oldPath, newPath string) error {
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
@@ -246,3 +246,118 @@ func TestMemoryFileSystem_Integration(t *testing.T) {
 		assert.Equal(t, testContent, content)
 	})
 }
+
+func TestListDirs(t *testing.T) {
+	t.Run("os filesystem lists sorted directories only", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		fs := NewOSFileSystem()
+		require.NoError(t, fs.MkdirAll(filepath.Join(tmpDir, "prd"), 0755))
+		require.NoError(t, fs.MkdirAll(filepath.Join(tmpDir, "dev"), 0755))
+		require.NoError(t, fs.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("x"), 0644))
+
+		dirs, err := fs.ListDirs(tmpDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dev", "prd"}, dirs)
+
+		_, err = fs.ListDirs(filepath.Join(tmpDir, "missing"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("memory filesystem includes parents of nested paths", func(t *testing.T) {
+		fs := NewMemoryFileSystem()
+		require.NoError(t, fs.MkdirAll("envs/dev/eu-central-1/payments", 0755))
+		require.NoError(t, fs.WriteFile("envs/prd/eu-central-1/orders/main.tf", []byte("x"), 0644))
+		require.NoError(t, fs.WriteFile("envs/README.md", []byte("x"), 0644))
+
+		dirs, err := fs.ListDirs("envs")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dev", "prd"}, dirs)
+
+		dirs, err = fs.ListDirs("envs/prd/eu-central-1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders"}, dirs)
+
+		dirs, err = fs.ListDirs(".")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"envs"}, dirs)
+
+		_, err = fs.ListDirs("missing")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("dry run filesystem merges overlay and base", func(t *testing.T) {
+		base := NewMemoryFileSystem()
+		require.NoError(t, base.MkdirAll("envs/dev", 0755))
+		dryRun := NewDryRunFileSystem(base)
+		require.NoError(t, dryRun.MkdirAll("envs/prd", 0755))
+
+		dirs, err := dryRun.ListDirs("envs")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dev", "prd"}, dirs)
+	})
+}
+
+func TestListFilesAndRemove(t *testing.T) {
+	t.Run("os filesystem", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		fs := NewOSFileSystem()
+		appDir := filepath.Join(tmpDir, "payments")
+		require.NoError(t, fs.WriteFile(filepath.Join(appDir, "main.tf"), []byte("x"), 0644))
+		require.NoError(t, fs.WriteFile(filepath.Join(appDir, "modules", "vpc.tf"), []byte("x"), 0644))
+
+		files, err := fs.ListFiles(appDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(appDir, "main.tf"), filepath.Join(appDir, "modules", "vpc.tf")}, files)
+
+		require.NoError(t, fs.Remove(filepath.Join(appDir, "main.tf")))
+		assert.False(t, fs.FileExists(filepath.Join(appDir, "main.tf")))
+
+		require.NoError(t, fs.RemoveAll(appDir))
+		assert.False(t, fs.DirExists(appDir))
+	})
+
+	t.Run("memory filesystem", func(t *testing.T) {
+		fs := NewMemoryFileSystem()
+		require.NoError(t, fs.MkdirAll("envs/dev/payments", 0755))
+		require.NoError(t, fs.WriteFile("envs/dev/payments/main.tf", []byte("x"), 0644))
+		require.NoError(t, fs.WriteFile("envs/dev/payments/modules/vpc.tf", []byte("x"), 0644))
+		require.NoError(t, fs.WriteFile("envs/dev/payments-v2/main.tf", []byte("x"), 0644))
+
+		files, err := fs.ListFiles("envs/dev/payments")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"envs/dev/payments/main.tf", "envs/dev/payments/modules/vpc.tf"}, files)
+
+		assert.ErrorIs(t, fs.Remove("envs/dev/payments/missing.tf"), os.ErrNotExist)
+		require.NoError(t, fs.RemoveAll("envs/dev/payments"))
+		assert.False(t, fs.DirExists("envs/dev/payments"))
+		assert.False(t, fs.FileExists("envs/dev/payments/modules/vpc.tf"))
+		assert.True(t, fs.FileExists("envs/dev/payments-v2/main.tf"))
+	})
+}
+
+func TestRename(t *testing.T) {
+	t.Run("os filesystem", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		fs := NewOSFileSystem()
+		from, to := filepath.Join(tmpDir, "us-east-1", "payments"), filepath.Join(tmpDir, "eu-west-1", "billing")
+		require.NoError(t, fs.WriteFile(filepath.Join(from, "main.tf"), []byte("x"), 0644))
+
+		require.NoError(t, fs.Rename(from, to))
+		assert.False(t, fs.DirExists(from))
+		assert.True(t, fs.FileExists(filepath.Join(to, "main.tf")))
+	})
+
+	t.Run("memory filesystem", func(t *testing.T) {
+		fs := NewMemoryFileSystem()
+		require.NoError(t, fs.WriteFile("envs/dev/payments/main.tf", []byte("x"), 0644))
+		require.NoError(t, fs.WriteFile("envs/dev/payments/.terraform/terraform.tfstate", []byte("x"), 0644))
+		require.NoError(t, fs.WriteFile("envs/dev/payments-v2/main.tf", []byte("x"), 0644))
+
+		require.NoError(t, fs.Rename("envs/dev/payments", "envs/dev/billing"))
+		assert.False(t, fs.DirExists("envs/dev/payments"))
+		assert.True(t, fs.FileExists("envs/dev/billing/main.tf"))
+		assert.True(t, fs.FileExists("envs/dev/billing/.terraform/terraform.tfstate"))
+		assert.True(t, fs.FileExists("envs/dev/payments-v2/main.tf"))
+		assert.ErrorIs(t, fs.Rename("envs/dev/missing", "envs/dev/other"), os.ErrNotExist)
+	})
+}
@@ -2,6 +2,9 @@ package fs
 
 import (
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -62,3 +65,136 @@ func (fs *MemoryFileSystem) DirExists(path string) bool {
 	_, ok := fs.dirs[path]
 	return ok
 }
+
+// ListDirs returns the sorted names of the directories directly under path
+// Parent directories of created directories and written files are listed as well
+func (fs *MemoryFileSystem) ListDirs(path string) ([]string, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	// Stored paths are relative to the working directory, so "." is a prefix of all of them
+	prefix := ""
+	if path := filepath.Clean(path); path != "." {
+		prefix = path + string(filepath.Separator)
+	}
+	found := false
+	names := make(map[string]bool)
+	collect := func(p string, isDir bool) {
+		rest, ok := strings.CutPrefix(filepath.Clean(p), prefix)
+		if !ok || filepath.IsAbs(rest) {
+			return
+		}
+		found = true
+		name, _, nested := strings.Cut(rest, string(filepath.Separator))
+		if isDir || nested {
+			names[name] = true
+		}
+	}
+	for dir := range fs.dirs {
+		collect(dir, true)
+	}
+	for file := range fs.files {
+		collect(file, false)
+	}
+
+	if !found && !fs.dirs[path] {
+		return nil, os.ErrNotExist
+	}
+	dirs := make([]string, 0, len(names))
+	for name := range names {
+		dirs = append(dirs, name)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// ListFiles returns the sorted paths of all files under path
+func (fs *MemoryFileSystem) ListFiles(path string) ([]string, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	prefix := filepath.Clean(path) + string(filepath.Separator)
+	var files []string
+	for file := range fs.files {
+		if strings.HasPrefix(filepath.Clean(file), prefix) {
+			files = append(files, file)
+		}
+	}
+	if files == nil && !fs.dirs[path] {
+		return nil, os.ErrNotExist
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Remove deletes a file
+func (fs *MemoryFileSystem) Remove(path string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, ok := fs.files[path]; !ok {
+		return os.ErrNotExist
+	}
+	delete(fs.files, path)
+	return nil
+}
+
+// RemoveAll deletes path and every file and directory under it
+func (fs *MemoryFileSystem) RemoveAll(path string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	clean := filepath.Clean(path)
+	prefix := clean + string(filepath.Separator)
+	under := func(p string) bool {
+		p = filepath.Clean(p)
+		return p == clean || strings.HasPrefix(p, prefix)
+	}
+	for file := range fs.files {
+		if under(file) {
+			delete(fs.files, file)
+		}
+	}
+	for dir := range fs.dirs {
+		if under(dir) {
+			delete(fs.dirs, dir)
+		}
+	}
+	return nil
+}
+
+// Rename moves a file or a directory with everything under it
+func (fs *MemoryFileSystem) Rename(oldPath, newPath string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	oldClean := filepath.Clean(oldPath)
+	moved := false
+	rename := func(p string) (string, bool) {
+		p = filepath.Clean(p)
+		if p == oldClean {
+			return newPath, true
+		}
+		if rest, ok := strings.CutPrefix(p, oldClean+string(filepath.Separator)); ok {
+			return filepath.Join(newPath, rest), true
+		}
+		return "", false
+	}
+	for file, data := range fs.files {
+		if target, ok := rename(file); ok {
+			delete(fs.files, file)
+			fs.files[target] = data
+			moved = true
+		}
+	}
+	for dir := range fs.dirs {
+		if target, ok := rename(dir); ok {
+			delete(fs.dirs, dir)
+			fs.dirs[target] = true
+			moved = true
+		}
+	}
+	if !moved {
+		return os.ErrNotExist
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// ErrInvalidFrontMatter indicates a template front-matter header could not be parsed
+var ErrInvalidFrontMatter = errors.New("invalid template front-matter")
+
+// frontMatterMarker opens the front-matter header of a template, following the
+// "## tfskel-metadata:" comment convention used in generated files. Example:
+//
+//	## tfskel:
+//	##   output: envs/{{.Env}}/README.md
+//	##   when: eq .Env "prd"
+//	##   mode: always
+//	##   category: docs
+const frontMatterMarker = "## tfskel:"
+
+// Template write modes, deciding what happens when the output file already exists
+const (
+	ModeCreateOnly = "create-only" // Only create missing files (default)
+	ModeManaged    = "managed"     // Update files whose content changed, reviewed through --diff/--interactive
+	ModeAlways     = "always"      // Overwrite files on every run
+)
+
+// FrontMatter holds the settings declared in the header of a template
+type FrontMatter struct {
+	// Output is the output path relative to the project root; may use template data like {{.Env}}
+	Output string `yaml:"output"`
+	// When is a template expression on Data; the template is skipped unless it is true
+	When string `yaml:"when"`
+	// Mode is the write mode, one of create-only, managed or always
+	Mode string `yaml:"mode"`
+	// Category places the template as if it was stored in that category directory
+	Category string `yaml:"category"`
+}
+
+// WriteMode returns the declared write mode, defaulting to create-only
+func (f FrontMatter) WriteMode() string {
+	if f.Mode == "" {
+		return ModeCreateOnly
+	}
+	return f.Mode
+}
+
+// parseFrontMatter splits a template into its front-matter and the remaining template body
+// Templates without a "## tfskel:" first line are returned unchanged
+func parseFrontMatter(content string) (FrontMatter, string, error) {
+	var fm FrontMatter
+
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != frontMatterMarker {
+		return fm, content, nil
+	}
+
+	// The header ends at the first line that is not a ## comment
+	var header strings.Builder
+	end := 1
+	for ; end < len(lines); end++ {
+		line, ok := strings.CutPrefix(lines[end], "##")
+		if !ok {
+			break
+		}
+		header.WriteString(line)
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(header.String()))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fm); err != nil && !errors.Is(err, io.EOF) {
+		return fm, "", fmt.Errorf("%w: %v", ErrInvalidFrontMatter, err)
+	}
+
+	switch fm.Mode {
+	case "", ModeCreateOnly, ModeManaged, ModeAlways:
+	default:
+		return fm, "", fmt.Errorf("%w: mode must be %s, %s or %s, got %q", ErrInvalidFrontMatter, ModeCreateOnly, ModeManaged, ModeAlways, fm.Mode)
+	}
+
+	return fm, strings.Join(lines[end:], ""), nil
+}
+
+// EvaluateCondition evaluates a front-matter when expression such as `eq .Env "prd"` against data
+// An empty expression is always true
+func EvaluateCondition(expr string, data *Data) (bool, error) {
+	if strings.TrimSpace(expr) == "" {
+		return true, nil
+	}
+
+	tmpl, err := template.New("when").Funcs(funcMap).Parse("{{if " + expr + "}}true{{end}}")
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to parse condition %q: %v", ErrInvalidFrontMatter, expr, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return false, fmt.Errorf("%w: failed to evaluate condition %q: %v", ErrInvalidFrontMatter, expr, err)
+	}
+	return buf.String() == "true", nil
+}
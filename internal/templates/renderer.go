@@ -8,6 +8,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -32,6 +33,15 @@ func stripConstraint(version string) string {
 	return strings.TrimSpace(version)
 }
 
+// hclValue formats a config value as an HCL literal: strings are quoted, everything else is printed as-is
+// Example: "foo" -> "\"foo\"", true -> "true"
+func hclValue(value any) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
 // funcMap provides common template functions for both default and custom templates
 var funcMap = template.FuncMap{
 	"replace":         strings.ReplaceAll,
@@ -46,8 +56,24 @@ var funcMap = template.FuncMap{
 	"join":            strings.Join,
 	"split":           strings.Split,
 	"stripConstraint": stripConstraint,
+	"hclValue":        hclValue,
 }
 
+// Built-in template categories, the first directory of a template path
+const (
+	CategoryRoot    = "root"    // Files at the project root
+	CategoryTF      = "tf"      // Files in the app directory
+	CategoryGithub  = "github"  // GitHub workflows in .github/workflows
+	CategoryBackend = "backend" // Backend partials rendered through include
+)
+
+// BuiltinCategories lists every built-in template category
+var BuiltinCategories = []string{CategoryRoot, CategoryTF, CategoryGithub, CategoryBackend}
+
+// partialCategories are template categories that never produce files on their own;
+// they are only rendered through include from other templates (e.g. backend/s3.tf.tmpl)
+var partialCategories = []string{CategoryBackend}
+
 //go:embed files/**/*.tmpl files/**/*.yaml
 var embeddedTemplates embed.FS
 
@@ -75,13 +101,29 @@ type Data struct {
 	DefaultTags        map[string]string
 	AWSRoleArn         string // AWS role ARN for terraform workflows
 	WorkflowFileName   string // Generated workflow filename for self-reference in triggers
+	AppPath            string // Directory of the app relative to the project root, see layout.app_path
+
+	Cloud       string            // Target cloud provider (aws, azurerm, google); empty means aws
+	BackendType string            // Target backend type (s3, azurerm, gcs, http, cloud, local); empty means s3
+	Backend     map[string]string // Rendered settings of the selected backend, e.g. .Backend.container_name
+
+	AzureRMProviderVersion string
+	SubscriptionID         string
+	AzureRMFeatures        map[string]map[string]any // Nested blocks of the azurerm features {} block
+
+	GoogleProviderVersion string
+	ProjectID             string
+
+	Vars map[string]any // User-defined variables from vars, environments.<env>.vars, apps[].vars and --set
 }
 
 // Renderer handles template rendering
 type Renderer struct {
 	templates     map[string]*template.Template
-	staticContent map[string]string // Raw content for static files (like reusable workflows)
-	sources       map[string]string // Track where each template came from (for logging)
+	partials      map[string]*template.Template // Templates only rendered through include
+	staticContent map[string]string             // Raw content for static files (like reusable workflows)
+	sources       map[string]string             // Track where each template came from (for logging)
+	frontMatter   map[string]FrontMatter        // Front-matter declared in the header of a template
 }
 
 // NewRenderer creates a new template renderer with default embedded templates
@@ -94,8 +136,10 @@ func NewRenderer() (*Renderer, error) {
 func NewRendererWithCustomTemplates(customTemplateDir string, allowedExtensions []string) (*Renderer, error) {
 	r := &Renderer{
 		templates:     make(map[string]*template.Template),
+		partials:      make(map[string]*template.Template),
 		staticContent: make(map[string]string),
 		sources:       make(map[string]string),
+		frontMatter:   make(map[string]FrontMatter),
 	}
 
 	// Load default embedded templates
@@ -142,23 +186,86 @@ func (r *Renderer) loadTemplatesFromFS(fsys fs.FS) error {
 		}
 
 		// For .tmpl files, parse as Go templates
-		tmpl, err := template.New(path).Funcs(funcMap).Parse(string(content))
-		if err != nil {
+		if err := r.parseTemplate(path, string(content)); err != nil {
 			return fmt.Errorf("failed to parse template %s: %w", path, err)
 		}
-
-		r.templates[path] = tmpl
 		r.sources[path] = "embedded:" + path
 		return nil
 	})
 }
 
+// parseTemplate parses a template after splitting off its front-matter header
+func (r *Renderer) parseTemplate(name, content string) error {
+	fm, body, err := parseFrontMatter(content)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := r.newTemplate(name).Parse(body)
+	if err != nil {
+		return err
+	}
+
+	r.addTemplate(name, tmpl)
+	if fm != (FrontMatter{}) {
+		r.frontMatter[name] = fm
+	}
+	return nil
+}
+
+// newTemplate creates a template with the shared funcMap plus the renderer bound include function
+func (r *Renderer) newTemplate(name string) *template.Template {
+	return template.New(name).Funcs(funcMap).Funcs(template.FuncMap{"include": r.include})
+}
+
+// addTemplate stores a parsed template, keeping partials apart from templates that produce files
+func (r *Renderer) addTemplate(name string, tmpl *template.Template) {
+	if isPartial(name) {
+		r.partials[name] = tmpl
+		return
+	}
+	r.templates[name] = tmpl
+}
+
+// isPartial reports whether a template belongs to a partial category
+func isPartial(name string) bool {
+	category, _, _ := strings.Cut(filepath.ToSlash(name), "/")
+	for _, partial := range partialCategories {
+		if category == partial {
+			return true
+		}
+	}
+	return false
+}
+
+// include renders a partial (or any other loaded template) by name from within a template
+// Example: {{include (printf "backend/%s.tf.tmpl" .BackendType) .}}
+func (r *Renderer) include(name string, data any) (string, error) {
+	tmpl, ok := r.partials[name]
+	if !ok {
+		tmpl, ok = r.templates[name]
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
 // loadCustomTemplates loads templates from a custom directory
 // Only processes files with extensions in allowedExtensions list
-// Example with ["tf.tmpl", "md.tmpl"]:
+// The directory structure is kept: the first directory is the category and files
+// directly in customDir belong to tf/. Example with ["tf.tmpl", "md.tmpl"]:
 //
-//	backend.tf.tmpl -> tf/backend.tf.tmpl (overrides default)
-//	readme.md.tmpl  -> tf/readme.md.tmpl (new template)
+//	backend.tf.tmpl         -> tf/backend.tf.tmpl (overrides default)
+//	tf/modules/main.tf.tmpl -> tf/modules/main.tf.tmpl (new template in a subdirectory)
+//	root/README.md.tmpl     -> root/README.md.tmpl (new project root template)
+//	docs/runbook.md.tmpl    -> docs/runbook.md.tmpl (user-defined category)
 func (r *Renderer) loadCustomTemplates(customDir string, allowedExtensions []string) error {
 	if _, err := os.Stat(customDir); os.IsNotExist(err) {
 		return fmt.Errorf("%w: %s", ErrCustomTemplateDirNotExist, customDir)
@@ -169,6 +276,10 @@ func (r *Renderer) loadCustomTemplates(customDir string, allowedExtensions []str
 			return err
 		}
 		if info.IsDir() {
+			// Skip hidden directories such as .git
+			if path != customDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
@@ -191,20 +302,46 @@ func (r *Renderer) loadCustomTemplates(customDir string, allowedExtensions []str
 			return fmt.Errorf("failed to read custom template %s: %w", path, err)
 		}
 
-		// Map to tf/ directory
-		templateKey := filepath.Join("tf", filename)
-
-		tmpl, err := template.New(templateKey).Funcs(funcMap).Parse(string(content))
+		templateKey, err := customTemplateKey(customDir, path)
 		if err != nil {
-			return fmt.Errorf("failed to parse custom template %s: %w", filename, err)
+			return err
 		}
 
-		r.templates[templateKey] = tmpl
+		// A custom template replaces any default template with the same key
+		delete(r.templates, templateKey)
+		delete(r.partials, templateKey)
+		delete(r.staticContent, templateKey)
+		delete(r.frontMatter, templateKey)
 		r.sources[templateKey] = path
+
+		// Workflow files without .tmpl are static, like the embedded reusable workflows
+		category, _, _ := strings.Cut(templateKey, "/")
+		if category == CategoryGithub && !strings.HasSuffix(templateKey, ".tmpl") {
+			r.staticContent[templateKey] = string(content)
+			return nil
+		}
+
+		if err := r.parseTemplate(templateKey, string(content)); err != nil {
+			return fmt.Errorf("failed to parse custom template %s: %w", templateKey, err)
+		}
 		return nil
 	})
 }
 
+// customTemplateKey returns the template key of a file in the custom template directory
+// The key is the slash separated path relative to customDir, files at the top level map to tf/
+func customTemplateKey(customDir, path string) (string, error) {
+	rel, err := filepath.Rel(customDir, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve custom template %s: %w", path, err)
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.Contains(rel, "/") {
+		return CategoryTF + "/" + rel, nil
+	}
+	return rel, nil
+}
+
 // GetTemplateNames returns all loaded template names (both templates and static content)
 func (r *Renderer) GetTemplateNames() []string {
 	count := len(r.templates) + len(r.staticContent)
@@ -248,3 +385,9 @@ func (r *Renderer) GetTemplateSource(templateName string) string {
 	}
 	return ""
 }
+
+// GetFrontMatter returns the front-matter declared by a template
+// Templates without a front-matter header return the zero value
+func (r *Renderer) GetFrontMatter(templateName string) FrontMatter {
+	return r.frontMatter[templateName]
+}
@@ -1,6 +1,9 @@
 package templates
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -137,6 +140,104 @@ func TestRenderWithDefaultTags(t *testing.T) {
 	assert.Contains(t, content, "infrastructure")
 }
 
+func TestRenderAzureRM(t *testing.T) {
+	renderer, err := NewRenderer()
+	require.NoError(t, err)
+
+	data := &Data{
+		Env:                    "dev",
+		Region:                 "westeurope",
+		AppDir:                 "myapp",
+		TerraformVersion:       "~> 1.13",
+		Cloud:                  "azurerm",
+		BackendType:            "azurerm",
+		AzureRMProviderVersion: "~> 4.0",
+		SubscriptionID:         "11111111-2222-3333-4444-555555555555",
+		Backend: map[string]string{
+			"resource_group_name":  "rg-tfstate",
+			"storage_account_name": "sttfstate",
+			"container_name":       "tfstate",
+			"key":                  "myapp-dev-westeurope/terraform.tfstate",
+		},
+		AzureRMFeatures: map[string]map[string]any{
+			"resource_group": {"prevent_deletion_if_contains_resources": false},
+		},
+		DefaultTags: map[string]string{"team": "platform"},
+	}
+
+	t.Run("versions.tf", func(t *testing.T) {
+		content, err := renderer.Render("tf/versions.tf.tmpl", data)
+		require.NoError(t, err)
+		assert.Contains(t, content, `source  = "hashicorp/azurerm"`)
+		assert.Contains(t, content, `"azurerm_provider_ver": "~> 4.0"`)
+		assert.Contains(t, content, `subscription_id = "11111111-2222-3333-4444-555555555555"`)
+		assert.Contains(t, content, "prevent_deletion_if_contains_resources = false")
+		assert.Contains(t, content, `team = "platform"`)
+		assert.NotContains(t, content, "hashicorp/aws")
+	})
+
+	t.Run("backend.tf", func(t *testing.T) {
+		content, err := renderer.Render("tf/backend.tf.tmpl", data)
+		require.NoError(t, err)
+		assert.Contains(t, content, `backend "azurerm"`)
+		assert.Contains(t, content, `storage_account_name = "sttfstate"`)
+		assert.Contains(t, content, `key                  = "myapp-dev-westeurope/terraform.tfstate"`)
+		assert.NotContains(t, content, `backend "s3"`)
+	})
+}
+
+func TestRenderBackendTypes(t *testing.T) {
+	renderer, err := NewRenderer()
+	require.NoError(t, err)
+
+	tests := []struct {
+		backendType string
+		backend     map[string]string
+		expected    []string
+	}{
+		{
+			backendType: "http",
+			backend:     map[string]string{"address": "https://state.example.com/myapp"},
+			expected:    []string{`backend "http"`, `address        = "https://state.example.com/myapp"`},
+		},
+		{
+			backendType: "cloud",
+			backend:     map[string]string{"organization": "acme", "workspace": "myapp-dev", "project": "platform"},
+			expected:    []string{"cloud {", `organization = "acme"`, `name    = "myapp-dev"`, `project = "platform"`},
+		},
+		{
+			backendType: "local",
+			backend:     map[string]string{"path": "terraform.tfstate"},
+			expected:    []string{`backend "local"`, `path = "terraform.tfstate"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.backendType, func(t *testing.T) {
+			content, err := renderer.Render("tf/backend.tf.tmpl", &Data{BackendType: tt.backendType, Backend: tt.backend})
+			require.NoError(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, content, expected)
+			}
+		})
+	}
+
+	t.Run("unknown backend type", func(t *testing.T) {
+		_, err := renderer.Render("tf/backend.tf.tmpl", &Data{BackendType: "consul"})
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	})
+
+	t.Run("backend partials are not output templates", func(t *testing.T) {
+		assert.NotContains(t, renderer.GetTemplateNames(), "backend/s3.tf.tmpl")
+	})
+}
+
+func TestHCLValue(t *testing.T) {
+	assert.Equal(t, `"foo"`, hclValue("foo"))
+	assert.Equal(t, "true", hclValue(true))
+	assert.Equal(t, "3", hclValue(3))
+}
+
 func TestRenderNonExistentTemplate(t *testing.T) {
 	renderer, err := NewRenderer()
 	require.NoError(t, err)
@@ -157,9 +258,144 @@ func TestNewRendererWithCustomTemplates(t *testing.T) {
 		assert.Contains(t, names, "tf/versions.tf.tmpl")
 	})
 
+	t.Run("keeps categories and subdirectories of the custom dir", func(t *testing.T) {
+		customDir := t.TempDir()
+		files := map[string]string{
+			"main.tf.tmpl":                  "# top level {{.AppDir}}",
+			"tf/modules/network.tf.tmpl":    "# module {{.Env}}",
+			"root/CODEOWNERS.tmpl":          "* @platform",
+			"github/lint.yaml.tmpl":         "name: {{.WorkflowFileName}}",
+			"github/reusable-lint.yaml":     "run: ${{ inputs.dir }}",
+			"backend/s3.tf.tmpl":            "# custom s3 {{.S3BucketName}}",
+			"docs/runbook.md.tmpl":          "# {{.AppDir}} runbook",
+			".git/hooks/pre-commit.tf.tmpl": "ignored",
+		}
+		for name, content := range files {
+			path := filepath.Join(customDir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+
+		renderer, err := NewRendererWithCustomTemplates(customDir, []string{"tf.tmpl", "md.tmpl", "yaml.tmpl", "yaml", "tmpl"})
+		require.NoError(t, err)
+
+		names := renderer.GetTemplateNames()
+		for _, name := range []string{"tf/main.tf.tmpl", "tf/modules/network.tf.tmpl", "root/CODEOWNERS.tmpl", "github/lint.yaml.tmpl", "github/reusable-lint.yaml", "docs/runbook.md.tmpl"} {
+			assert.Contains(t, names, name)
+		}
+		assert.NotContains(t, names, "backend/s3.tf.tmpl", "backend partials are only rendered through include")
+		for _, name := range names {
+			assert.False(t, strings.HasPrefix(name, ".git"), "hidden directories are skipped: %s", name)
+		}
+
+		content, err := renderer.Render("github/reusable-lint.yaml", &Data{})
+		require.NoError(t, err)
+		assert.Equal(t, "run: ${{ inputs.dir }}", content, "custom workflow files without .tmpl are static")
+
+		content, err = renderer.Render("tf/backend.tf.tmpl", &Data{S3BucketName: "bucket"})
+		require.NoError(t, err)
+		assert.Contains(t, content, "# custom s3 bucket", "custom backend partial overrides the default")
+		assert.Equal(t, filepath.Join(customDir, "docs", "runbook.md.tmpl"), renderer.GetTemplateSource("docs/runbook.md.tmpl"))
+	})
+
 	t.Run("with non-existent custom dir returns error", func(t *testing.T) {
 		_, err := NewRendererWithCustomTemplates("/nonexistent/path", []string{"tf.tmpl"})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "does not exist")
 	})
 }
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		expected     FrontMatter
+		expectedBody string
+		wantErr      bool
+	}{
+		{
+			name:         "template without front-matter",
+			content:      "# {{.Env}}\n",
+			expectedBody: "# {{.Env}}\n",
+		},
+		{
+			name: "all settings",
+			content: `## tfskel:
+##   output: envs/{{.Env}}/README.md
+##   when: eq .Env "prd"
+##   mode: always
+##   category: docs
+# {{.Env}}
+`,
+			expected:     FrontMatter{Output: "envs/{{.Env}}/README.md", When: `eq .Env "prd"`, Mode: ModeAlways, Category: "docs"},
+			expectedBody: "# {{.Env}}\n",
+		},
+		{
+			name:    "unsupported mode",
+			content: "## tfskel:\n##   mode: sometimes\nbody\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown setting",
+			content: "## tfskel:\n##   ouput: README.md\nbody\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, body, err := parseFrontMatter(tt.content)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFrontMatter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fm)
+			assert.Equal(t, tt.expectedBody, body)
+		})
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	data := &Data{Env: "prd", Region: "eu-central-1", Vars: map[string]any{"public": true}}
+
+	tests := []struct {
+		expr     string
+		expected bool
+		wantErr  bool
+	}{
+		{expr: "", expected: true},
+		{expr: `eq .Env "prd"`, expected: true},
+		{expr: `eq .Env "dev"`, expected: false},
+		{expr: `and .Vars.public (hasPrefix .Region "eu-")`, expected: true},
+		{expr: `eq .Env`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result, err := EvaluateCondition(tt.expr, data)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidFrontMatter)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCustomTemplateFrontMatter(t *testing.T) {
+	customDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(customDir, "README.md.tmpl"),
+		[]byte("## tfskel:\n##   category: root\n##   mode: managed\n# {{.AppDir}}\n"), 0644))
+
+	renderer, err := NewRendererWithCustomTemplates(customDir, []string{"tf.tmpl", "md.tmpl"})
+	require.NoError(t, err)
+
+	assert.Equal(t, FrontMatter{Category: CategoryRoot, Mode: ModeManaged}, renderer.GetFrontMatter("tf/README.md.tmpl"))
+	assert.Equal(t, FrontMatter{}, renderer.GetFrontMatter("tf/backend.tf.tmpl"))
+
+	content, err := renderer.Render("tf/README.md.tmpl", &Data{AppDir: "payments"})
+	require.NoError(t, err)
+	assert.Equal(t, "# payments\n", content)
+}
@@ -51,3 +51,34 @@ func TestTransformRegionName(t *testing.T) {
 		})
 	}
 }
+
+func TestShortRegionName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "us-east-1", expected: "use1"},
+		{input: "ap-south-1", expected: "aps1"},
+		{input: "ap-southeast-1", expected: "apse1"},
+		{input: "us-gov-west-1", expected: "usgovw1"},
+		{input: "xx-central-9", expected: "xxc9"}, // Unknown regions fall back to TransformRegionName
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ShortRegionName(tt.input))
+		})
+	}
+}
+
+func TestRegionCatalogCodesAreUnique(t *testing.T) {
+	regions := make(map[string]string, len(awsRegionCodes))
+	for region, code := range awsRegionCodes {
+		if other, ok := regions[code]; ok {
+			t.Errorf("%s and %s share the short code %s", region, other, code)
+		}
+		regions[code] = region
+	}
+	assert.True(t, KnownRegion("eu-central-1"))
+	assert.False(t, KnownRegion("eu-centrall-1"))
+}